@@ -0,0 +1,375 @@
+// Package asciimath parses AsciiMath input such as `sqrt(x)/2` or
+// `sum_(i=1)^n i^2` into the AST of the LaTeX parser, so that every
+// visitor works for both input languages.
+//
+// Brackets become ast.DelimitedExpressionNode and are dropped where they
+// only group an argument, as in `(a+b)/2` or `x^(n+1)`. The column vector
+// `((n),(k))` is read as a binomial coefficient. Positions are byte offsets
+// into the input. Format writes an AST back as AsciiMath.
+package asciimath
+
+import (
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+// Parse tokenizes and parses input.
+func Parse(input string) (ast.Node, []parser.ParseError) {
+	return New(Tokenize(input)).Parse()
+}
+
+type Parser struct {
+	tokens []Token
+	pos    int
+	errors []parser.ParseError
+
+	// bars counts the open |...| groups; inside them a bar ends the
+	// expression
+	bars int
+}
+
+func New(ts []Token) *Parser {
+	return &Parser{
+		tokens: ts,
+		errors: []parser.ParseError{},
+	}
+}
+
+func (p *Parser) Parse() (ast.Node, []parser.ParseError) {
+	start := p.peek().Pos
+	var elements []ast.Node
+	for {
+		elements = append(elements, p.parseSequence()...)
+		if p.peek().Type == EOF {
+			break
+		}
+		// An unmatched closing bracket is kept as a plain delimiter
+		t := p.next()
+		p.addError("unexpected closing bracket "+t.Value, t.Pos)
+		elements = append(elements, delimiter(t))
+	}
+	return &ast.ExpressionNode{Start: start, Elements: elements}, p.errors
+}
+
+func (p *Parser) peek() Token {
+	if p.pos >= len(p.tokens) {
+		return Token{Type: EOF, Value: "", Pos: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *Parser) next() Token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *Parser) addError(msg string, pos int) {
+	p.errors = append(p.errors, parser.ParseError{Message: msg, Pos: pos})
+}
+
+// parseSequence parses expressions up to the end of input, a closing
+// bracket or, inside |...|, a bar.
+func (p *Parser) parseSequence() []ast.Node {
+	var elements []ast.Node
+	for {
+		t := p.peek()
+		if t.Type == EOF || t.Type == RIGHT || (t.Type == BAR && p.bars > 0) {
+			return elements
+		}
+		if n := p.parseFraction(); n != nil {
+			elements = append(elements, n)
+		}
+	}
+}
+
+// parseFraction parses an intermediate expression, followed by any number
+// of "/" and denominators.
+func (p *Parser) parseFraction() ast.Node {
+	left := p.parseIntermediate()
+	if left == nil {
+		return nil
+	}
+	for p.peek().Type == DIVIDE {
+		p.next() // consume '/'
+		right := p.parseIntermediate()
+		if right == nil {
+			p.addError("expected denominator after '/'", p.peek().Pos)
+			return left
+		}
+		left = &ast.FractionNode{
+			Start:       left.Pos(),
+			Numerator:   unbracket(left),
+			Denominator: unbracket(right),
+		}
+	}
+	return left
+}
+
+// parseIntermediate parses a simple expression with its subscripts and
+// superscripts.
+func (p *Parser) parseIntermediate() ast.Node {
+	base := p.parseSimple()
+	if base == nil {
+		return nil
+	}
+	for {
+		switch p.peek().Type {
+		case SUBSCRIPT:
+			p.next() // consume '_'
+			base = &ast.SubscriptNode{Start: base.Pos(), Base: base, Subscript: p.parseArgument("_")}
+		case SUPERSCRIPT:
+			p.next() // consume '^'
+			base = &ast.SuperscriptNode{Start: base.Pos(), Base: base, Exponent: p.parseArgument("^")}
+		default:
+			return base
+		}
+	}
+}
+
+// parseArgument parses the argument of a script or function, removing
+// grouping brackets. A missing argument is reported and replaced by an
+// empty expression.
+func (p *Parser) parseArgument(after string) ast.Node {
+	t := p.peek()
+	if n := p.parseSimple(); n != nil {
+		return unbracket(n)
+	}
+	p.addError("expected argument after "+after, t.Pos)
+	return &ast.ExpressionNode{Start: t.Pos}
+}
+
+// parseSimple parses a single symbol, bracketed expression, or function
+// with its arguments.
+func (p *Parser) parseSimple() ast.Node {
+	t := p.peek()
+	switch t.Type {
+	case NUMBER:
+		p.next()
+		return &ast.NumberNode{Start: t.Pos, Value: t.Value}
+	case SYMBOL, TEXT:
+		p.next()
+		return &ast.SymbolNode{Start: t.Pos, Value: t.Value}
+	case OPERATOR, DIVIDE:
+		p.next()
+		return &ast.OperatorNode{Start: t.Pos, Value: t.Value}
+	case FUNCTION:
+		p.next()
+		return p.parseFunction(t)
+	case BIGOP:
+		p.next()
+		return p.parseBigOperator(t)
+	case UNARY:
+		p.next()
+		return p.parseUnary(t)
+	case BINARY:
+		p.next()
+		return p.parseBinary(t)
+	case LEFT:
+		return p.parseBracketed()
+	case BAR:
+		return p.parseBars()
+	case EOF, RIGHT:
+		return nil
+	}
+
+	// Scripts without a base and illegal tokens
+	p.next()
+	p.addError("unexpected "+t.Value, t.Pos)
+	return nil
+}
+
+// parseBigOperator parses sum, prod, int and lim with their limits.
+func (p *Parser) parseBigOperator(t Token) ast.Node {
+	node := &ast.LimitedOperatorNode{Start: t.Pos, Operator: t.Value}
+	for {
+		switch p.peek().Type {
+		case SUBSCRIPT:
+			p.next()
+			if node.LowerLimit != nil {
+				p.addError("duplicate lower limit", p.peek().Pos)
+			}
+			node.LowerLimit = p.parseArgument("_")
+		case SUPERSCRIPT:
+			p.next()
+			if node.UpperLimit != nil {
+				p.addError("duplicate upper limit", p.peek().Pos)
+			}
+			node.UpperLimit = p.parseArgument("^")
+		default:
+			if node.Operator == "lim" && node.UpperLimit != nil {
+				p.addError("lim can only have a lower limit", node.UpperLimit.Pos())
+				node.UpperLimit = nil
+			}
+			return node
+		}
+	}
+}
+
+// parseFunction parses a function name. As in AsciiMath, the function
+// binds a following argument with its scripts, so sin(x)/x is a fraction
+// with sin(x) as numerator and sin x^2 the sine of x^2; function and
+// argument are grouped in an expression.
+func (p *Parser) parseFunction(t Token) ast.Node {
+	fn := &ast.NonArgumentFunctionNode{Start: t.Pos, Name: t.Value}
+	switch p.peek().Type {
+	case EOF, RIGHT, OPERATOR, DIVIDE, SUBSCRIPT, SUPERSCRIPT:
+		return fn
+	case BAR:
+		if p.bars > 0 {
+			return fn
+		}
+	}
+	arg := p.parseIntermediate()
+	if arg == nil {
+		return fn
+	}
+	return &ast.ExpressionNode{Start: t.Pos, Elements: []ast.Node{fn, arg}}
+}
+
+// unaryDelimiters maps the functions written with delimiters to the
+// delimiter kinds of ast.DelimiterNode.
+var unaryDelimiters = map[string][2]ast.Delimiter{
+	"abs":   {ast.Vert, ast.Vert},
+	"norm":  {ast.DoubleVert, ast.DoubleVert},
+	"floor": {ast.LeftFloor, ast.RightFloor},
+	"ceil":  {ast.LeftCeil, ast.RightCeil},
+}
+
+// delimiterKinds maps the values of bracket and bar tokens to the kinds
+// of ast.DelimiterNode.
+var delimiterKinds = map[string]ast.Delimiter{
+	"(":      ast.LeftParen,
+	")":      ast.RightParen,
+	"[":      ast.LeftBracket,
+	"]":      ast.RightBracket,
+	"{":      ast.LeftBrace,
+	"}":      ast.RightBrace,
+	"langle": ast.LeftAngle,
+	"rangle": ast.RightAngle,
+	"lfloor": ast.LeftFloor,
+	"rfloor": ast.RightFloor,
+	"lceil":  ast.LeftCeil,
+	"rceil":  ast.RightCeil,
+	".":      ast.NoDelimiter,
+	"|":      ast.Vert,
+}
+
+// delimiter returns the delimiter node of a bracket or bar token.
+func delimiter(t Token) *ast.DelimiterNode {
+	return &ast.DelimiterNode{Start: t.Pos, Kind: delimiterKinds[t.Value]}
+}
+
+// parseUnary parses sqrt, abs, floor, ceil and norm with their argument.
+func (p *Parser) parseUnary(t Token) ast.Node {
+	arg := p.parseArgument(t.Value)
+	if t.Value == "sqrt" {
+		return &ast.SqrtNode{Start: t.Pos, Radicand: arg}
+	}
+	delims := unaryDelimiters[t.Value]
+	return &ast.DelimitedExpressionNode{
+		Start:          t.Pos,
+		LeftDelimiter:  &ast.DelimiterNode{Start: t.Pos, Kind: delims[0]},
+		Content:        arg,
+		RightDelimiter: &ast.DelimiterNode{Start: arg.End(), Kind: delims[1]},
+	}
+}
+
+// parseBinary parses frac and root with their two arguments.
+func (p *Parser) parseBinary(t Token) ast.Node {
+	first := p.parseArgument(t.Value)
+	second := p.parseArgument(t.Value)
+	if t.Value == "root" {
+		return &ast.SqrtNode{Start: t.Pos, Index: first, Radicand: second}
+	}
+	return &ast.FractionNode{Start: t.Pos, Numerator: first, Denominator: second}
+}
+
+// parseBracketed parses a bracketed expression. Any closing bracket ends
+// it, as AsciiMath allows intervals like (a, b].
+func (p *Parser) parseBracketed() ast.Node {
+	left := p.next()
+
+	// Bars inside brackets are independent of an enclosing |...|
+	bars := p.bars
+	p.bars = 0
+	content := &ast.ExpressionNode{Start: p.peek().Pos, Elements: p.parseSequence()}
+	p.bars = bars
+
+	var right *ast.DelimiterNode
+	if t := p.peek(); t.Type == RIGHT {
+		p.next()
+		right = delimiter(t)
+	} else {
+		p.addError("expected closing bracket", t.Pos)
+		right = &ast.DelimiterNode{Start: t.Pos, Kind: ast.NoDelimiter}
+	}
+
+	if binom := binomial(left, content, right); binom != nil {
+		return binom
+	}
+	return &ast.DelimitedExpressionNode{
+		Start:          left.Pos,
+		LeftDelimiter:  delimiter(left),
+		Content:        content,
+		RightDelimiter: right,
+	}
+}
+
+// parseBars parses |...| as an absolute value. A bar without a matching
+// bar is kept as a plain delimiter.
+func (p *Parser) parseBars() ast.Node {
+	left := p.next()
+	pos, errors := p.pos, len(p.errors)
+
+	p.bars++
+	content := &ast.ExpressionNode{Start: p.peek().Pos, Elements: p.parseSequence()}
+	p.bars--
+
+	if t := p.peek(); t.Type == BAR && len(content.Elements) > 0 {
+		p.next()
+		return &ast.DelimitedExpressionNode{
+			Start:          left.Pos,
+			LeftDelimiter:  delimiter(left),
+			Content:        content,
+			RightDelimiter: delimiter(t),
+		}
+	}
+
+	p.pos, p.errors = pos, p.errors[:errors]
+	return delimiter(left)
+}
+
+// binomial returns a BinomNode for the column vector ((n),(k)), or nil.
+func binomial(left Token, content *ast.ExpressionNode, right *ast.DelimiterNode) ast.Node {
+	if left.Value != "(" || right.Kind != ast.RightParen || len(content.Elements) != 3 {
+		return nil
+	}
+	upper, ok1 := content.Elements[0].(*ast.DelimitedExpressionNode)
+	comma, ok2 := content.Elements[1].(*ast.OperatorNode)
+	lower, ok3 := content.Elements[2].(*ast.DelimitedExpressionNode)
+	if !ok1 || !ok2 || !ok3 || comma.Value != "," || !isParenthesized(upper) || !isParenthesized(lower) {
+		return nil
+	}
+	return &ast.BinomNode{Start: left.Pos, Upper: upper.Content, Lower: lower.Content}
+}
+
+func isParenthesized(d *ast.DelimitedExpressionNode) bool {
+	l, _ := d.LeftDelimiter.(*ast.DelimiterNode)
+	r, _ := d.RightDelimiter.(*ast.DelimiterNode)
+	return l != nil && r != nil && l.Kind == ast.LeftParen && r.Kind == ast.RightParen
+}
+
+// unbracket returns the content of a bracketed expression that only
+// groups, and n itself otherwise.
+func unbracket(n ast.Node) ast.Node {
+	d, ok := n.(*ast.DelimitedExpressionNode)
+	if !ok {
+		return n
+	}
+	switch d.LeftDelimiter.(*ast.DelimiterNode).Kind {
+	case ast.LeftParen, ast.LeftBracket, ast.LeftBrace, ast.NoDelimiter:
+		return d.Content
+	}
+	return n
+}
@@ -0,0 +1,384 @@
+package asciimath
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/neox5/texmax/ast"
+)
+
+// operatorNames maps operator values in the AST to AsciiMath input.
+// Operators without an entry are written as they are.
+var operatorNames = map[string]string{
+	"*": "**", // asterisk, as "*" is the multiplication dot
+	"/": "//", // slash, as "/" is a fraction
+	"·": "*",
+	"∗": "**",
+	"×": "xx",
+	"÷": "-:",
+	"∘": "@",
+	"±": "+-",
+	"∓": "-+",
+	"∧": "^^",
+	"∨": "vv",
+	"∩": "nn",
+	"∪": "uu",
+	"∖": "setminus",
+	"≠": "!=",
+	"≤": "<=",
+	"≥": ">=",
+	"≡": "-=",
+	"≅": "~=",
+	"≈": "~~",
+	"∼": "~",
+	"∝": "prop",
+	"∈": "in",
+	"∉": "!in",
+	"⊂": "sub",
+	"⊃": "sup",
+	"→": "->",
+	"←": "<-",
+	"⇒": "=>",
+	"⇔": "<=>",
+	"↦": "|->",
+}
+
+// leftDelimiters and rightDelimiters map the delimiters to AsciiMath
+// brackets. AsciiMath has no brackets for the other delimiters.
+var (
+	leftDelimiters = map[ast.Delimiter]string{
+		ast.LeftParen: "(", ast.LeftBracket: "[", ast.LeftBrace: "{", ast.NoDelimiter: "{:",
+		ast.Vert: "|", ast.LeftVert: "|", ast.DoubleVert: "||", ast.LeftDoubleVert: "||",
+		ast.LeftAngle: "(:", ast.LeftFloor: "|__", ast.LeftCeil: "|~",
+	}
+	rightDelimiters = map[ast.Delimiter]string{
+		ast.RightParen: ")", ast.RightBracket: "]", ast.RightBrace: "}", ast.NoDelimiter: ":}",
+		ast.Vert: "|", ast.RightVert: "|", ast.DoubleVert: "||", ast.RightDoubleVert: "||",
+		ast.RightAngle: ":)", ast.RightFloor: "__|", ast.RightCeil: "~|",
+	}
+)
+
+// delimiterFunctions maps delimiter pairs written as functions.
+var delimiterFunctions = map[[2]ast.Delimiter]string{
+	{ast.LeftFloor, ast.RightFloor}:           "floor",
+	{ast.LeftCeil, ast.RightCeil}:             "ceil",
+	{ast.DoubleVert, ast.DoubleVert}:          "norm",
+	{ast.LeftDoubleVert, ast.RightDoubleVert}: "norm",
+}
+
+// Format returns the AsciiMath input for node, such that Parse(Format(node))
+// yields the same formula.
+func Format(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+	s := NewSerializer()
+	ast.Walk(s, node)
+	return s.String()
+}
+
+// Serializer is an ast.Visitor that writes AsciiMath. Operators are spaced
+// at the top level and written compactly inside scripts and arguments;
+// parentheses are added where AsciiMath would otherwise group differently.
+type Serializer struct {
+	sb    strings.Builder
+	depth int
+}
+
+// NewSerializer creates a new Serializer.
+func NewSerializer() *Serializer {
+	return &Serializer{}
+}
+
+// String returns the AsciiMath written so far.
+func (s *Serializer) String() string {
+	return s.sb.String()
+}
+
+// format returns the AsciiMath for n at the given depth.
+func (s *Serializer) format(n ast.Node, depth int) string {
+	if n == nil {
+		return ""
+	}
+	sub := &Serializer{depth: depth}
+	n.Accept(sub)
+	return sub.String()
+}
+
+// argument returns n as the argument of a script, in parentheses unless
+// it is a single item.
+func (s *Serializer) argument(n ast.Node) string {
+	text := s.format(n, s.depth+1)
+	if isItem(n) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// operand returns n as numerator or denominator of a fraction, in
+// parentheses unless it is an item with scripts.
+func (s *Serializer) operand(n ast.Node) string {
+	text := s.format(n, s.depth+1)
+	if isItem(n) || isScripted(n) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// base returns n as the base of a script. A bracketed base keeps its
+// brackets, and a closing delimiter, as in (a-b)^2, takes the script for
+// the group it closes.
+func (s *Serializer) base(n ast.Node) string {
+	text := s.format(n, s.depth)
+	switch n.(type) {
+	case *ast.DelimiterNode, *ast.DelimitedExpressionNode:
+		return text
+	}
+	if isItem(n) || isScripted(n) || isFunction(n) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// Visit methods for container nodes
+func (s *Serializer) VisitExpressionNode(node *ast.ExpressionNode) {
+	var out string
+	for i, element := range node.Elements {
+		text := s.format(element, s.depth)
+		if _, ok := element.(*ast.SpaceNode); ok {
+			continue
+		}
+		// A function binds the following argument including fractions,
+		// so a fraction after a function is kept apart
+		if _, ok := element.(*ast.FractionNode); ok && i > 0 && isFunction(node.Elements[i-1]) {
+			text = "(" + text + ")"
+		}
+		if out != "" && (s.spaced(node.Elements, i) || needsSpace(out, text)) {
+			out += " "
+		}
+		out += text
+	}
+	s.sb.WriteString(out)
+}
+
+// spaced reports whether the element at i is separated from the previous
+// element by a space: around operators at the top level and after
+// functions and big operators.
+func (s *Serializer) spaced(elements []ast.Node, i int) bool {
+	prev := previous(elements, i)
+	switch node := prev.(type) {
+	case *ast.NonArgumentFunctionNode, *ast.LimitedOperatorNode:
+		return true
+	case *ast.SuperscriptNode:
+		return isFunction(node.Base)
+	case *ast.OperatorNode:
+		// No space after a sign
+		if s.depth > 0 || isOperator(previous(elements, i-1)) || previous(elements, i-1) == nil {
+			return false
+		}
+		return true
+	}
+	if op, ok := elements[i].(*ast.OperatorNode); ok {
+		return s.depth == 0 && op.Value != ","
+	}
+	return false
+}
+
+// previous returns the element before i, skipping spaces, or nil.
+func previous(elements []ast.Node, i int) ast.Node {
+	for j := i - 1; j >= 0; j-- {
+		if _, ok := elements[j].(*ast.SpaceNode); !ok {
+			return elements[j]
+		}
+	}
+	return nil
+}
+
+func (s *Serializer) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	left, right := delimiterKind(node.LeftDelimiter), delimiterKind(node.RightDelimiter)
+	content := s.format(node.Content, s.depth)
+	if fn, ok := delimiterFunctions[[2]ast.Delimiter{left, right}]; ok {
+		s.sb.WriteString(fn + "(" + content + ")")
+		return
+	}
+	s.sb.WriteString(leftDelimiters[left] + content + rightDelimiters[right])
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (s *Serializer) VisitErrorNode(node *ast.ErrorNode) {
+	s.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (s *Serializer) VisitSymbolNode(node *ast.SymbolNode) {
+	switch name, ok := symbolNames[node.Value]; {
+	case ok:
+		s.sb.WriteString(name)
+	case utf8.RuneCountInString(node.Value) > 1:
+		// Text, as produced by the AsciiMath parser
+		s.sb.WriteString(`"` + node.Value + `"`)
+	default:
+		s.sb.WriteString(node.Value)
+	}
+}
+
+func (s *Serializer) VisitNumberNode(node *ast.NumberNode) {
+	s.sb.WriteString(node.Value)
+}
+
+func (s *Serializer) VisitOperatorNode(node *ast.OperatorNode) {
+	if name, ok := operatorNames[node.Value]; ok {
+		s.sb.WriteString(name)
+		return
+	}
+	s.sb.WriteString(node.Value)
+}
+
+func (s *Serializer) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	s.sb.WriteString(node.Name)
+}
+
+func (s *Serializer) VisitSpaceNode(node *ast.SpaceNode) {}
+
+func (s *Serializer) VisitDelimiterNode(node *ast.DelimiterNode) {
+	switch {
+	case node.Kind == ast.NoDelimiter:
+	case node.Kind == ast.Backslash:
+		s.sb.WriteString("setminus")
+	case node.Kind.Class() == ast.OpenDelimiter:
+		s.sb.WriteString(leftDelimiters[node.Kind])
+	default:
+		s.sb.WriteString(rightDelimiters[node.Kind])
+	}
+}
+
+// Visit methods for composite nodes
+func (s *Serializer) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	s.sb.WriteString(s.base(node.Base) + "^" + s.argument(node.Exponent))
+}
+
+func (s *Serializer) VisitSubscriptNode(node *ast.SubscriptNode) {
+	s.sb.WriteString(s.base(node.Base) + "_" + s.argument(node.Subscript))
+}
+
+func (s *Serializer) VisitFractionNode(node *ast.FractionNode) {
+	s.sb.WriteString(s.operand(node.Numerator) + "/" + s.operand(node.Denominator))
+}
+
+func (s *Serializer) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	s.sb.WriteString(node.Operator)
+	if node.LowerLimit != nil {
+		s.sb.WriteString("_" + s.argument(node.LowerLimit))
+	}
+	if node.UpperLimit != nil {
+		s.sb.WriteString("^" + s.argument(node.UpperLimit))
+	}
+}
+
+func (s *Serializer) VisitSqrtNode(node *ast.SqrtNode) {
+	radicand := "(" + s.format(node.Radicand, s.depth+1) + ")"
+	if node.Index != nil {
+		s.sb.WriteString("root(" + s.format(node.Index, s.depth+1) + ")" + radicand)
+		return
+	}
+	s.sb.WriteString("sqrt" + radicand)
+}
+
+func (s *Serializer) VisitBinomNode(node *ast.BinomNode) {
+	s.sb.WriteString("((" + s.format(node.Upper, s.depth+1) + "),(" + s.format(node.Lower, s.depth+1) + "))")
+}
+
+func (s *Serializer) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	// The size of a delimiter is not written
+	node.Delimiter.Accept(s)
+}
+
+// needsSpace reports whether a and b must be separated by a space, since
+// writing them together would change how they are tokenized, as in "x" "x"
+// becoming the times sign "xx", or a name would run into a letter, as in
+// "alphax".
+func needsSpace(a, b string) bool {
+	ta, tb := withoutEOF(Tokenize(a)), withoutEOF(Tokenize(b))
+	joined := withoutEOF(Tokenize(a + b))
+	if len(joined) != len(ta)+len(tb) {
+		return true
+	}
+	for i, t := range append(ta, tb...) {
+		if joined[i].Type != t.Type || joined[i].Value != t.Value {
+			return true
+		}
+	}
+
+	if len(ta) == 0 || len(tb) == 0 {
+		return false
+	}
+	lastLen := len(a) - ta[len(ta)-1].Pos
+	firstLen := len(b)
+	if len(tb) > 1 {
+		firstLen = tb[1].Pos
+	}
+	r1, _ := utf8.DecodeLastRuneInString(a)
+	r2, _ := utf8.DecodeRuneInString(b)
+	return unicode.IsLetter(r1) && unicode.IsLetter(r2) && (lastLen > 1 || firstLen > 1)
+}
+
+// withoutEOF removes the EOF tokens from ts.
+func withoutEOF(ts []Token) []Token {
+	out := ts[:0:0]
+	for _, t := range ts {
+		if t.Type != EOF {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// isItem reports whether n is written as a single AsciiMath simple
+// expression that needs no parentheses as an argument.
+func isItem(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.ExpressionNode:
+		return len(node.Elements) == 1 && isItem(node.Elements[0])
+	case *ast.SymbolNode, *ast.NumberNode, *ast.SqrtNode, *ast.BinomNode:
+		return true
+	case *ast.DelimitedExpressionNode:
+		// Grouping brackets would be removed when parsed back
+		switch delimiterKind(node.LeftDelimiter) {
+		case ast.LeftParen, ast.LeftBracket, ast.LeftBrace, ast.NoDelimiter:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// isScripted reports whether n is an item with subscripts or superscripts.
+func isScripted(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.ExpressionNode:
+		return len(node.Elements) == 1 && isScripted(node.Elements[0])
+	case *ast.SuperscriptNode:
+		return isItem(node.Base) || isScripted(node.Base)
+	case *ast.SubscriptNode:
+		return isItem(node.Base) || isScripted(node.Base)
+	}
+	return false
+}
+
+func isFunction(n ast.Node) bool {
+	_, ok := n.(*ast.NonArgumentFunctionNode)
+	return ok
+}
+
+func isOperator(n ast.Node) bool {
+	_, ok := n.(*ast.OperatorNode)
+	return ok
+}
+
+func delimiterKind(n ast.Node) ast.Delimiter {
+	if d, ok := n.(*ast.DelimiterNode); ok {
+		return d.Kind
+	}
+	return ast.NoDelimiter
+}
@@ -0,0 +1,54 @@
+package asciimath_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/asciimath"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/unicode"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		latex string
+		want  string
+	}{
+		{`\frac{a+b}{c}`, `(a+b)/c`},
+		{`\frac{x^2}{\sqrt{y}}`, `x^2/sqrt(y)`},
+		{`\frac{\frac{1}{2}}{3}`, `(1/2)/3`},
+		{`\sum_{i=1}^{n} i^2`, `sum_(i=1)^n i^2`},
+		{`\lim_{x \to 0} f(x)`, `lim_(x->0) f(x)`},
+		{`\binom{n}{k}`, `((n),(k))`},
+		{`\sqrt[3]{x+1}`, `root(3)(x+1)`},
+		{`x^{-1}`, `x^(-1)`},
+		{`e^{i\pi}`, `e^(i pi)`},
+		{`a = -b`, `a = -b`},
+		{`a \leq b \cdot c`, `a <= b * c`},
+		{`x \times x`, `x xx x`},
+		{`\left\lfloor x \right\rfloor + \left| y \right|`, `floor(x) + |y|`},
+		{`(a-b)^2`, `(a - b)^2`},
+		{`\left(a-b\right)^2`, `(a - b)^2`},
+		{`\lfloor x \rfloor`, `|__x__|`},
+		{`\lceil x \rceil + 1`, `|~x~| + 1`},
+	}
+
+	for _, tt := range tests {
+		root, _ := parser.New(tokenizer.Tokenize(tt.latex)).Parse()
+		got := asciimath.Format(root)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.latex, got, tt.want)
+			continue
+		}
+
+		// Parsing the output back must give the same formula
+		back, errs := asciimath.Parse(got)
+		if len(errs) > 0 {
+			t.Errorf("%s: parsing %q failed: %v", tt.latex, got, errs)
+			continue
+		}
+		if g, w := unicode.Render(back), unicode.Render(root); g != w {
+			t.Errorf("%s: round trip gives %q, want %q", tt.latex, g, w)
+		}
+	}
+}
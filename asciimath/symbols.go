@@ -0,0 +1,154 @@
+package asciimath
+
+import "github.com/neox5/texmax/parser"
+
+// symbol is an entry of the AsciiMath symbol table.
+type symbol struct {
+	Type  TokenType
+	Value string
+}
+
+// symbols maps AsciiMath input names to tokens. The tokenizer always
+// takes the longest matching name, so "int" wins over "in".
+var symbols = map[string]symbol{
+	// Operators
+	"+":        {OPERATOR, "+"},
+	"-":        {OPERATOR, "-"},
+	"*":        {OPERATOR, "·"},
+	"**":       {OPERATOR, "∗"},
+	"//":       {OPERATOR, "/"},
+	"xx":       {OPERATOR, "×"},
+	"-:":       {OPERATOR, "÷"},
+	"@":        {OPERATOR, "∘"},
+	"+-":       {OPERATOR, "±"},
+	"-+":       {OPERATOR, "∓"},
+	"^^":       {OPERATOR, "∧"},
+	"vv":       {OPERATOR, "∨"},
+	"nn":       {OPERATOR, "∩"},
+	"uu":       {OPERATOR, "∪"},
+	"setminus": {OPERATOR, "∖"},
+	",":        {OPERATOR, ","},
+	"/":        {DIVIDE, "/"},
+	"mod":      {FUNCTION, "mod"},
+
+	// Relations
+	"=":    {OPERATOR, "="},
+	"!=":   {OPERATOR, "≠"},
+	"<":    {OPERATOR, "<"},
+	"lt":   {OPERATOR, "<"},
+	">":    {OPERATOR, ">"},
+	"gt":   {OPERATOR, ">"},
+	"<=":   {OPERATOR, "≤"},
+	"le":   {OPERATOR, "≤"},
+	">=":   {OPERATOR, "≥"},
+	"ge":   {OPERATOR, "≥"},
+	"-=":   {OPERATOR, "≡"},
+	"~=":   {OPERATOR, "≅"},
+	"~~":   {OPERATOR, "≈"},
+	"~":    {OPERATOR, "∼"},
+	"prop": {OPERATOR, "∝"},
+	"in":   {OPERATOR, "∈"},
+	"!in":  {OPERATOR, "∉"},
+	"sub":  {OPERATOR, "⊂"},
+	"sup":  {OPERATOR, "⊃"},
+
+	// Arrows
+	"->":  {OPERATOR, "→"},
+	"to":  {OPERATOR, "→"},
+	"<-":  {OPERATOR, "←"},
+	"=>":  {OPERATOR, "⇒"},
+	"<=>": {OPERATOR, "⇔"},
+	"|->": {OPERATOR, "↦"},
+
+	// Miscellaneous symbols
+	"oo":    {SYMBOL, "∞"},
+	"O/":    {SYMBOL, "∅"},
+	"del":   {SYMBOL, "∂"},
+	"grad":  {SYMBOL, "∇"},
+	"AA":    {SYMBOL, "∀"},
+	"EE":    {SYMBOL, "∃"},
+	"NN":    {SYMBOL, "ℕ"},
+	"ZZ":    {SYMBOL, "ℤ"},
+	"QQ":    {SYMBOL, "ℚ"},
+	"RR":    {SYMBOL, "ℝ"},
+	"CC":    {SYMBOL, "ℂ"},
+	"...":   {SYMBOL, "…"},
+	"cdots": {SYMBOL, "⋯"},
+
+	// Big operators
+	"sum":  {BIGOP, "sum"},
+	"prod": {BIGOP, "prod"},
+	"int":  {BIGOP, "int"},
+	"lim":  {BIGOP, "lim"},
+
+	// Functions with arguments
+	"sqrt":  {UNARY, "sqrt"},
+	"abs":   {UNARY, "abs"},
+	"floor": {UNARY, "floor"},
+	"ceil":  {UNARY, "ceil"},
+	"norm":  {UNARY, "norm"},
+	"frac":  {BINARY, "frac"},
+	"root":  {BINARY, "root"},
+
+	// Brackets, see delimiterKinds
+	"(":   {LEFT, "("},
+	")":   {RIGHT, ")"},
+	"[":   {LEFT, "["},
+	"]":   {RIGHT, "]"},
+	"{":   {LEFT, "{"},
+	"}":   {RIGHT, "}"},
+	"(:":  {LEFT, "langle"},
+	":)":  {RIGHT, "rangle"},
+	"<<":  {LEFT, "langle"},
+	">>":  {RIGHT, "rangle"},
+	"{:":  {LEFT, "."},
+	":}":  {RIGHT, "."},
+	"|__": {LEFT, "lfloor"},
+	"__|": {RIGHT, "rfloor"},
+	"|~":  {LEFT, "lceil"},
+	"~|":  {RIGHT, "rceil"},
+	"|":   {BAR, "|"},
+
+	// Scripts
+	"^": {SUPERSCRIPT, "^"},
+	"_": {SUBSCRIPT, "_"},
+}
+
+// functions are the function names written upright, matching the functions
+// known to the LaTeX parser.
+var functions = []string{
+	"sin", "cos", "tan", "cot", "sec", "csc", "log", "ln", "exp",
+	"arcsin", "arccos", "arctan", "sinh", "cosh", "tanh",
+	"max", "min", "det", "arg",
+}
+
+// greekNames are the Greek letter names of AsciiMath.
+var greekNames = []string{
+	"alpha", "beta", "gamma", "Gamma", "delta", "Delta", "epsilon",
+	"zeta", "eta", "theta", "Theta", "iota", "kappa", "lambda", "Lambda",
+	"mu", "nu", "xi", "Xi", "pi", "Pi", "rho", "sigma", "Sigma", "tau",
+	"upsilon", "phi", "Phi", "chi", "psi", "Psi", "omega", "Omega",
+}
+
+// maxSymbolLength is the length of the longest name in symbols.
+var maxSymbolLength int
+
+// symbolNames maps the values of symbols back to their names.
+var symbolNames = map[string]string{}
+
+func init() {
+	for _, name := range functions {
+		symbols[name] = symbol{FUNCTION, name}
+	}
+	for _, name := range greekNames {
+		if value, ok := parser.GreekLetter(name); ok {
+			symbols[name] = symbol{SYMBOL, value}
+		}
+	}
+	for name, sym := range symbols {
+		maxSymbolLength = max(maxSymbolLength, len(name))
+		if sym.Type == SYMBOL {
+			symbolNames[sym.Value] = name
+		}
+	}
+}
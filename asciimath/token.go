@@ -0,0 +1,79 @@
+package asciimath
+
+// TokenType defines the type of a token from AsciiMath input.
+type TokenType int
+
+const (
+	// Special
+	ILLEGAL TokenType = iota
+	EOF
+
+	// Core token types
+	NUMBER      // e.g., 12, 3.14
+	SYMBOL      // e.g., x, alpha, oo
+	TEXT        // e.g., "if", text(if)
+	OPERATOR    // e.g., +, xx, <=, ->
+	FUNCTION    // e.g., sin, log
+	BIGOP       // sum, prod, int, lim
+	UNARY       // sqrt, abs, floor, ceil, norm
+	BINARY      // frac, root
+	LEFT        // ( [ { (: {:
+	RIGHT       // ) ] } :) :}
+	BAR         // |
+	SUPERSCRIPT // ^
+	SUBSCRIPT   // _
+	DIVIDE      // /
+)
+
+// Token represents a single lexical token. Value holds the canonical form
+// used in the AST, e.g. "α" for alpha and "≤" for <=.
+type Token struct {
+	Type  TokenType // Type of the token
+	Value string    // Canonical value
+	Pos   int       // Byte offset in the input
+}
+
+// String returns a readable representation of the token.
+func (t Token) String() string {
+	return t.Type.String() + "('" + t.Value + "')"
+}
+
+// String implements the fmt.Stringer interface for TokenType.
+func (tt TokenType) String() string {
+	switch tt {
+	case ILLEGAL:
+		return "ILLEGAL"
+	case EOF:
+		return "EOF"
+	case NUMBER:
+		return "NUMBER"
+	case SYMBOL:
+		return "SYMBOL"
+	case TEXT:
+		return "TEXT"
+	case OPERATOR:
+		return "OPERATOR"
+	case FUNCTION:
+		return "FUNCTION"
+	case BIGOP:
+		return "BIGOP"
+	case UNARY:
+		return "UNARY"
+	case BINARY:
+		return "BINARY"
+	case LEFT:
+		return "LEFT"
+	case RIGHT:
+		return "RIGHT"
+	case BAR:
+		return "BAR"
+	case SUPERSCRIPT:
+		return "SUPERSCRIPT"
+	case SUBSCRIPT:
+		return "SUBSCRIPT"
+	case DIVIDE:
+		return "DIVIDE"
+	default:
+		return "UNKNOWN"
+	}
+}
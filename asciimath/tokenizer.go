@@ -0,0 +1,92 @@
+package asciimath
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenize splits AsciiMath input into tokens. Whitespace only separates
+// tokens and is dropped. Names from the symbol table are matched greedily;
+// other letters become single-letter symbols and other characters operators.
+func Tokenize(input string) []Token {
+	var tokens []Token
+
+	for pos := 0; pos < len(input); {
+		rest := input[pos:]
+		r, size := utf8.DecodeRuneInString(rest)
+
+		switch {
+		// SPACE
+		case unicode.IsSpace(r):
+			pos += size
+
+		// TEXT - "quoted" or text(...)
+		case r == '"':
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				tokens = append(tokens, Token{Type: ILLEGAL, Value: rest, Pos: pos})
+				pos = len(input)
+				continue
+			}
+			tokens = append(tokens, Token{Type: TEXT, Value: rest[1 : end+1], Pos: pos})
+			pos += end + 2
+		case strings.HasPrefix(rest, "text("):
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				tokens = append(tokens, Token{Type: ILLEGAL, Value: rest, Pos: pos})
+				pos = len(input)
+				continue
+			}
+			tokens = append(tokens, Token{Type: TEXT, Value: rest[len("text("):end], Pos: pos})
+			pos += end + 1
+
+		// NUMBER - digits with an optional decimal part
+		case r >= '0' && r <= '9':
+			end := 0
+			for end < len(rest) && isDigit(rest[end]) {
+				end++
+			}
+			if end+1 < len(rest) && rest[end] == '.' && isDigit(rest[end+1]) {
+				end++
+				for end < len(rest) && isDigit(rest[end]) {
+					end++
+				}
+			}
+			tokens = append(tokens, Token{Type: NUMBER, Value: rest[:end], Pos: pos})
+			pos += end
+
+		default:
+			if name, sym, ok := matchSymbol(rest); ok {
+				tokens = append(tokens, Token{Type: sym.Type, Value: sym.Value, Pos: pos})
+				pos += len(name)
+				continue
+			}
+			// SYMBOL or OPERATOR
+			typ := OPERATOR
+			if unicode.IsLetter(r) {
+				typ = SYMBOL
+			}
+			tokens = append(tokens, Token{Type: typ, Value: string(r), Pos: pos})
+			pos += size
+		}
+	}
+
+	// Add EOF token at end
+	tokens = append(tokens, Token{Type: EOF, Value: "", Pos: len(input)})
+	return tokens
+}
+
+// matchSymbol returns the longest name from the symbol table that s starts with.
+func matchSymbol(s string) (string, symbol, bool) {
+	for n := min(len(s), maxSymbolLength); n > 0; n-- {
+		if sym, ok := symbols[s[:n]]; ok {
+			return s[:n], sym, true
+		}
+	}
+	return "", symbol{}, false
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,31 @@
+package asciimath_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/asciimath"
+)
+
+func TestTokenizeNonASCIIDigits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"١", []string{"١"}},
+		{"１+x", []string{"１", "+", "x"}},
+		{"12٣", []string{"12", "٣"}},
+	}
+
+	for _, tt := range tests {
+		tokens := asciimath.Tokenize(tt.input)
+		if len(tokens) != len(tt.want)+1 || tokens[len(tokens)-1].Type != asciimath.EOF {
+			t.Errorf("%q: got %v, want %q followed by EOF", tt.input, tokens, tt.want)
+			continue
+		}
+		for i, want := range tt.want {
+			if tokens[i].Value != want {
+				t.Errorf("%q: token %d: got %q, want %q", tt.input, i, tokens[i].Value, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ast
+
+// Delimiter is a kind of math delimiter: a symbol that \left, \right,
+// \middle and \big can enlarge.
+type Delimiter int
+
+const (
+	NoDelimiter       Delimiter = iota // the empty delimiter .
+	LeftParen                          // (
+	RightParen                         // )
+	LeftBracket                        // [ or \lbrack
+	RightBracket                       // ] or \rbrack
+	LeftBrace                          // \{ or \lbrace
+	RightBrace                         // \} or \rbrace
+	LeftAngle                          // \langle or <
+	RightAngle                         // \rangle or >
+	LeftFloor                          // \lfloor
+	RightFloor                         // \rfloor
+	LeftCeil                           // \lceil
+	RightCeil                          // \rceil
+	LeftGroup                          // \lgroup
+	RightGroup                         // \rgroup
+	LeftMoustache                      // \lmoustache
+	RightMoustache                     // \rmoustache
+	UpperLeftCorner                    // \ulcorner
+	UpperRightCorner                   // \urcorner
+	LowerLeftCorner                    // \llcorner
+	LowerRightCorner                   // \lrcorner
+	Vert                               // | or \vert
+	LeftVert                           // \lvert
+	RightVert                          // \rvert
+	DoubleVert                         // \| or \Vert
+	LeftDoubleVert                     // \lVert
+	RightDoubleVert                    // \rVert
+	Slash                              // /
+	Backslash                          // \backslash
+	UpArrow                            // \uparrow
+	DownArrow                          // \downarrow
+	UpDownArrow                        // \updownarrow
+	DoubleUpArrow                      // \Uparrow
+	DoubleDownArrow                    // \Downarrow
+	DoubleUpDownArrow                  // \Updownarrow
+)
+
+// DelimiterClass tells on which side of an expression a delimiter goes.
+type DelimiterClass int
+
+const (
+	OpenDelimiter  DelimiterClass = iota // opens an expression, such as (
+	CloseDelimiter                       // closes an expression, such as )
+	FenceDelimiter                       // goes on either side, such as |
+)
+
+// String returns the name of the class.
+func (c DelimiterClass) String() string {
+	switch c {
+	case OpenDelimiter:
+		return "open"
+	case CloseDelimiter:
+		return "close"
+	default:
+		return "fence"
+	}
+}
+
+var delimiters = [...]struct {
+	latex string
+	glyph string
+	class DelimiterClass
+}{
+	NoDelimiter:       {".", "", FenceDelimiter},
+	LeftParen:         {"(", "(", OpenDelimiter},
+	RightParen:        {")", ")", CloseDelimiter},
+	LeftBracket:       {"[", "[", OpenDelimiter},
+	RightBracket:      {"]", "]", CloseDelimiter},
+	LeftBrace:         {`\{`, "{", OpenDelimiter},
+	RightBrace:        {`\}`, "}", CloseDelimiter},
+	LeftAngle:         {`\langle`, "⟨", OpenDelimiter},
+	RightAngle:        {`\rangle`, "⟩", CloseDelimiter},
+	LeftFloor:         {`\lfloor`, "⌊", OpenDelimiter},
+	RightFloor:        {`\rfloor`, "⌋", CloseDelimiter},
+	LeftCeil:          {`\lceil`, "⌈", OpenDelimiter},
+	RightCeil:         {`\rceil`, "⌉", CloseDelimiter},
+	LeftGroup:         {`\lgroup`, "⟮", OpenDelimiter},
+	RightGroup:        {`\rgroup`, "⟯", CloseDelimiter},
+	LeftMoustache:     {`\lmoustache`, "⎰", OpenDelimiter},
+	RightMoustache:    {`\rmoustache`, "⎱", CloseDelimiter},
+	UpperLeftCorner:   {`\ulcorner`, "⌜", OpenDelimiter},
+	UpperRightCorner:  {`\urcorner`, "⌝", CloseDelimiter},
+	LowerLeftCorner:   {`\llcorner`, "⌞", OpenDelimiter},
+	LowerRightCorner:  {`\lrcorner`, "⌟", CloseDelimiter},
+	Vert:              {"|", "|", FenceDelimiter},
+	LeftVert:          {`\lvert`, "|", OpenDelimiter},
+	RightVert:         {`\rvert`, "|", CloseDelimiter},
+	DoubleVert:        {`\|`, "‖", FenceDelimiter},
+	LeftDoubleVert:    {`\lVert`, "‖", OpenDelimiter},
+	RightDoubleVert:   {`\rVert`, "‖", CloseDelimiter},
+	Slash:             {"/", "/", FenceDelimiter},
+	Backslash:         {`\backslash`, `\`, FenceDelimiter},
+	UpArrow:           {`\uparrow`, "↑", FenceDelimiter},
+	DownArrow:         {`\downarrow`, "↓", FenceDelimiter},
+	UpDownArrow:       {`\updownarrow`, "↕", FenceDelimiter},
+	DoubleUpArrow:     {`\Uparrow`, "⇑", FenceDelimiter},
+	DoubleDownArrow:   {`\Downarrow`, "⇓", FenceDelimiter},
+	DoubleUpDownArrow: {`\Updownarrow`, "⇕", FenceDelimiter},
+}
+
+// String returns the delimiter as written in LaTeX, such as "(" or
+// `\langle`.
+func (d Delimiter) String() string {
+	if d < 0 || int(d) >= len(delimiters) {
+		return "?"
+	}
+	return delimiters[d].latex
+}
+
+// Glyph returns the Unicode character of the delimiter, or "" for
+// NoDelimiter. Delimiters that differ only in their class, such as Vert
+// and LeftVert, share a glyph.
+func (d Delimiter) Glyph() string {
+	if d < 0 || int(d) >= len(delimiters) {
+		return ""
+	}
+	return delimiters[d].glyph
+}
+
+// Class returns whether the delimiter opens or closes an expression, or
+// goes on either side.
+func (d Delimiter) Class() DelimiterClass {
+	if d < 0 || int(d) >= len(delimiters) {
+		return FenceDelimiter
+	}
+	return delimiters[d].class
+}
+
+// Mirror returns the delimiter on the other side of a pair, such as
+// RightParen for LeftParen. A fence delimiter is its own mirror.
+func (d Delimiter) Mirror() Delimiter {
+	switch d.Class() {
+	case OpenDelimiter:
+		return d + 1
+	case CloseDelimiter:
+		return d - 1
+	}
+	return d
+}
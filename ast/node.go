@@ -1,5 +1,10 @@
 package ast
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Node represents a node in the LaTeX math abstract syntax tree.
 type Node interface {
 	// Pos returns the position of the first character of the node.
@@ -20,11 +25,15 @@ type Node interface {
 // at any level of the AST, including as the root.
 type ExpressionNode struct {
 	Start    int
+	Stop     int // Optional: position after the closing brace of a group, or 0
 	Elements []Node
 }
 
 func (n *ExpressionNode) Pos() int { return n.Start }
 func (n *ExpressionNode) End() int {
+	if n.Stop > 0 {
+		return n.Stop
+	}
 	if len(n.Elements) == 0 {
 		return n.Start
 	}
@@ -39,15 +48,23 @@ func (n *ExpressionNode) Accept(v Visitor) {
 // typically created with \left and \right commands. These delimiters automatically
 // adjust their size based on the height of the enclosed content.
 // Examples: \left( ... \right), \left[ ... \right], \left\{ ... \right\}
+//
+// The content may be split by \middle separators, which grow with the
+// content as well, as in \left\{ x \middle| x > 0 \right\}.
 type DelimitedExpressionNode struct {
 	Start          int
+	Stop           int // Optional: position after the arguments of \genfrac, whose delimiters precede the content, or 0
 	LeftDelimiter  Node
 	Content        Node
 	RightDelimiter Node
+	Middle         []*DelimiterNode // Optional: the \middle separators, which are elements of Content
 }
 
 func (n *DelimitedExpressionNode) Pos() int { return n.Start }
 func (n *DelimitedExpressionNode) End() int {
+	if n.Stop > 0 {
+		return n.Stop
+	}
 	// the end is after the right Delimiter
 	return n.RightDelimiter.End()
 }
@@ -56,6 +73,24 @@ func (n *DelimitedExpressionNode) Accept(v Visitor) {
 	v.VisitDelimitedExpressionNode(n)
 }
 
+// ErrorNode represents input that could not be parsed. It keeps what was
+// parsed of it, such as the numerator of a \frac without denominator, so
+// that no part of the input is lost. Stop is the position after the
+// erroneous input.
+type ErrorNode struct {
+	Start    int
+	Stop     int
+	Message  string
+	Elements []Node
+}
+
+func (n *ErrorNode) Pos() int { return n.Start }
+func (n *ErrorNode) End() int { return n.Stop }
+
+func (n *ErrorNode) Accept(v Visitor) {
+	v.VisitErrorNode(n)
+}
+
 // --------------------
 // Leaf Nodes
 // --------------------
@@ -63,11 +98,17 @@ func (n *DelimitedExpressionNode) Accept(v Visitor) {
 // SymbolNode represents a single letter or variable, e.g., "x".
 type SymbolNode struct {
 	Start int
+	Stop  int // Optional: position after a symbol command such as \alpha, or 0
 	Value string
 }
 
 func (n *SymbolNode) Pos() int { return n.Start }
-func (n *SymbolNode) End() int { return n.Start + len(n.Value) }
+func (n *SymbolNode) End() int {
+	if n.Stop > 0 {
+		return n.Stop
+	}
+	return n.Start + len(n.Value)
+}
 
 func (n *SymbolNode) Accept(v Visitor) {
 	v.VisitSymbolNode(n)
@@ -89,11 +130,17 @@ func (n *NumberNode) Accept(v Visitor) {
 // OperatorNode represents an operator, e.g., "+", "-", "*".
 type OperatorNode struct {
 	Start int
+	Stop  int // Optional: position after an operator command such as \leq, or 0
 	Value string
 }
 
 func (n *OperatorNode) Pos() int { return n.Start }
-func (n *OperatorNode) End() int { return n.Start + len(n.Value) }
+func (n *OperatorNode) End() int {
+	if n.Stop > 0 {
+		return n.Stop
+	}
+	return n.Start + len(n.Value)
+}
 
 func (n *OperatorNode) Accept(v Visitor) {
 	v.VisitOperatorNode(n)
@@ -133,11 +180,17 @@ func (n *SpaceNode) Accept(v Visitor) {
 // DelimiterNode represents a visual math delimiter, such as "(" or "]".
 type DelimiterNode struct {
 	Start int
-	Value string
+	Stop  int // Optional: position after the delimiter as written, or 0 for the length of its LaTeX form
+	Kind  Delimiter
 }
 
 func (n *DelimiterNode) Pos() int { return n.Start }
-func (n *DelimiterNode) End() int { return n.Start + len(n.Value) }
+func (n *DelimiterNode) End() int {
+	if n.Stop > 0 {
+		return n.Stop
+	}
+	return n.Start + len(n.Kind.String())
+}
 
 func (n *DelimiterNode) Accept(v Visitor) {
 	v.VisitDelimiterNode(n)
@@ -147,6 +200,47 @@ func (n *DelimiterNode) Accept(v Visitor) {
 // Composite Nodes
 // --------------------
 
+// DelimiterRole is the role a sized delimiter plays in the formula, which
+// determines the spacing around it.
+type DelimiterRole int
+
+const (
+	OrdinaryRole DelimiterRole = iota // \big
+	OpenRole                          // \bigl
+	CloseRole                         // \bigr
+	RelationRole                      // \bigm
+)
+
+// String returns the name of the role.
+func (r DelimiterRole) String() string {
+	switch r {
+	case OpenRole:
+		return "open"
+	case CloseRole:
+		return "close"
+	case RelationRole:
+		return "relation"
+	default:
+		return "ordinary"
+	}
+}
+
+// SizedDelimiterNode represents a delimiter of a fixed size, such as
+// `\big(` or `\Biggr]`.
+type SizedDelimiterNode struct {
+	Start     int
+	Size      int // 1 for \big, 2 for \Big, 3 for \bigg, 4 for \Bigg
+	Role      DelimiterRole
+	Delimiter Node
+}
+
+func (n *SizedDelimiterNode) Pos() int { return n.Start }
+func (n *SizedDelimiterNode) End() int { return n.Delimiter.End() }
+
+func (n *SizedDelimiterNode) Accept(v Visitor) {
+	v.VisitSizedDelimiterNode(n)
+}
+
 // SuperscriptNode represents `base^exponent`.
 type SuperscriptNode struct {
 	Start    int
@@ -175,16 +269,59 @@ func (n *SubscriptNode) Accept(v Visitor) {
 	v.VisitSubscriptNode(n)
 }
 
-// FractionNode represents a LaTeX `\frac{a}{b}`.
+// Style is the math style a fraction or binomial is set in, which
+// variants such as \dfrac and \tbinom choose.
+type Style int
+
+const (
+	InheritStyle      Style = iota // the style of the surroundings, as for \frac
+	DisplayStyle                   // \dfrac, \dbinom
+	TextStyle                      // \tfrac, \tbinom
+	ScriptStyle                    // \genfrac with style 2
+	ScriptScriptStyle              // \genfrac with style 3
+	ContinuedStyle                 // \cfrac: display style for continued fractions
+)
+
+// String returns the name of the style, or "" for InheritStyle.
+func (s Style) String() string {
+	switch s {
+	case DisplayStyle:
+		return "display"
+	case TextStyle:
+		return "text"
+	case ScriptStyle:
+		return "script"
+	case ScriptScriptStyle:
+		return "scriptscript"
+	case ContinuedStyle:
+		return "continued"
+	default:
+		return ""
+	}
+}
+
+// FractionNode represents a LaTeX `\frac{a}{b}` or one of its variants,
+// such as `\dfrac{a}{b}` or `\cfrac[l]{a}{b}`, or a TeX generalized
+// fraction such as `{a \over b}` or `{a \atop b}`.
 type FractionNode struct {
 	Start       int
 	Numerator   Node
 	Denominator Node
+	Thickness   string // Optional: thickness of the fraction bar, such as "0pt" for \atop, or "" for the default
+	Style       Style
+	Align       string // Optional: "l" or "r" for the numerator of \cfrac[l] and \cfrac[r], or "" to center it
 }
 
 func (n *FractionNode) Pos() int { return n.Start }
 func (n *FractionNode) End() int { return n.Denominator.End() }
 
+// Ruled reports whether the fraction has a bar, which a fraction of zero
+// thickness such as `{a \atop b}` lacks.
+func (n *FractionNode) Ruled() bool {
+	value, err := strconv.ParseFloat(strings.TrimRight(n.Thickness, "abcdefghijklmnopqrstuvwxyz"), 64)
+	return n.Thickness == "" || err != nil || value != 0
+}
+
 func (n *FractionNode) Accept(v Visitor) {
 	v.VisitFractionNode(n)
 }
@@ -200,15 +337,15 @@ type LimitedOperatorNode struct {
 
 func (n *LimitedOperatorNode) Pos() int { return n.Start }
 func (n *LimitedOperatorNode) End() int {
-	// If there are limits, the end is the end of the last limit
+	// Length of the operator backslash + name, or the end of the last limit
+	end := n.Start + len(n.Operator) + 1
 	if n.UpperLimit != nil {
-		return n.UpperLimit.End()
+		end = max(end, n.UpperLimit.End())
 	}
 	if n.LowerLimit != nil {
-		return n.LowerLimit.End()
+		end = max(end, n.LowerLimit.End())
 	}
-	// Length of the operator backslash + name
-	return n.Start + len(n.Operator) + 1
+	return end
 }
 
 func (n *LimitedOperatorNode) Accept(v Visitor) {
@@ -238,11 +375,13 @@ func (n *SqrtNode) Accept(v Visitor) {
 	v.VisitSqrtNode(n)
 }
 
-// BinomNode represents a LaTeX `\binom{a}{b}` command for binomial coefficients.
+// BinomNode represents a LaTeX `\binom{a}{b}` command for binomial
+// coefficients, or one of its variants `\dbinom` and `\tbinom`.
 type BinomNode struct {
 	Start int
 	Upper Node
 	Lower Node
+	Style Style
 }
 
 func (n *BinomNode) Pos() int { return n.Start }
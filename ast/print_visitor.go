@@ -69,6 +69,38 @@ func (p *PrintVisitor) VisitExpressionNode(node *ExpressionNode) {
 	fmt.Fprintf(p.Writer, "}\n")
 }
 
+func (p *PrintVisitor) VisitErrorNode(node *ErrorNode) {
+	fmt.Fprintf(p.Writer, "*ast.ErrorNode {\n")
+	p.increaseDepth()
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Start: %d\n", node.Start)
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Stop: %d\n", node.Stop)
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Message: %q\n", node.Message)
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Elements: []ast.Node (len = %d) {\n", len(node.Elements))
+	p.increaseDepth()
+
+	for i, element := range node.Elements {
+		p.printIndent()
+		fmt.Fprintf(p.Writer, "%d: ", i)
+		element.Accept(p)
+	}
+
+	p.decreaseDepth()
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "}\n")
+
+	p.decreaseDepth()
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "}\n")
+}
+
 func (p *PrintVisitor) VisitDelimitedExpressionNode(node *DelimitedExpressionNode) {
 	fmt.Fprintf(p.Writer, "*ast.DelimitedExpressionNode {\n")
 	p.increaseDepth()
@@ -92,6 +124,16 @@ func (p *PrintVisitor) VisitDelimitedExpressionNode(node *DelimitedExpressionNod
 	fmt.Fprintf(p.Writer, "RightDelimiter: ")
 	node.RightDelimiter.Accept(p)
 
+	if len(node.Middle) > 0 {
+		// The separators are printed as part of the content
+		starts := make([]int, len(node.Middle))
+		for i, m := range node.Middle {
+			starts[i] = m.Start
+		}
+		p.printIndent()
+		fmt.Fprintf(p.Writer, "Middle: at %v\n", starts)
+	}
+
 	p.decreaseDepth()
 	p.printIndent()
 	fmt.Fprintf(p.Writer, "}\n")
@@ -181,7 +223,7 @@ func (p *PrintVisitor) VisitDelimiterNode(node *DelimiterNode) {
 	fmt.Fprintf(p.Writer, "Start: %d\n", node.Start)
 
 	p.printIndent()
-	fmt.Fprintf(p.Writer, "Value: %q\n", node.Value)
+	fmt.Fprintf(p.Writer, "Kind: %s\n", node.Kind)
 
 	p.decreaseDepth()
 	p.printIndent()
@@ -244,6 +286,19 @@ func (p *PrintVisitor) VisitFractionNode(node *FractionNode) {
 	fmt.Fprintf(p.Writer, "Denominator: ")
 	node.Denominator.Accept(p)
 
+	if node.Thickness != "" {
+		p.printIndent()
+		fmt.Fprintf(p.Writer, "Thickness: %q\n", node.Thickness)
+	}
+	if node.Style != InheritStyle {
+		p.printIndent()
+		fmt.Fprintf(p.Writer, "Style: %s\n", node.Style)
+	}
+	if node.Align != "" {
+		p.printIndent()
+		fmt.Fprintf(p.Writer, "Align: %q\n", node.Align)
+	}
+
 	p.decreaseDepth()
 	p.printIndent()
 	fmt.Fprintf(p.Writer, "}\n")
@@ -323,6 +378,33 @@ func (p *PrintVisitor) VisitBinomNode(node *BinomNode) {
 	fmt.Fprintf(p.Writer, "Lower: ")
 	node.Lower.Accept(p)
 
+	if node.Style != InheritStyle {
+		p.printIndent()
+		fmt.Fprintf(p.Writer, "Style: %s\n", node.Style)
+	}
+
+	p.decreaseDepth()
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "}\n")
+}
+
+func (p *PrintVisitor) VisitSizedDelimiterNode(node *SizedDelimiterNode) {
+	fmt.Fprintf(p.Writer, "*ast.SizedDelimiterNode {\n")
+	p.increaseDepth()
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Start: %d\n", node.Start)
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Size: %d\n", node.Size)
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Role: %s\n", node.Role)
+
+	p.printIndent()
+	fmt.Fprintf(p.Writer, "Delimiter: ")
+	node.Delimiter.Accept(p)
+
 	p.decreaseDepth()
 	p.printIndent()
 	fmt.Fprintf(p.Writer, "}\n")
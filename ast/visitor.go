@@ -5,6 +5,7 @@ type Visitor interface {
 	// Visit methods for container nodes
 	VisitExpressionNode(node *ExpressionNode)
 	VisitDelimitedExpressionNode(node *DelimitedExpressionNode)
+	VisitErrorNode(node *ErrorNode)
 
 	// Visit methods for leaf nodes
 	VisitSymbolNode(node *SymbolNode)
@@ -21,6 +22,7 @@ type Visitor interface {
 	VisitLimitedOperatorNode(node *LimitedOperatorNode)
 	VisitSqrtNode(node *SqrtNode)
 	VisitBinomNode(node *BinomNode)
+	VisitSizedDelimiterNode(node *SizedDelimiterNode)
 }
 
 // BaseVisitor provides default implementations for all Visitor methods.
@@ -44,6 +46,12 @@ func (v *BaseVisitor) VisitDelimitedExpressionNode(node *DelimitedExpressionNode
 	node.RightDelimiter.Accept(v)
 }
 
+func (v *BaseVisitor) VisitErrorNode(node *ErrorNode) {
+	for _, child := range node.Elements {
+		child.Accept(v)
+	}
+}
+
 func (v *BaseVisitor) VisitSymbolNode(node *SymbolNode)                           {}
 func (v *BaseVisitor) VisitNumberNode(node *NumberNode)                           {}
 func (v *BaseVisitor) VisitOperatorNode(node *OperatorNode)                       {}
@@ -88,3 +96,7 @@ func (v *BaseVisitor) VisitBinomNode(node *BinomNode) {
 	node.Upper.Accept(v)
 	node.Lower.Accept(v)
 }
+
+func (v *BaseVisitor) VisitSizedDelimiterNode(node *SizedDelimiterNode) {
+	node.Delimiter.Accept(v)
+}
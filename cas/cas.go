@@ -0,0 +1,349 @@
+// Package cas exports semantic expression trees in the input syntax of
+// computer algebra systems: SymPy, the Wolfram Language and Maxima.
+package cas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neox5/texmax/semantic"
+)
+
+// System selects the computer algebra system.
+type System int
+
+const (
+	SymPy   System = iota // Python with sympy imported
+	Wolfram               // Wolfram Language (Mathematica)
+	Maxima                // Maxima
+)
+
+// String returns the name of the system.
+func (s System) String() string {
+	switch s {
+	case SymPy:
+		return "SymPy"
+	case Wolfram:
+		return "Wolfram Language"
+	case Maxima:
+		return "Maxima"
+	default:
+		return "unknown"
+	}
+}
+
+// Format returns e in the syntax of the given system. Constructs the system
+// has no notation for, such as ≈ or definite sums without limits, are
+// reported as errors.
+func Format(e semantic.Expr, sys System) (string, error) {
+	d, ok := dialects[sys]
+	if !ok {
+		return "", fmt.Errorf("cas: unknown system %d", sys)
+	}
+	f := &formatter{sys: sys, dialect: d}
+	code, _, err := f.expr(e)
+	return code, err
+}
+
+// Precedence levels of the written expressions.
+const (
+	precRelation = iota + 1
+	precAdd
+	precMul
+	precUnary
+	precPow
+	precAtom
+)
+
+// formatter writes a single expression.
+type formatter struct {
+	sys     System
+	dialect *dialect
+	bound   []string // index variables of the enclosing sums and products
+}
+
+// expr returns e and its precedence.
+func (f *formatter) expr(e semantic.Expr) (string, int, error) {
+	switch e := e.(type) {
+	case *semantic.Number:
+		return e.Value, precAtom, nil
+	case *semantic.Ident:
+		if idx := f.boundIndex(e); idx != nil {
+			return f.index(&semantic.Ident{Name: e.Name}, idx)
+		}
+		name, err := f.dialect.ident(e)
+		return name, precAtom, err
+	case *semantic.Apply:
+		return f.apply(e)
+	case *semantic.Bind:
+		return f.bind(e)
+	default:
+		return "", 0, fmt.Errorf("cas: unsupported expression %T", e)
+	}
+}
+
+// boundIndex returns the index variable that subscripts v, as i in x_i
+// within a sum over i, or nil.
+func (f *formatter) boundIndex(v *semantic.Ident) *semantic.Ident {
+	if v.Subscript == "" {
+		return nil
+	}
+	for _, b := range f.bound {
+		if b == v.Subscript {
+			return &semantic.Ident{Name: b}
+		}
+	}
+	return nil
+}
+
+// operand returns e, in parentheses if it binds weaker than min.
+func (f *formatter) operand(e semantic.Expr, min int) (string, error) {
+	code, prec, err := f.expr(e)
+	if err != nil {
+		return "", err
+	}
+	if prec < min {
+		return "(" + code + ")", nil
+	}
+	return code, nil
+}
+
+// list returns the expressions separated by commas.
+func (f *formatter) list(es ...semantic.Expr) (string, error) {
+	codes := make([]string, len(es))
+	for i, e := range es {
+		code, _, err := f.expr(e)
+		if err != nil {
+			return "", err
+		}
+		codes[i] = code
+	}
+	return strings.Join(codes, ", "), nil
+}
+
+// call returns fn applied to args, with the brackets of the system.
+func (f *formatter) call(fn string, args ...semantic.Expr) (string, int, error) {
+	code, err := f.list(args...)
+	if err != nil {
+		return "", 0, err
+	}
+	return fn + f.dialect.open + code + f.dialect.close, precAtom, nil
+}
+
+// infix joins the arguments with op. Later arguments are put in
+// parentheses unless they bind tighter.
+func (f *formatter) infix(op string, prec int, args []semantic.Expr) (string, int, error) {
+	codes := make([]string, len(args))
+	for i, arg := range args {
+		min := prec
+		if i > 0 {
+			min++
+		}
+		code, err := f.operand(arg, min)
+		if err != nil {
+			return "", 0, err
+		}
+		codes[i] = code
+	}
+	return strings.Join(codes, op), prec, nil
+}
+
+func (f *formatter) apply(e *semantic.Apply) (string, int, error) {
+	d := f.dialect
+	if fn, ok := d.calls[e.Op]; ok {
+		return f.call(fn, e.Args...)
+	}
+	if op, ok := d.relations[e.Op]; ok {
+		return f.infix(op, precRelation, e.Args)
+	}
+
+	switch e.Op {
+	case semantic.Plus:
+		return f.infix(" + ", precAdd, e.Args)
+	case semantic.Minus:
+		return f.infix(" - ", precAdd, e.Args)
+	case semantic.Times:
+		return f.infix("*", precMul, e.Args)
+	case semantic.Divide:
+		return f.divide(e.Args[0], e.Args[1])
+	case semantic.Negate:
+		code, err := f.operand(e.Args[0], precUnary)
+		if err != nil {
+			return "", 0, err
+		}
+		return "-" + code, precUnary, nil
+	case semantic.Power:
+		base, err := f.operand(e.Args[0], precAtom)
+		if err != nil {
+			return "", 0, err
+		}
+		exp, err := f.operand(e.Args[1], precPow)
+		if err != nil {
+			return "", 0, err
+		}
+		return base + d.pow + exp, precPow, nil
+	case semantic.Root:
+		return f.root(e.Args)
+	case semantic.Index:
+		return f.index(e.Args[0], e.Args[1])
+	case semantic.Function:
+		return f.function(e)
+	}
+	return "", 0, fmt.Errorf("cas: %s has no %s equivalent", e.Op, f.sys)
+}
+
+// divide writes a / b. SymPy would divide integer literals as Python
+// floats, so their quotient is written as a Rational.
+func (f *formatter) divide(a, b semantic.Expr) (string, int, error) {
+	if f.sys == SymPy && isInteger(a) && isInteger(b) {
+		return f.call("sympy.Rational", a, b)
+	}
+	num, err := f.operand(a, precMul)
+	if err != nil {
+		return "", 0, err
+	}
+	den, err := f.operand(b, precMul+1)
+	if err != nil {
+		return "", 0, err
+	}
+	return num + "/" + den, precMul, nil
+}
+
+func (f *formatter) root(args []semantic.Expr) (string, int, error) {
+	d := f.dialect
+	if len(args) == 1 {
+		return f.call(d.sqrt, args[0])
+	}
+	if d.root != "" {
+		return f.call(d.root, args...)
+	}
+	base, err := f.operand(args[0], precAtom)
+	if err != nil {
+		return "", 0, err
+	}
+	exp, _, err := f.divide(&semantic.Number{Value: "1"}, args[1])
+	if err != nil {
+		return "", 0, err
+	}
+	return base + d.pow + "(" + exp + ")", precPow, nil
+}
+
+// index writes subscripts that are not part of a name, as in a_{i+1}.
+func (f *formatter) index(base, idx semantic.Expr) (string, int, error) {
+	switch f.sys {
+	case SymPy:
+		if _, ok := base.(*semantic.Ident); !ok {
+			return "", 0, fmt.Errorf("cas: only variables can be indexed in %s", f.sys)
+		}
+		b, _, err := f.expr(base)
+		if err != nil {
+			return "", 0, err
+		}
+		i, _, err := f.expr(idx)
+		if err != nil {
+			return "", 0, err
+		}
+		return "sympy.Indexed(" + b + ", " + i + ")", precAtom, nil
+	case Wolfram:
+		return f.call("Subscript", base, idx)
+	}
+	b, err := f.operand(base, precAtom)
+	if err != nil {
+		return "", 0, err
+	}
+	i, _, err := f.expr(idx)
+	if err != nil {
+		return "", 0, err
+	}
+	return b + "[" + i + "]", precAtom, nil
+}
+
+func (f *formatter) function(e *semantic.Apply) (string, int, error) {
+	// \log_b x
+	if e.Func == "log" && len(e.Args) == 2 {
+		switch f.sys {
+		case SymPy:
+			return f.call("sympy.log", e.Args[0], e.Args[1])
+		case Wolfram:
+			return f.call("Log", e.Args[1], e.Args[0])
+		}
+		num, _, err := f.call("log", e.Args[0])
+		if err != nil {
+			return "", 0, err
+		}
+		den, _, err := f.call("log", e.Args[1])
+		if err != nil {
+			return "", 0, err
+		}
+		return num + "/" + den, precMul, nil
+	}
+
+	fn, ok := f.dialect.functions[e.Func]
+	if !ok {
+		return "", 0, fmt.Errorf("cas: function %s has no %s equivalent", e.Func, f.sys)
+	}
+	return f.call(fn, e.Args...)
+}
+
+// bind writes sums, products, integrals and limits. Within a sum or
+// product, a variable subscripted by the index, as x_i, is indexed by it.
+func (f *formatter) bind(e *semantic.Bind) (string, int, error) {
+	if e.Var == nil {
+		return "", 0, fmt.Errorf("cas: %s without a variable", e.Op)
+	}
+	if (e.Lower == nil) != (e.Upper == nil) && e.Op != semantic.Limit {
+		return "", 0, fmt.Errorf("cas: %s needs both limits or none", e.Op)
+	}
+	if e.Lower == nil && e.Op != semantic.Integral {
+		return "", 0, fmt.Errorf("cas: %s needs limits", e.Op)
+	}
+
+	d := f.dialect
+	isIndex := e.Op == semantic.Sum || e.Op == semantic.Product
+	if isIndex {
+		f.bound = append(f.bound, e.Var.String())
+	}
+	body, _, err := f.expr(e.Body)
+	if isIndex {
+		f.bound = f.bound[:len(f.bound)-1]
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	v, err := d.ident(e.Var)
+	if err != nil {
+		return "", 0, err
+	}
+
+	parts := []string{body, v}
+	if e.Op == semantic.Limit {
+		to, _, err := f.expr(e.Lower)
+		if err != nil {
+			return "", 0, err
+		}
+		if f.sys == Wolfram {
+			parts[1] = v + " -> " + to
+		} else {
+			parts = append(parts, to)
+		}
+	} else if e.Lower != nil {
+		limits, err := f.list(e.Lower, e.Upper)
+		if err != nil {
+			return "", 0, err
+		}
+		switch f.sys {
+		case SymPy:
+			parts[1] = "(" + v + ", " + limits + ")"
+		case Wolfram:
+			parts[1] = "{" + v + ", " + limits + "}"
+		default:
+			parts = append(parts, limits)
+		}
+	}
+	return d.binds[e.Op] + d.open + strings.Join(parts, ", ") + d.close, precAtom, nil
+}
+
+func isInteger(e semantic.Expr) bool {
+	n, ok := e.(*semantic.Number)
+	return ok && !strings.Contains(n.Value, ".")
+}
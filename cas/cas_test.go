@@ -0,0 +1,91 @@
+package cas_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/cas"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input string
+		sys   cas.System
+		want  string
+	}{
+		{`\frac{1}{2} \sqrt{x}`, cas.SymPy, `sympy.Rational(1, 2)*sympy.sqrt(x)`},
+		{`\frac{1}{2} \sqrt{x}`, cas.Wolfram, `1/2*Sqrt[x]`},
+		{`\frac{1}{2} \sqrt{x}`, cas.Maxima, `1/2*sqrt(x)`},
+		{`\sqrt[3]{x+1}`, cas.SymPy, `sympy.root(x + 1, 3)`},
+		{`\sqrt[3]{x+1}`, cas.Wolfram, `(x + 1)^(1/3)`},
+		{`\sum_{i=1}^{n} i^2`, cas.SymPy, `sympy.Sum(i**2, (i, 1, n))`},
+		{`\sum_{i=1}^{n} i^2`, cas.Wolfram, `Sum[i^2, {i, 1, n}]`},
+		{`\sum_{i=1}^{n} i^2`, cas.Maxima, `sum(i^2, i, 1, n)`},
+		{`\lim_{x \to 0} \frac{\sin x}{x}`, cas.SymPy, `sympy.Limit(sympy.sin(x)/x, x, 0)`},
+		{`\lim_{x \to 0} \frac{\sin x}{x}`, cas.Wolfram, `Limit[Sin[x]/x, x -> 0]`},
+		{`\lim_{x \to 0} \frac{\sin x}{x}`, cas.Maxima, `limit(sin(x)/x, x, 0)`},
+		{`\int_0^1 x^2 dx`, cas.Wolfram, `Integrate[x^2, {x, 0, 1}]`},
+		{`\int x dx`, cas.Maxima, `integrate(x, x)`},
+		{`\binom{n}{k}`, cas.Wolfram, `Binomial[n, k]`},
+		{`\alpha_1 \leq 2\pi`, cas.Wolfram, `Subscript[\[Alpha], 1] <= 2*Pi`},
+		{`\alpha_1 \leq 2\pi`, cas.Maxima, `alpha_1 <= 2*%pi`},
+		{`a^2 + b^2 = c^2`, cas.SymPy, `sympy.Eq(a**2 + b**2, c**2)`},
+		{`a \neq -b`, cas.Maxima, `a # -b`},
+		{`\log_2 x`, cas.Wolfram, `Log[2, x]`},
+		{`\lambda \Gamma`, cas.SymPy, `lamda*Gamma`},
+		{`\lambda \Gamma`, cas.Wolfram, `\[Lambda]*\[CapitalGamma]`},
+		{`a - (b - c)`, cas.Maxima, `a - (b - c)`},
+		{`e^x`, cas.SymPy, `sympy.E**x`},
+		{`e^x`, cas.Wolfram, `E^x`},
+		{`e^x`, cas.Maxima, `%e^x`},
+		{`e_1 + e`, cas.SymPy, `e_1 + sympy.E`},
+		{`\sum_{i=1}^{n} x_i^2`, cas.SymPy, `sympy.Sum(sympy.Indexed(x, i)**2, (i, 1, n))`},
+		{`\sum_{i=1}^{n} x_i^2`, cas.Wolfram, `Sum[Subscript[x, i]^2, {i, 1, n}]`},
+		{`\sum_{i=1}^{n} x_i^2`, cas.Maxima, `sum(x[i]^2, i, 1, n)`},
+		{`\prod_{k=1}^{n} a_k + a_k`, cas.Maxima, `product(a[k], k, 1, n) + a_k`},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		if len(errs) > 0 {
+			t.Fatalf("%s: unexpected parse errors: %v", tt.input, errs)
+		}
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.input, err)
+		}
+		got, err := cas.Format(expr, tt.sys)
+		if err != nil {
+			t.Errorf("%s (%s): unexpected error: %v", tt.input, tt.sys, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s (%s): got %s, want %s", tt.input, tt.sys, got, tt.want)
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		sys   cas.System
+	}{
+		{`a \approx b`, cas.SymPy},
+		{`a \pm b`, cas.Maxima},
+		{`\int_0^1 x^2`, cas.Wolfram},
+		{`\sum_{i=1} i`, cas.SymPy},
+	}
+
+	for _, tt := range tests {
+		root, _ := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.input, err)
+		}
+		if _, err := cas.Format(expr, tt.sys); err == nil {
+			t.Errorf("%s (%s): expected error", tt.input, tt.sys)
+		}
+	}
+}
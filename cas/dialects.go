@@ -0,0 +1,213 @@
+package cas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+)
+
+// dialect holds the notation of a computer algebra system.
+type dialect struct {
+	open, close string // brackets around arguments
+	pow         string
+	sqrt        string
+	root        string // root of x with degree n; empty for x^(1/n)
+	calls       map[semantic.Op]string
+	relations   map[semantic.Op]string
+	functions   map[string]string
+	binds       map[semantic.Op]string
+	ident       func(v *semantic.Ident) (string, error)
+}
+
+var dialects = map[System]*dialect{
+	SymPy: {
+		open: "(", close: ")", pow: "**",
+		sqrt: "sympy.sqrt", root: "sympy.root",
+		calls: map[semantic.Op]string{
+			semantic.Mod:      "sympy.Mod",
+			semantic.Abs:      "sympy.Abs",
+			semantic.Floor:    "sympy.floor",
+			semantic.Ceil:     "sympy.ceiling",
+			semantic.Binomial: "sympy.binomial",
+			semantic.Equal:    "sympy.Eq",
+			semantic.NotEqual: "sympy.Ne",
+		},
+		relations: map[semantic.Op]string{
+			semantic.Less:      " < ",
+			semantic.LessEq:    " <= ",
+			semantic.Greater:   " > ",
+			semantic.GreaterEq: " >= ",
+		},
+		functions: map[string]string{
+			"sin": "sympy.sin", "cos": "sympy.cos", "tan": "sympy.tan",
+			"cot": "sympy.cot", "sec": "sympy.sec", "csc": "sympy.csc",
+			"arcsin": "sympy.asin", "arccos": "sympy.acos", "arctan": "sympy.atan",
+			"sinh": "sympy.sinh", "cosh": "sympy.cosh", "tanh": "sympy.tanh",
+			"exp": "sympy.exp", "ln": "sympy.log", "log": "sympy.log",
+			"max": "sympy.Max", "min": "sympy.Min", "arg": "sympy.arg",
+			"det": "sympy.det",
+		},
+		binds: map[semantic.Op]string{
+			semantic.Sum:      "sympy.Sum",
+			semantic.Product:  "sympy.Product",
+			semantic.Integral: "sympy.Integral",
+			semantic.Limit:    "sympy.Limit",
+		},
+		ident: sympyIdent,
+	},
+	Wolfram: {
+		open: "[", close: "]", pow: "^",
+		sqrt: "Sqrt",
+		calls: map[semantic.Op]string{
+			semantic.Mod:       "Mod",
+			semantic.Abs:       "Abs",
+			semantic.Floor:     "Floor",
+			semantic.Ceil:      "Ceiling",
+			semantic.Binomial:  "Binomial",
+			semantic.PlusMinus: "PlusMinus",
+		},
+		relations: map[semantic.Op]string{
+			semantic.Equal:     " == ",
+			semantic.NotEqual:  " != ",
+			semantic.Less:      " < ",
+			semantic.LessEq:    " <= ",
+			semantic.Greater:   " > ",
+			semantic.GreaterEq: " >= ",
+		},
+		functions: map[string]string{
+			"sin": "Sin", "cos": "Cos", "tan": "Tan",
+			"cot": "Cot", "sec": "Sec", "csc": "Csc",
+			"arcsin": "ArcSin", "arccos": "ArcCos", "arctan": "ArcTan",
+			"sinh": "Sinh", "cosh": "Cosh", "tanh": "Tanh",
+			"exp": "Exp", "ln": "Log", "log": "Log",
+			"max": "Max", "min": "Min", "arg": "Arg", "det": "Det",
+		},
+		binds: map[semantic.Op]string{
+			semantic.Sum:      "Sum",
+			semantic.Product:  "Product",
+			semantic.Integral: "Integrate",
+			semantic.Limit:    "Limit",
+		},
+		ident: wolframIdent,
+	},
+	Maxima: {
+		open: "(", close: ")", pow: "^",
+		sqrt: "sqrt",
+		calls: map[semantic.Op]string{
+			semantic.Mod:      "mod",
+			semantic.Abs:      "abs",
+			semantic.Floor:    "floor",
+			semantic.Ceil:     "ceiling",
+			semantic.Binomial: "binomial",
+		},
+		relations: map[semantic.Op]string{
+			semantic.Equal:     " = ",
+			semantic.NotEqual:  " # ",
+			semantic.Less:      " < ",
+			semantic.LessEq:    " <= ",
+			semantic.Greater:   " > ",
+			semantic.GreaterEq: " >= ",
+		},
+		functions: map[string]string{
+			"sin": "sin", "cos": "cos", "tan": "tan",
+			"cot": "cot", "sec": "sec", "csc": "csc",
+			"arcsin": "asin", "arccos": "acos", "arctan": "atan",
+			"sinh": "sinh", "cosh": "cosh", "tanh": "tanh",
+			"exp": "exp", "ln": "log", "log": "log",
+			"max": "max", "min": "min", "arg": "carg", "det": "determinant",
+		},
+		binds: map[semantic.Op]string{
+			semantic.Sum:      "sum",
+			semantic.Product:  "product",
+			semantic.Integral: "integrate",
+			semantic.Limit:    "limit",
+		},
+		ident: maximaIdent,
+	},
+}
+
+// spell returns s with Greek letters spelled out by name. Other characters
+// must be ASCII letters or digits.
+func spell(v *semantic.Ident, s string, greek func(name string) string) (string, error) {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		default:
+			name, ok := parser.GreekLetterName(string(r))
+			if !ok {
+				return "", fmt.Errorf("cas: no name for variable %s", v)
+			}
+			sb.WriteString(greek(name))
+		}
+	}
+	return sb.String(), nil
+}
+
+func plain(name string) string { return name }
+
+// sympyIdent writes x_1 as x_1, which SymPy prints with a subscript,
+// Greek letters by name, and π and e as the SymPy constants. Python keywords such as lambda are spelled the
+// way SymPy spells them.
+func sympyIdent(v *semantic.Ident) (string, error) {
+	if v.Name == "π" && v.Subscript == "" {
+		return "sympy.pi", nil
+	}
+	if v.Name == "e" && v.Subscript == "" {
+		return "sympy.E", nil
+	}
+	name, err := spell(v, v.Name, func(name string) string {
+		if name == "lambda" {
+			return "lamda"
+		}
+		return name
+	})
+	if err != nil || v.Subscript == "" {
+		return name, err
+	}
+	sub, err := spell(v, v.Subscript, plain)
+	return name + "_" + sub, err
+}
+
+// wolframIdent writes Greek letters as named characters such as \[Alpha],
+// subscripts with Subscript, and π and e as Pi and E.
+func wolframIdent(v *semantic.Ident) (string, error) {
+	if v.Name == "π" && v.Subscript == "" {
+		return "Pi", nil
+	}
+	if v.Name == "e" && v.Subscript == "" {
+		return "E", nil
+	}
+	greek := func(name string) string {
+		if name[0] >= 'A' && name[0] <= 'Z' {
+			return `\[Capital` + name + `]`
+		}
+		return `\[` + strings.ToUpper(name[:1]) + name[1:] + `]`
+	}
+	name, err := spell(v, v.Name, greek)
+	if err != nil || v.Subscript == "" {
+		return name, err
+	}
+	sub, err := spell(v, v.Subscript, greek)
+	return "Subscript[" + name + ", " + sub + "]", err
+}
+
+// maximaIdent writes Greek letters by name, which wxMaxima displays as
+// Greek letters, x_1 as x_1, and π and e as %pi and %e.
+func maximaIdent(v *semantic.Ident) (string, error) {
+	if v.Name == "π" && v.Subscript == "" {
+		return "%pi", nil
+	}
+	if v.Name == "e" && v.Subscript == "" {
+		return "%e", nil
+	}
+	name, err := spell(v, v.Name, plain)
+	if err != nil || v.Subscript == "" {
+		return name, err
+	}
+	sub, err := spell(v, v.Subscript, plain)
+	return name + "_" + sub, err
+}
@@ -6,40 +6,86 @@ import (
 	"os"
 	"strings"
 
+	"github.com/neox5/texmax/asciimath"
 	"github.com/neox5/texmax/ast"
-	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/diagnostic"
+	"github.com/neox5/texmax/source"
 	"github.com/neox5/texmax/tokenizer"
+	"github.com/neox5/texmax/typst"
 )
 
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options] 'latex_expression'\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s check-markdown [-color when] [-profile name] [-permissive] [file or directory...]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Example: %s '\\frac{a^2}{b}'\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
 
+	// Validate the formulas of Markdown files
+	if len(os.Args) > 1 && os.Args[1] == "check-markdown" {
+		os.Exit(checkMarkdown(os.Stdout, os.Args[2:]))
+	}
+
+	syntax := flag.String("syntax", "latex", "Input syntax: latex, asciimath, typst")
 	tokensOnly := flag.Bool("tokens", false, "Only show tokenization results")
+	format := flag.String("format", "ast", "Output format: ast, asciimath, typst, unicode, pretty, pretty-ascii, svg, speech, nemeth, ueb, contentmathml, openmath, go, python, numpy, javascript, sympy, wolfram, maxima")
+	lang := flag.String("lang", "en", "Language of the speech format: en, de")
+	brief := flag.Bool("brief", false, "Use brief verbosity for the speech format")
+	brf := flag.Bool("brf", false, "Write Braille ASCII instead of Unicode braille for the nemeth and ueb formats")
+	colorSetting := flag.String("color", "auto", "Colour diagnostics: auto, always, never")
+	profileName := flag.String("profile", "default", "LaTeX dialect: default, strict, amsmath, katex, mathjax")
+	permissive := flag.Bool("permissive", false, "Report unknown commands as warnings")
 	flag.Parse()
 
 	if flag.NArg() < 1 {
 		flag.Usage()
 		os.Exit(1)
 	}
+	profile, err := lookupProfile(*profileName, *permissive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
 
 	// Get input from command line arguments
 	input := strings.Join(flag.Args(), " ")
+
+	if *format != "ast" {
+		color, err := useColor(*colorSetting, os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		if err := render(os.Stdout, input, renderOptions{syntax: *syntax, format: *format, lang: *lang, brief: *brief, brf: *brf, color: color, profile: profile}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Printf("Input: %s\n\n", input)
+	file := source.NewFile("", input)
 
 	// Tokenize
-	tokens := tokenizer.Tokenize(input)
-
 	fmt.Println("Tokens:")
-	for i, tok := range tokens {
-		if tok.Type == tokenizer.EOF {
-			fmt.Printf("%d: %s at position %d\n", i, tok.Type, tok.Pos)
-		} else {
-			fmt.Printf("%d: %s %q at position %d\n", i, tok.Type, tok.Value, tok.Pos)
+	switch *syntax {
+	case "asciimath":
+		for i, tok := range asciimath.Tokenize(input) {
+			fmt.Printf("%d: %s %q at %s\n", i, tok.Type, tok.Value, file.Position(tok.Pos))
+		}
+	case "typst":
+		for i, tok := range typst.Tokenize(input) {
+			fmt.Printf("%d: %s %q at %s\n", i, tok.Type, tok.Value, file.Position(tok.Pos))
+		}
+	default:
+		for i, tok := range tokenizer.Tokenize(input) {
+			if tok.Type == tokenizer.EOF {
+				fmt.Printf("%d: %s at %s\n", i, tok.Type, file.Position(tok.Pos))
+			} else {
+				fmt.Printf("%d: %s %q at %s\n", i, tok.Type, tok.Value, file.Position(tok.Pos))
+			}
 		}
 	}
 
@@ -49,15 +95,17 @@ func main() {
 	}
 
 	// Parse
-	p := parser.New(tokens)
-	root, errors := p.Parse()
+	root, errors := parse(input, *syntax, profile)
 
 	// Print errors if any
 	if len(errors) > 0 {
-		fmt.Println("\nParser errors:")
-		for i, err := range errors {
-			fmt.Printf("%d: %s\n", i, err)
+		color, err := useColor(*colorSetting, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
 		}
+		fmt.Println("\nParser errors:")
+		diagnostic.Render(os.Stdout, file, errors, diagnostic.Options{Color: color})
 	}
 
 	// Print AST using the GoLikePrinter
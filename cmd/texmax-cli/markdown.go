@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/neox5/texmax/diagnostic"
+	"github.com/neox5/texmax/extract"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/source"
+)
+
+// checkMarkdown validates the formulas of the Markdown files in the files
+// and directory trees given after the flags and reports the diagnostics of
+// each file with the offending lines of the document underlined.
+// It returns the exit status: 1 if any formula has errors, 2 if a file
+// cannot be read. Warnings alone do not fail the check.
+func checkMarkdown(w *os.File, args []string) int {
+	flags := flag.NewFlagSet("check-markdown", flag.ExitOnError)
+	colorSetting := flags.String("color", "auto", "Colour diagnostics: auto, always, never")
+	profileName := flags.String("profile", "default", "LaTeX dialect: default, strict, amsmath, katex, mathjax")
+	permissive := flags.Bool("permissive", false, "Report unknown commands as warnings")
+	flags.Parse(args)
+	profile, err := lookupProfile(*profileName, *permissive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 2
+	}
+	color, err := useColor(*colorSetting, w)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	status := 0
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isMarkdown(path) {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var errs []parser.ParseError
+			for _, snippet := range extract.MarkdownProfile(path, string(src), profile) {
+				for _, e := range snippet.Errors {
+					errs = append(errs, shift(e, snippet.Offset))
+					if e.Severity == parser.Error {
+						status = max(status, 1)
+					}
+				}
+			}
+			return diagnostic.Render(w, source.NewFile(path, string(src)), errs, diagnostic.Options{Color: color})
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 2
+		}
+	}
+	return status
+}
+
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// shift moves the spans of a diagnostic about a formula by the offset of
+// the formula in its document.
+func shift(e parser.ParseError, offset int) parser.ParseError {
+	e.Pos += offset
+	e.End += offset
+	e.Related = append([]parser.Related(nil), e.Related...)
+	for i := range e.Related {
+		e.Related[i].Pos += offset
+		e.Related[i].End += offset
+	}
+	fixes := make([]parser.Fix, len(e.Fixes))
+	for i, fix := range e.Fixes {
+		fixes[i] = parser.Fix{Message: fix.Message}
+		for _, edit := range fix.Edits {
+			edit.Pos += offset
+			edit.End += offset
+			fixes[i].Edits = append(fixes[i].Edits, edit)
+		}
+	}
+	e.Fixes = fixes
+	return e
+}
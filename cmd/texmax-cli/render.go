@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/neox5/texmax/asciimath"
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/cas"
+	"github.com/neox5/texmax/codegen"
+	"github.com/neox5/texmax/diagnostic"
+	"github.com/neox5/texmax/mathml"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/braille"
+	"github.com/neox5/texmax/render/pretty"
+	"github.com/neox5/texmax/render/speech"
+	"github.com/neox5/texmax/render/svg"
+	"github.com/neox5/texmax/render/unicode"
+	"github.com/neox5/texmax/semantic"
+	"github.com/neox5/texmax/source"
+	"github.com/neox5/texmax/typst"
+)
+
+// renderOptions holds the command line settings of the output formats.
+type renderOptions struct {
+	syntax string // input syntax: latex, asciimath, typst
+	format string
+	lang   string // speech language: en, de
+	brief  bool   // brief speech verbosity
+	brf    bool   // Braille ASCII instead of Unicode braille
+	color  bool   // ANSI colours in diagnostics
+
+	profile parser.Profile // LaTeX dialect
+}
+
+// languages maps the code generation formats to languages.
+var languages = map[string]codegen.Language{
+	"go":         codegen.Go,
+	"python":     codegen.Python,
+	"numpy":      codegen.NumPy,
+	"javascript": codegen.JavaScript,
+}
+
+// systems maps the computer algebra formats to systems.
+var systems = map[string]cas.System{
+	"sympy":   cas.SymPy,
+	"wolfram": cas.Wolfram,
+	"maxima":  cas.Maxima,
+}
+
+// render parses input and writes it to w in the requested output format.
+// Parser errors are reported on stderr.
+func render(w io.Writer, input string, opts renderOptions) error {
+	root, errors := parse(input, opts.syntax, opts.profile)
+	diagnostic.Render(os.Stderr, source.NewFile("", input), errors, diagnostic.Options{Color: opts.color})
+
+	switch format := opts.format; format {
+	case "asciimath":
+		_, err := fmt.Fprintln(w, asciimath.Format(root))
+		return err
+	case "typst":
+		_, err := fmt.Fprintln(w, typst.Format(root))
+		return err
+	case "unicode":
+		_, err := fmt.Fprintln(w, unicode.Render(root))
+		return err
+	case "pretty":
+		_, err := fmt.Fprintln(w, pretty.Render(root, pretty.Unicode))
+		return err
+	case "pretty-ascii":
+		_, err := fmt.Fprintln(w, pretty.Render(root, pretty.ASCII))
+		return err
+	case "svg":
+		return svg.Render(w, root, svg.Options{})
+	case "speech":
+		var lang *speech.Language
+		switch opts.lang {
+		case "en":
+			lang = speech.English
+		case "de":
+			lang = speech.German
+		default:
+			return fmt.Errorf("unknown language %q", opts.lang)
+		}
+		verbosity := speech.Verbose
+		if opts.brief {
+			verbosity = speech.Brief
+		}
+		_, err := fmt.Fprintln(w, speech.Render(root, lang, verbosity))
+		return err
+	case "nemeth", "ueb":
+		code := braille.Nemeth
+		if format == "ueb" {
+			code = braille.UEB
+		}
+		out := braille.Unicode
+		if opts.brf {
+			out = braille.ASCII
+		}
+		_, err := fmt.Fprintln(w, braille.Render(root, code, out))
+		return err
+	case "contentmathml", "openmath":
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			return err
+		}
+		if format == "openmath" {
+			return mathml.WriteOpenMath(w, expr)
+		}
+		return mathml.WriteContent(w, expr)
+	case "go", "python", "numpy", "javascript":
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			return err
+		}
+		code, err := codegen.Generate(expr, languages[format], codegen.Options{})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, code)
+		return err
+	case "sympy", "wolfram", "maxima":
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			return err
+		}
+		out, err := cas.Format(expr, systems[format])
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, out)
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// parse parses input in the given syntax.
+func parse(input, syntax string, profile parser.Profile) (ast.Node, []parser.ParseError) {
+	switch syntax {
+	case "asciimath":
+		return asciimath.Parse(input)
+	case "typst":
+		return typst.Parse(input)
+	}
+	return parser.ParseContext(context.Background(), input, parser.Options{Profile: profile})
+}
+
+// profiles maps the -profile names to parser profiles.
+var profiles = map[string]parser.Profile{
+	"default": parser.Default,
+	"strict":  parser.Strict,
+	"amsmath": parser.AMSMath,
+	"katex":   parser.KaTeX,
+	"mathjax": parser.MathJax,
+}
+
+// lookupProfile returns the profile of the given name. A permissive
+// profile reports unknown commands as warnings.
+func lookupProfile(name string, permissive bool) (parser.Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return parser.Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	if permissive {
+		profile.UnknownCommands = parser.Warning
+	}
+	return profile, nil
+}
+
+// useColor decides from the -color setting whether to colour diagnostics
+// written to f. In auto mode colours are used on terminals unless the
+// NO_COLOR environment variable is set.
+func useColor(setting string, f *os.File) (bool, error) {
+	switch setting {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := f.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	}
+	return false, fmt.Errorf("unknown color setting %q", setting)
+}
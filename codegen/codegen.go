@@ -0,0 +1,422 @@
+// Package codegen turns semantic expression trees into source code
+// expressions for Go, Python and JavaScript, so that formulas can be pasted
+// into simulation code instead of being transcribed by hand.
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/neox5/texmax/semantic"
+)
+
+// Language selects the target of the generated code.
+type Language int
+
+const (
+	Go         Language = iota // Go with the math package
+	Python                     // Python with the math module
+	NumPy                      // Python with numpy imported as np
+	JavaScript                 // JavaScript with Math
+)
+
+// String returns the name of the language.
+func (l Language) String() string {
+	switch l {
+	case Go:
+		return "Go"
+	case Python:
+		return "Python"
+	case NumPy:
+		return "NumPy"
+	case JavaScript:
+		return "JavaScript"
+	default:
+		return "unknown"
+	}
+}
+
+// Options control the generated code.
+type Options struct {
+	// Names maps variables, written as by semantic.Ident.String() such as
+	// "x_1" or "α", to the names used in the code. Variables without an
+	// entry are named by DefaultName.
+	Names map[string]string
+}
+
+// Generate returns e as a source code expression in the given language.
+// Finite sums and products become loops in Go and JavaScript and generator
+// expressions in Python; a variable subscripted with the index, as x_i in
+// a sum over i, becomes an element access x[i]. Constructs without a
+// counterpart in the language, such as integrals and limits, are reported
+// as errors.
+func Generate(e semantic.Expr, lang Language, opts Options) (string, error) {
+	t, ok := targets[lang]
+	if !ok {
+		return "", fmt.Errorf("codegen: unknown language %d", lang)
+	}
+	g := &generator{lang: lang, target: t, opts: opts, used: map[string]bool{}}
+	g.collect(e)
+	code, _, err := g.expr(e)
+	return code, err
+}
+
+// Precedence levels of the generated expressions.
+const (
+	precRelation = iota + 1
+	precAdd
+	precMul
+	precUnary
+	precPow
+	precAtom
+)
+
+// generator writes a single expression.
+type generator struct {
+	lang   Language
+	target *target
+	opts   Options
+	used   map[string]bool // names of the variables, avoided by accumulators
+	bound  []string        // index variables of the enclosing sums and products
+}
+
+// collect records the names of all variables in e.
+func (g *generator) collect(e semantic.Expr) {
+	switch e := e.(type) {
+	case *semantic.Ident:
+		if name, err := g.name(e); err == nil {
+			g.used[name] = true
+		}
+	case *semantic.Apply:
+		for _, arg := range e.Args {
+			g.collect(arg)
+		}
+	case *semantic.Bind:
+		for _, sub := range []semantic.Expr{e.Lower, e.Upper, e.Body} {
+			if sub != nil {
+				g.collect(sub)
+			}
+		}
+		if e.Var != nil {
+			g.collect(e.Var)
+		}
+	}
+}
+
+// expr returns the code for e and its precedence.
+func (g *generator) expr(e semantic.Expr) (string, int, error) {
+	switch e := e.(type) {
+	case *semantic.Number:
+		return e.Value, precAtom, nil
+	case *semantic.Ident:
+		if idx := g.boundIndex(e); idx != nil {
+			return g.index(&semantic.Ident{Name: e.Name}, idx)
+		}
+		name, err := g.name(e)
+		return name, precAtom, err
+	case *semantic.Apply:
+		return g.apply(e)
+	case *semantic.Bind:
+		return g.bind(e)
+	default:
+		return "", 0, fmt.Errorf("codegen: unsupported expression %T", e)
+	}
+}
+
+// boundIndex returns the index variable that subscripts v, as i in x_i
+// within a sum over i, or nil. Options.Names takes precedence.
+func (g *generator) boundIndex(v *semantic.Ident) *semantic.Ident {
+	if v.Subscript == "" {
+		return nil
+	}
+	if _, ok := g.opts.Names[v.String()]; ok {
+		return nil
+	}
+	for _, b := range g.bound {
+		if b == v.Subscript {
+			return &semantic.Ident{Name: b}
+		}
+	}
+	return nil
+}
+
+// operand returns the code for e, in parentheses if it binds weaker
+// than min.
+func (g *generator) operand(e semantic.Expr, min int) (string, error) {
+	code, prec, err := g.expr(e)
+	if err != nil {
+		return "", err
+	}
+	if prec < min {
+		return "(" + code + ")", nil
+	}
+	return code, nil
+}
+
+// args returns the code for the arguments of a call.
+func (g *generator) args(args []semantic.Expr) (string, error) {
+	codes := make([]string, len(args))
+	for i, arg := range args {
+		code, _, err := g.expr(arg)
+		if err != nil {
+			return "", err
+		}
+		codes[i] = code
+	}
+	return strings.Join(codes, ", "), nil
+}
+
+// call returns fn applied to args.
+func (g *generator) call(fn string, args ...semantic.Expr) (string, int, error) {
+	code, err := g.args(args)
+	if err != nil {
+		return "", 0, err
+	}
+	return fn + "(" + code + ")", precAtom, nil
+}
+
+// infix joins the arguments with op. The first argument may have the same
+// precedence; later ones are put in parentheses unless they bind tighter.
+func (g *generator) infix(op string, prec int, args []semantic.Expr) (string, int, error) {
+	codes := make([]string, len(args))
+	for i, arg := range args {
+		min := prec
+		if i > 0 {
+			min++
+		}
+		code, err := g.operand(arg, min)
+		if err != nil {
+			return "", 0, err
+		}
+		codes[i] = code
+	}
+	return strings.Join(codes, " "+op+" "), prec, nil
+}
+
+func (g *generator) apply(e *semantic.Apply) (string, int, error) {
+	t := g.target
+	if op, ok := relations[e.Op]; ok {
+		if g.lang == JavaScript && (e.Op == semantic.Equal || e.Op == semantic.NotEqual) {
+			op += "="
+		}
+		return g.infix(op, precRelation, e.Args)
+	}
+
+	switch e.Op {
+	case semantic.Plus:
+		return g.infix("+", precAdd, e.Args)
+	case semantic.Minus:
+		return g.infix("-", precAdd, e.Args)
+	case semantic.Times:
+		return g.infix("*", precMul, e.Args)
+	case semantic.Divide:
+		return g.divide(e.Args[0], e.Args[1])
+	case semantic.Negate:
+		code, err := g.operand(e.Args[0], precUnary)
+		if err != nil {
+			return "", 0, err
+		}
+		return "-" + code, precUnary, nil
+	case semantic.Mod:
+		if t.mod != "" {
+			return g.call(t.mod, e.Args...)
+		}
+		return g.infix("%", precMul, e.Args)
+	case semantic.Power:
+		return g.power(e.Args[0], e.Args[1])
+	case semantic.Root:
+		return g.root(e.Args)
+	case semantic.Abs:
+		return g.call(t.abs, e.Args...)
+	case semantic.Floor:
+		return g.call(t.floor, e.Args...)
+	case semantic.Ceil:
+		return g.call(t.ceil, e.Args...)
+	case semantic.Binomial:
+		if t.binomial == "" {
+			break
+		}
+		return g.call(t.binomial, e.Args...)
+	case semantic.Index:
+		return g.index(e.Args[0], e.Args[1])
+	case semantic.Function:
+		return g.function(e)
+	}
+	return "", 0, fmt.Errorf("codegen: %s has no %s equivalent", e.Op, g.lang)
+}
+
+// divide writes a / b. In Go, a quotient of constants is integer division,
+// so a constant numerator is converted to float64.
+func (g *generator) divide(a, b semantic.Expr) (string, int, error) {
+	num, err := g.operand(a, precMul)
+	if err != nil {
+		return "", 0, err
+	}
+	den, err := g.operand(b, precMul+1)
+	if err != nil {
+		return "", 0, err
+	}
+	if g.lang == Go && isConstant(a) && isConstant(b) {
+		num, _, _ = g.expr(a)
+		num = "float64(" + num + ")"
+	}
+	return num + " / " + den, precMul, nil
+}
+
+func (g *generator) power(base, exp semantic.Expr) (string, int, error) {
+	if g.isEuler(base) {
+		return g.call(g.target.functions["exp"], exp)
+	}
+	if g.target.pow != "" {
+		return g.call(g.target.pow, base, exp)
+	}
+	b, err := g.operand(base, precAtom)
+	if err != nil {
+		return "", 0, err
+	}
+	x, err := g.operand(exp, precPow)
+	if err != nil {
+		return "", 0, err
+	}
+	return b + " ** " + x, precPow, nil
+}
+
+// root writes square and cube roots with the functions of the language and
+// other roots as powers.
+func (g *generator) root(args []semantic.Expr) (string, int, error) {
+	if len(args) == 1 || isNumber(args[1], "2") {
+		return g.call(g.target.sqrt, args[0])
+	}
+	if isNumber(args[1], "3") && g.target.cbrt != "" {
+		return g.call(g.target.cbrt, args[0])
+	}
+	exp := &semantic.Apply{Op: semantic.Divide, Args: []semantic.Expr{&semantic.Number{Value: "1"}, args[1]}}
+	return g.power(args[0], exp)
+}
+
+// index writes a_{i+1} as an element access.
+func (g *generator) index(base, idx semantic.Expr) (string, int, error) {
+	b, err := g.operand(base, precAtom)
+	if err != nil {
+		return "", 0, err
+	}
+	i, _, err := g.expr(idx)
+	if err != nil {
+		return "", 0, err
+	}
+	if g.lang == Go {
+		i = "int(" + i + ")"
+	}
+	return b + "[" + i + "]", precAtom, nil
+}
+
+func (g *generator) function(e *semantic.Apply) (string, int, error) {
+	// \log_b x
+	if e.Func == "log" && len(e.Args) == 2 {
+		num := &semantic.Apply{Op: semantic.Function, Func: "ln", Args: e.Args[:1]}
+		den := &semantic.Apply{Op: semantic.Function, Func: "ln", Args: e.Args[1:]}
+		return g.divide(num, den)
+	}
+
+	if fn, ok := g.target.functions[e.Func]; ok {
+		return g.call(fn, e.Args...)
+	}
+
+	// Reciprocal trigonometric functions
+	if fn, ok := reciprocals[e.Func]; ok {
+		call, _, err := g.call(g.target.functions[fn], e.Args...)
+		if err != nil {
+			return "", 0, err
+		}
+		return "1 / " + call, precMul, nil
+	}
+	return "", 0, fmt.Errorf("codegen: function %s has no %s equivalent", e.Func, g.lang)
+}
+
+// bind writes finite sums and products.
+func (g *generator) bind(e *semantic.Bind) (string, int, error) {
+	if e.Op != semantic.Sum && e.Op != semantic.Product {
+		return "", 0, fmt.Errorf("codegen: %s has no %s equivalent", e.Op, g.lang)
+	}
+	if e.Var == nil || e.Lower == nil || e.Upper == nil {
+		return "", 0, fmt.Errorf("codegen: %s needs an index variable and both limits", e.Op)
+	}
+
+	v, err := g.name(e.Var)
+	if err != nil {
+		return "", 0, err
+	}
+	lower, _, err := g.expr(e.Lower)
+	if err != nil {
+		return "", 0, err
+	}
+	upper, _, err := g.expr(e.Upper)
+	if err != nil {
+		return "", 0, err
+	}
+	g.bound = append(g.bound, e.Var.String())
+	body, _, err := g.expr(e.Body)
+	g.bound = g.bound[:len(g.bound)-1]
+	if err != nil {
+		return "", 0, err
+	}
+
+	if g.lang == Python || g.lang == NumPy {
+		// range takes integers and excludes its end
+		start, end := lower, "int("+upper+") + 1"
+		if _, err := strconv.Atoi(lower); err != nil {
+			start = "int(" + lower + ")"
+		}
+		if n, err := strconv.Atoi(upper); err == nil {
+			end = strconv.Itoa(n + 1)
+		}
+		fn := "sum"
+		if e.Op == semantic.Product {
+			fn = "math.prod"
+		}
+		return fmt.Sprintf("%s(%s for %s in range(%s, %s))", fn, body, v, start, end), precAtom, nil
+	}
+
+	acc, init, update := g.fresh("sum"), "0", "+="
+	if e.Op == semantic.Product {
+		acc, init, update = g.fresh("prod"), "1", "*="
+	}
+	if g.lang == Go {
+		return fmt.Sprintf("func() float64 { %s := %s.0; for %s := float64(%s); %s <= %s; %s++ { %s %s %s }; return %s }()",
+			acc, init, v, lower, v, upper, v, acc, update, body, acc), precAtom, nil
+	}
+	return fmt.Sprintf("(() => { let %s = %s; for (let %s = %s; %s <= %s; %s++) { %s %s %s; } return %s; })()",
+		acc, init, v, lower, v, upper, v, acc, update, body, acc), precAtom, nil
+}
+
+// fresh returns a name starting with prefix that no variable uses.
+func (g *generator) fresh(prefix string) string {
+	name := prefix
+	for i := 1; g.used[name]; i++ {
+		name = prefix + strconv.Itoa(i)
+	}
+	g.used[name] = true
+	return name
+}
+
+// isConstant reports whether e contains no variables.
+func isConstant(e semantic.Expr) bool {
+	switch e := e.(type) {
+	case *semantic.Number:
+		return true
+	case *semantic.Apply:
+		for _, arg := range e.Args {
+			if !isConstant(arg) {
+				return false
+			}
+		}
+		return e.Op != semantic.Function
+	}
+	return false
+}
+
+func isNumber(e semantic.Expr, value string) bool {
+	n, ok := e.(*semantic.Number)
+	return ok && n.Value == value
+}
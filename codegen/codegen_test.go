@@ -0,0 +1,102 @@
+package codegen_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/codegen"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		input string
+		lang  codegen.Language
+		want  string
+	}{
+		{`\frac{-b + \sqrt{b^2 - 4ac}}{2a}`, codegen.Go, `(-b + math.Sqrt(math.Pow(b, 2) - 4 * a * c)) / (2 * a)`},
+		{`\frac{-b + \sqrt{b^2 - 4ac}}{2a}`, codegen.Python, `(-b + math.sqrt(b ** 2 - 4 * a * c)) / (2 * a)`},
+		{`\frac{-b + \sqrt{b^2 - 4ac}}{2a}`, codegen.JavaScript, `(-b + Math.sqrt(Math.pow(b, 2) - 4 * a * c)) / (2 * a)`},
+		{`\sin^2 \theta + \cos^2 \theta`, codegen.NumPy, `np.sin(theta) ** 2 + np.cos(theta) ** 2`},
+		{`\frac{1}{2} x_1`, codegen.Go, `float64(1) / 2 * x1`},
+		{`\sqrt[3]{x} + \sqrt[n]{y}`, codegen.Python, `x ** (1 / 3) + y ** (1 / n)`},
+		{`\sqrt[3]{x}`, codegen.JavaScript, `Math.cbrt(x)`},
+		{`2 \pi r`, codegen.JavaScript, `2 * Math.PI * r`},
+		{`e^x`, codegen.Go, `math.Exp(x)`},
+		{`e^{-x^2}`, codegen.JavaScript, `Math.exp(-Math.pow(x, 2))`},
+		{`2 e + e_1`, codegen.Python, `2 * math.e + e1`},
+		{`\lambda (a - b) - c`, codegen.Python, `lambda_ * (a - b) - c`},
+		{`a - (b - c)`, codegen.Go, `a - (b - c)`},
+		{`(-x)^{2}`, codegen.Python, `(-x) ** 2`},
+		{`2^{3^{x}}`, codegen.Python, `2 ** 3 ** x`},
+		{`|x| \leq \left\lfloor y \right\rfloor`, codegen.Go, `math.Abs(x) <= math.Floor(y)`},
+		{`a = b`, codegen.JavaScript, `a === b`},
+		{`\log_2 x`, codegen.Python, `math.log(x) / math.log(2)`},
+		{`\binom{n}{k}`, codegen.Python, `math.comb(n, k)`},
+		{`\sum_{i=1}^{10} i^2`, codegen.Python, `sum(i ** 2 for i in range(1, 11))`},
+		{`\prod_{k=1}^{n} k`, codegen.NumPy, `math.prod(k for k in range(1, int(n) + 1))`},
+		{`\sum_{i=1}^{n} x_i`, codegen.Go, `func() float64 { sum := 0.0; for i := float64(1); i <= n; i++ { sum += x[int(i)] }; return sum }()`},
+		{`\sum_{i=0}^{n} x_i`, codegen.Python, `sum(x[i] for i in range(0, int(n) + 1))`},
+		{`\sum_{i=1}^{n} x_i y_j`, codegen.JavaScript, `(() => { let sum = 0; for (let i = 1; i <= n; i++) { sum += x[i] * yj; } return sum; })()`},
+		{`\sum_{i=1}^{n} \sum_{j=1}^{i} j`, codegen.JavaScript, `(() => { let sum1 = 0; for (let i = 1; i <= n; i++) { sum1 += (() => { let sum = 0; for (let j = 1; j <= i; j++) { sum += j; } return sum; })(); } return sum1; })()`},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		if len(errs) > 0 {
+			t.Fatalf("%s: unexpected parse errors: %v", tt.input, errs)
+		}
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.input, err)
+		}
+		got, err := codegen.Generate(expr, tt.lang, codegen.Options{})
+		if err != nil {
+			t.Errorf("%s (%s): unexpected error: %v", tt.input, tt.lang, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s (%s): got %s, want %s", tt.input, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateNames(t *testing.T) {
+	root, _ := parser.New(tokenizer.Tokenize(`\alpha x_1 + \pi`)).Parse()
+	expr, _ := semantic.Structure(root)
+	opts := codegen.Options{Names: map[string]string{"α": "a", "x_1": "x[0]", "π": "PI"}}
+
+	got, err := codegen.Generate(expr, codegen.Go, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a * x[0] + PI"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		lang  codegen.Language
+	}{
+		{`\int_0^1 x dx`, codegen.Go},
+		{`\lim_{x \to 0} x`, codegen.Python},
+		{`\sum_{i=1} i`, codegen.JavaScript},
+		{`\binom{n}{k}`, codegen.Go},
+		{`a \pm b`, codegen.Python},
+		{`\det A`, codegen.NumPy},
+	}
+
+	for _, tt := range tests {
+		root, _ := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.input, err)
+		}
+		if _, err := codegen.Generate(expr, tt.lang, codegen.Options{}); err == nil {
+			t.Errorf("%s (%s): expected error", tt.input, tt.lang)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+)
+
+// name returns the name of v in the code: the mapping from Options.Names,
+// the constants π and e of the language, or the default name. Names that are
+// keywords of the language get a trailing underscore.
+func (g *generator) name(v *semantic.Ident) (string, error) {
+	if name, ok := g.opts.Names[v.String()]; ok {
+		return name, nil
+	}
+	if v.Name == "π" && v.Subscript == "" {
+		return g.target.pi, nil
+	}
+	if g.isEuler(v) {
+		return g.target.e, nil
+	}
+	name, err := DefaultName(v)
+	if err != nil {
+		return "", err
+	}
+	if g.target.keywords[name] {
+		name += "_"
+	}
+	return name, nil
+}
+
+// isEuler reports whether e is Euler's number: an unsubscripted e that
+// Options.Names does not map to a variable.
+func (g *generator) isEuler(e semantic.Expr) bool {
+	v, ok := e.(*semantic.Ident)
+	if !ok || v.Name != "e" || v.Subscript != "" {
+		return false
+	}
+	_, mapped := g.opts.Names["e"]
+	return !mapped
+}
+
+// DefaultName returns the identifier for a variable: Greek letters are
+// spelled out and the subscript is appended, so x_1 becomes "x1" and
+// α_i becomes "alphai". Variables with other non-ASCII letters have no
+// default name and must be mapped with Options.Names.
+func DefaultName(v *semantic.Ident) (string, error) {
+	var sb strings.Builder
+	for _, r := range v.Name + v.Subscript {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		default:
+			name, ok := parser.GreekLetterName(string(r))
+			if !ok {
+				return "", fmt.Errorf("codegen: no name for variable %s", v)
+			}
+			sb.WriteString(name)
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,112 @@
+package codegen
+
+import "github.com/neox5/texmax/semantic"
+
+// target holds the library functions of a language. An empty pow or mod
+// means the language has an operator for it.
+type target struct {
+	functions map[string]string
+	abs       string
+	floor     string
+	ceil      string
+	sqrt      string
+	cbrt      string // empty if there is none
+	pow       string
+	mod       string
+	binomial  string // empty if there is none
+	pi        string
+	e         string
+	keywords  map[string]bool
+}
+
+var targets = map[Language]*target{
+	Go: {
+		functions: map[string]string{
+			"sin": "math.Sin", "cos": "math.Cos", "tan": "math.Tan",
+			"arcsin": "math.Asin", "arccos": "math.Acos", "arctan": "math.Atan",
+			"sinh": "math.Sinh", "cosh": "math.Cosh", "tanh": "math.Tanh",
+			"exp": "math.Exp", "ln": "math.Log", "log": "math.Log",
+		},
+		abs: "math.Abs", floor: "math.Floor", ceil: "math.Ceil",
+		sqrt: "math.Sqrt", cbrt: "math.Cbrt", pow: "math.Pow", mod: "math.Mod",
+		pi: "math.Pi", e: "math.E",
+		keywords: keywords("break", "case", "chan", "const", "continue", "default",
+			"defer", "else", "fallthrough", "for", "func", "go", "goto", "if",
+			"import", "interface", "map", "package", "range", "return", "select",
+			"struct", "switch", "type", "var"),
+	},
+	Python: {
+		functions: map[string]string{
+			"sin": "math.sin", "cos": "math.cos", "tan": "math.tan",
+			"arcsin": "math.asin", "arccos": "math.acos", "arctan": "math.atan",
+			"sinh": "math.sinh", "cosh": "math.cosh", "tanh": "math.tanh",
+			"exp": "math.exp", "ln": "math.log", "log": "math.log",
+		},
+		abs: "abs", floor: "math.floor", ceil: "math.ceil",
+		sqrt: "math.sqrt", binomial: "math.comb",
+		pi:       "math.pi",
+		e:        "math.e",
+		keywords: pythonKeywords,
+	},
+	NumPy: {
+		functions: map[string]string{
+			"sin": "np.sin", "cos": "np.cos", "tan": "np.tan",
+			"arcsin": "np.arcsin", "arccos": "np.arccos", "arctan": "np.arctan",
+			"sinh": "np.sinh", "cosh": "np.cosh", "tanh": "np.tanh",
+			"exp": "np.exp", "ln": "np.log", "log": "np.log",
+		},
+		abs: "np.abs", floor: "np.floor", ceil: "np.ceil",
+		sqrt: "np.sqrt", cbrt: "np.cbrt", binomial: "math.comb",
+		pi:       "np.pi",
+		e:        "np.e",
+		keywords: pythonKeywords,
+	},
+	JavaScript: {
+		functions: map[string]string{
+			"sin": "Math.sin", "cos": "Math.cos", "tan": "Math.tan",
+			"arcsin": "Math.asin", "arccos": "Math.acos", "arctan": "Math.atan",
+			"sinh": "Math.sinh", "cosh": "Math.cosh", "tanh": "Math.tanh",
+			"exp": "Math.exp", "ln": "Math.log", "log": "Math.log",
+		},
+		abs: "Math.abs", floor: "Math.floor", ceil: "Math.ceil",
+		sqrt: "Math.sqrt", cbrt: "Math.cbrt", pow: "Math.pow",
+		pi: "Math.PI", e: "Math.E",
+		keywords: keywords("break", "case", "catch", "class", "const", "continue",
+			"debugger", "default", "delete", "do", "else", "export", "extends",
+			"finally", "for", "function", "if", "import", "in", "instanceof",
+			"let", "new", "return", "super", "switch", "this", "throw", "try",
+			"typeof", "var", "void", "while", "with", "yield"),
+	},
+}
+
+var pythonKeywords = keywords("and", "as", "assert", "async", "await", "break",
+	"class", "continue", "def", "del", "elif", "else", "except", "finally",
+	"for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal",
+	"not", "or", "pass", "raise", "return", "try", "while", "with", "yield")
+
+func keywords(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+// relations maps comparisons to their operators, which all three languages
+// share. JavaScript uses strict equality.
+var relations = map[semantic.Op]string{
+	semantic.Equal:     "==",
+	semantic.NotEqual:  "!=",
+	semantic.Less:      "<",
+	semantic.LessEq:    "<=",
+	semantic.Greater:   ">",
+	semantic.GreaterEq: ">=",
+}
+
+// reciprocals maps the reciprocal trigonometric functions to the functions
+// they are the reciprocal of.
+var reciprocals = map[string]string{
+	"cot": "tan",
+	"sec": "cos",
+	"csc": "sin",
+}
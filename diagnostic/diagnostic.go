@@ -0,0 +1,208 @@
+// Package diagnostic renders parser errors for people, in the style of
+// rustc and clang: each error is shown with the line of input it refers
+// to and the offending span underlined.
+//
+//	error[E0003]: unsupported command: \aplha
+//	 --> 1:5
+//	  |
+//	1 | x + \aplha
+//	  |     ^^^^^^
+//	  = help: did you mean \alpha?
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/source"
+)
+
+// Options controls the rendering.
+type Options struct {
+	// Color enables ANSI escape sequences.
+	Color bool
+}
+
+// ANSI escape sequences
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	red    = "\x1b[1;31m"
+	yellow = "\x1b[1;33m"
+	cyan   = "\x1b[1;36m"
+	blue   = "\x1b[1;34m"
+)
+
+// tabWidth is the number of spaces a tab is shown as.
+const tabWidth = 4
+
+// Render writes the diagnostics about the input f ordered by position,
+// followed by a line counting the errors and warnings. Nothing is written
+// for no diagnostics.
+func Render(w io.Writer, f *source.File, errs []parser.ParseError, opts Options) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sorted := append([]parser.ParseError(nil), errs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Pos < sorted[j].Pos })
+
+	r := &renderer{w: w, f: f, opts: opts}
+	for _, e := range sorted {
+		r.diagnostic(e)
+		r.printf("\n")
+	}
+	r.summary(sorted)
+	return r.err
+}
+
+// String renders the diagnostics without colours.
+func String(f *source.File, errs []parser.ParseError) string {
+	var b strings.Builder
+	Render(&b, f, errs, Options{})
+	return b.String()
+}
+
+type renderer struct {
+	w      io.Writer
+	f      *source.File
+	opts   Options
+	gutter int // width of the line numbers
+	err    error
+}
+
+// label marks a span of the input in a snippet.
+type label struct {
+	pos, end int
+	mark     byte // '^' for the primary span, '-' for related ones
+	message  string
+	color    string
+}
+
+func (r *renderer) diagnostic(e parser.ParseError) {
+	color := severityColor(e.Severity)
+	name := e.Severity.String()
+	if e.Code != 0 {
+		name += "[" + e.Code.String() + "]"
+	}
+	r.printf("%s: %s\n", r.style(color, name), r.style(bold, e.Message))
+
+	labels := []label{{pos: e.Pos, end: e.End, mark: '^', color: color}}
+	for _, rel := range e.Related {
+		labels = append(labels, label{pos: rel.Pos, end: rel.End, mark: '-', message: rel.Message, color: blue})
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].pos < labels[j].pos })
+
+	r.gutter = 0
+	for _, l := range labels {
+		r.gutter = max(r.gutter, len(strconv.Itoa(r.f.Position(l.pos).Line)))
+	}
+	pad := strings.Repeat(" ", r.gutter)
+
+	r.printf("%s%s %s\n", pad, r.style(blue, "-->"), r.f.Position(e.Pos))
+	r.printf("%s %s\n", pad, r.style(blue, "|"))
+	for i := 0; i < len(labels); {
+		line := r.f.Position(labels[i].pos).Line
+		j := i
+		for j < len(labels) && r.f.Position(labels[j].pos).Line == line {
+			j++
+		}
+		r.snippet(line, labels[i:j])
+		i = j
+	}
+	for _, fix := range e.Fixes {
+		r.printf("%s %s %s\n", pad, r.style(blue, "="), r.style(bold, "help: ")+fix.Message)
+	}
+}
+
+// snippet prints a line of input with one underline row for each label on
+// it.
+func (r *renderer) snippet(line int, labels []label) {
+	text := r.f.Line(line)
+	num := fmt.Sprintf("%*d", r.gutter, line)
+	r.printf("%s %s\n", r.style(blue, num+" |"), expandTabs(text))
+
+	first := lineStart(r.f, labels[0].pos)
+	for _, l := range labels {
+		start := min(max(l.pos-first, 0), len(text))
+		end := min(max(l.end-first, start), len(text))
+		indent := width(text[:start])
+		n := max(width(text[start:end]), 1)
+
+		underline := strings.Repeat(" ", indent) + r.style(l.color, strings.Repeat(string(l.mark), n))
+		if l.message != "" {
+			underline += " " + r.style(l.color, l.message)
+		}
+		r.printf("%s %s %s\n", strings.Repeat(" ", r.gutter), r.style(blue, "|"), underline)
+	}
+}
+
+func (r *renderer) summary(errs []parser.ParseError) {
+	counts := map[parser.Severity]int{}
+	for _, e := range errs {
+		counts[e.Severity]++
+	}
+	var parts []string
+	for _, s := range []parser.Severity{parser.Error, parser.Warning, parser.Hint} {
+		if n := counts[s]; n > 0 {
+			word := s.String()
+			if n > 1 {
+				word += "s"
+			}
+			parts = append(parts, fmt.Sprintf("%d %s", n, word))
+		}
+	}
+	r.printf("%s\n", r.style(bold, strings.Join(parts, ", ")))
+}
+
+func (r *renderer) printf(format string, args ...any) {
+	if r.err == nil {
+		_, r.err = fmt.Fprintf(r.w, format, args...)
+	}
+}
+
+// style wraps s in the escape sequence if colours are enabled.
+func (r *renderer) style(seq, s string) string {
+	if !r.opts.Color || s == "" {
+		return s
+	}
+	return seq + s + reset
+}
+
+func severityColor(s parser.Severity) string {
+	switch s {
+	case parser.Warning:
+		return yellow
+	case parser.Hint:
+		return cyan
+	default:
+		return red
+	}
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func lineStart(f *source.File, offset int) int {
+	src := f.Source()
+	offset = max(0, min(offset, len(src)))
+	return strings.LastIndexByte(src[:offset], '\n') + 1
+}
+
+// width returns the number of columns s takes up.
+func width(s string) int {
+	n := 0
+	for _, c := range s {
+		if c == '\t' {
+			n += tabWidth
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", tabWidth))
+}
@@ -0,0 +1,80 @@
+package diagnostic_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/neox5/texmax/diagnostic"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/source"
+)
+
+func TestRender(t *testing.T) {
+	input := "x + \\aplha\n+ \\left( y"
+	_, errs := parser.Parse(input)
+
+	got := diagnostic.String(source.NewFile("f.tex", input), errs)
+	want := `error[E0003]: unsupported command: \aplha
+ --> f.tex:1:5
+  |
+1 | x + \aplha
+  |     ^^^^^^
+  = help: did you mean \alpha?
+
+error[E0008]: expected \right to close \left
+ --> f.tex:2:11
+  |
+2 | + \left( y
+  |   ----- \left opened here
+  |           ^
+  = help: insert \right.
+
+2 errors
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderRelatedOnOtherLine(t *testing.T) {
+	input := "\\left(\n\tx"
+	_, errs := parser.Parse(input)
+
+	got := diagnostic.String(source.NewFile("", input), errs)
+	want := `error[E0008]: expected \right to close \left
+ --> 2:3
+  |
+1 | \left(
+  | ----- \left opened here
+2 |     x
+  |      ^
+  = help: insert \right.
+
+1 error
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	input := `\foo`
+	_, errs := parser.Parse(input)
+
+	var b strings.Builder
+	if err := diagnostic.Render(&b, source.NewFile("", input), errs, diagnostic.Options{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "\x1b[1;31merror[E0003]\x1b[0m") {
+		t.Errorf("no coloured severity in %q", b.String())
+	}
+	if plain := diagnostic.String(source.NewFile("", input), errs); strings.Contains(plain, "\x1b") {
+		t.Errorf("escape sequence in %q", plain)
+	}
+}
+
+func TestRenderNothing(t *testing.T) {
+	if got := diagnostic.String(source.NewFile("", "x"), nil); got != "" {
+		t.Errorf("got %q, want nothing", got)
+	}
+}
@@ -0,0 +1,328 @@
+// Package eval compiles semantic expression trees into Go closures for fast
+// repeated evaluation, e.g. at millions of sample points when plotting.
+package eval
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/neox5/texmax/semantic"
+)
+
+// Func evaluates a compiled expression. The arguments are the values of the
+// variables in the order given to Compile; with fewer arguments the result
+// is NaN. A Func does not allocate and may be called concurrently.
+type Func func(args []float64) float64
+
+// constants are the symbols with a fixed value, unless they are listed as
+// variables.
+var constants = map[string]float64{
+	"π": math.Pi,
+	"e": math.E,
+}
+
+// Compile turns e into a Func over the given variables, named as by
+// semantic.Ident.String(), e.g. "x" or "x_1". Constant subexpressions are
+// folded at compile time, and variables are resolved to slice indices, so
+// evaluation neither walks the tree nor looks up names. Relations,
+// integrals and limits cannot be compiled.
+func Compile(e semantic.Expr, vars []string) (Func, error) {
+	c := &compiler{slots: map[string]int{}, size: len(vars)}
+	for i, v := range vars {
+		c.slots[v] = i
+	}
+	code, err := c.compile(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if code.isConst {
+		value := code.value
+		return func([]float64) float64 { return value }, nil
+	}
+	fn, n, size := code.fn, len(vars), c.size
+	if size == n {
+		return func(args []float64) float64 {
+			if len(args) < n {
+				return math.NaN()
+			}
+			return fn(args)
+		}, nil
+	}
+	// Sums and products need slots for their index variables, which are
+	// taken from a pool rather than allocated on every call
+	pool := &sync.Pool{New: func() any {
+		env := make([]float64, size)
+		return &env
+	}}
+	return func(args []float64) float64 {
+		if len(args) < n {
+			return math.NaN()
+		}
+		env := pool.Get().(*[]float64)
+		copy(*env, args[:n])
+		v := fn(*env)
+		pool.Put(env)
+		return v
+	}, nil
+}
+
+// code is a compiled expression: either a constant or a closure over the
+// slots of the variables.
+type code struct {
+	fn      func(env []float64) float64
+	isConst bool
+	value   float64
+}
+
+func constant(v float64) code {
+	return code{fn: func([]float64) float64 { return v }, isConst: true, value: v}
+}
+
+// compiler tracks the slots of the variables.
+type compiler struct {
+	slots map[string]int
+	size  int // number of slots, including index variables
+}
+
+func (c *compiler) compile(e semantic.Expr) (code, error) {
+	switch e := e.(type) {
+	case *semantic.Number:
+		v, err := strconv.ParseFloat(e.Value, 64)
+		if err != nil {
+			return code{}, fmt.Errorf("eval: invalid number %q", e.Value)
+		}
+		return constant(v), nil
+	case *semantic.Ident:
+		name := e.String()
+		if slot, ok := c.slots[name]; ok {
+			return code{fn: func(env []float64) float64 { return env[slot] }}, nil
+		}
+		if v, ok := constants[name]; ok {
+			return constant(v), nil
+		}
+		return code{}, fmt.Errorf("eval: unknown variable %s", name)
+	case *semantic.Apply:
+		return c.apply(e)
+	case *semantic.Bind:
+		return c.bind(e)
+	default:
+		return code{}, fmt.Errorf("eval: unsupported expression %T", e)
+	}
+}
+
+// all compiles es and reports whether all of them are constant.
+func (c *compiler) all(es []semantic.Expr) ([]code, bool, error) {
+	codes := make([]code, len(es))
+	isConst := true
+	for i, e := range es {
+		cd, err := c.compile(e)
+		if err != nil {
+			return nil, false, err
+		}
+		codes[i] = cd
+		isConst = isConst && cd.isConst
+	}
+	return codes, isConst, nil
+}
+
+func (c *compiler) apply(e *semantic.Apply) (code, error) {
+	args, isConst, err := c.all(e.Args)
+	if err != nil {
+		return code{}, err
+	}
+
+	var result code
+	switch e.Op {
+	case semantic.Plus:
+		result = fold(args, func(a, b float64) float64 { return a + b })
+	case semantic.Times:
+		result = fold(args, func(a, b float64) float64 { return a * b })
+	case semantic.Minus:
+		result = binary(args[0], args[1], func(a, b float64) float64 { return a - b })
+	case semantic.Divide:
+		result = binary(args[0], args[1], func(a, b float64) float64 { return a / b })
+	case semantic.Mod:
+		result = binary(args[0], args[1], math.Mod)
+	case semantic.Power:
+		result = power(args[0], args[1])
+	case semantic.Binomial:
+		result = binary(args[0], args[1], binomial)
+	case semantic.Negate:
+		result = unary(args[0], func(a float64) float64 { return -a })
+	case semantic.Abs:
+		result = unary(args[0], math.Abs)
+	case semantic.Floor:
+		result = unary(args[0], math.Floor)
+	case semantic.Ceil:
+		result = unary(args[0], math.Ceil)
+	case semantic.Root:
+		if len(args) == 1 {
+			result = unary(args[0], math.Sqrt)
+		} else {
+			result = binary(args[0], args[1], func(x, n float64) float64 { return math.Pow(x, 1/n) })
+		}
+	case semantic.Function:
+		if e.Func == "log" && len(args) == 2 {
+			result = binary(args[0], args[1], func(x, b float64) float64 { return math.Log(x) / math.Log(b) })
+			break
+		}
+		fn, ok := functions[e.Func]
+		if !ok {
+			return code{}, fmt.Errorf("eval: unknown function %s", e.Func)
+		}
+		result = unary(args[0], fn)
+	default:
+		return code{}, fmt.Errorf("eval: cannot evaluate %s", e.Op)
+	}
+
+	// Constant folding
+	if isConst {
+		return constant(result.fn(nil)), nil
+	}
+	return result, nil
+}
+
+// bind compiles finite sums and products as loops. The index variable gets
+// its own slot, shadowing a variable of the same name.
+func (c *compiler) bind(e *semantic.Bind) (code, error) {
+	if e.Op != semantic.Sum && e.Op != semantic.Product {
+		return code{}, fmt.Errorf("eval: cannot evaluate %s", e.Op)
+	}
+	if e.Var == nil || e.Lower == nil || e.Upper == nil {
+		return code{}, fmt.Errorf("eval: %s needs an index variable and both limits", e.Op)
+	}
+	lower, err := c.compile(e.Lower)
+	if err != nil {
+		return code{}, err
+	}
+	upper, err := c.compile(e.Upper)
+	if err != nil {
+		return code{}, err
+	}
+
+	name := e.Var.String()
+	saved, shadowed := c.slots[name]
+	slot := c.size
+	c.size++
+	c.slots[name] = slot
+	body, err := c.compile(e.Body)
+	if shadowed {
+		c.slots[name] = saved
+	} else {
+		delete(c.slots, name)
+	}
+	if err != nil {
+		return code{}, err
+	}
+
+	lo, hi, f := lower.fn, upper.fn, body.fn
+	var fn func(env []float64) float64
+	if e.Op == semantic.Sum {
+		fn = func(env []float64) float64 {
+			s := 0.0
+			for i, n := lo(env), hi(env); i <= n; i++ {
+				env[slot] = i
+				s += f(env)
+			}
+			return s
+		}
+	} else {
+		fn = func(env []float64) float64 {
+			p := 1.0
+			for i, n := lo(env), hi(env); i <= n; i++ {
+				env[slot] = i
+				p *= f(env)
+			}
+			return p
+		}
+	}
+
+	// Sums and products whose limits and body are all constant are folded
+	if lower.isConst && upper.isConst && body.isConst {
+		return constant(fn(make([]float64, c.size))), nil
+	}
+	return code{fn: fn}, nil
+}
+
+// fold combines the arguments of a sum or product from left to right.
+func fold(args []code, op func(a, b float64) float64) code {
+	result := args[0]
+	for _, arg := range args[1:] {
+		result = binary(result, arg, op)
+	}
+	return result
+}
+
+func unary(a code, op func(float64) float64) code {
+	f := a.fn
+	return code{fn: func(env []float64) float64 { return op(f(env)) }}
+}
+
+// binary specializes on a constant operand, so that e.g. 2x only calls
+// the closure for x.
+func binary(a, b code, op func(a, b float64) float64) code {
+	f, g := a.fn, b.fn
+	switch {
+	case b.isConst:
+		v := b.value
+		return code{fn: func(env []float64) float64 { return op(f(env), v) }}
+	case a.isConst:
+		v := a.value
+		return code{fn: func(env []float64) float64 { return op(v, g(env)) }}
+	}
+	return code{fn: func(env []float64) float64 { return op(f(env), g(env)) }}
+}
+
+// power avoids math.Pow for the common small integer exponents.
+func power(base, exp code) code {
+	f := base.fn
+	if exp.isConst {
+		switch exp.value {
+		case 1:
+			return base
+		case 2:
+			return code{fn: func(env []float64) float64 { x := f(env); return x * x }}
+		case 3:
+			return code{fn: func(env []float64) float64 { x := f(env); return x * x * x }}
+		case 0.5:
+			return unary(base, math.Sqrt)
+		case -1:
+			return code{fn: func(env []float64) float64 { return 1 / f(env) }}
+		}
+	}
+	return binary(base, exp, math.Pow)
+}
+
+// binomial returns n choose k for integral k.
+func binomial(n, k float64) float64 {
+	if k < 0 || k != math.Trunc(k) {
+		return math.NaN()
+	}
+	result := 1.0
+	for i := 1.0; i <= k; i++ {
+		result *= (n - k + i) / i
+	}
+	return result
+}
+
+// functions are the functions of one argument.
+var functions = map[string]func(float64) float64{
+	"sin":    math.Sin,
+	"cos":    math.Cos,
+	"tan":    math.Tan,
+	"cot":    func(x float64) float64 { return 1 / math.Tan(x) },
+	"sec":    func(x float64) float64 { return 1 / math.Cos(x) },
+	"csc":    func(x float64) float64 { return 1 / math.Sin(x) },
+	"arcsin": math.Asin,
+	"arccos": math.Acos,
+	"arctan": math.Atan,
+	"sinh":   math.Sinh,
+	"cosh":   math.Cosh,
+	"tanh":   math.Tanh,
+	"exp":    math.Exp,
+	"ln":     math.Log,
+	"log":    math.Log,
+}
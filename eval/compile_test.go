@@ -0,0 +1,116 @@
+package eval_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/neox5/texmax/eval"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func compile(t testing.TB, input string, vars ...string) eval.Func {
+	t.Helper()
+	root, errs := parser.New(tokenizer.Tokenize(input)).Parse()
+	if len(errs) > 0 {
+		t.Fatalf("%s: unexpected parse errors: %v", input, errs)
+	}
+	expr, err := semantic.Structure(root)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", input, err)
+	}
+	f, err := eval.Compile(expr, vars)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", input, err)
+	}
+	return f
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input string
+		vars  []string
+		args  []float64
+		want  float64
+	}{
+		{`\frac{-b + \sqrt{b^2 - 4ac}}{2a}`, []string{"a", "b", "c"}, []float64{1, -3, 2}, 2},
+		{`x^2 + 2x + 1`, []string{"x"}, []float64{3}, 16},
+		{`\sin^2 x + \cos^2 x`, []string{"x"}, []float64{0.7}, 1},
+		{`2 \pi r`, []string{"r"}, []float64{1}, 2 * math.Pi},
+		{`x_1 - x_2`, []string{"x_1", "x_2"}, []float64{5, 3}, 2},
+		{`\sqrt[3]{x}`, []string{"x"}, []float64{27}, 3},
+		{`|x| + \left\lfloor y \right\rfloor`, []string{"x", "y"}, []float64{-2, 2.5}, 4},
+		{`\log_2 x`, []string{"x"}, []float64{8}, 3},
+		{`\binom{n}{2}`, []string{"n"}, []float64{5}, 10},
+		{`\sum_{i=1}^{n} i^2`, []string{"n"}, []float64{3}, 14},
+		{`\prod_{k=1}^{n} k`, []string{"n"}, []float64{5}, 120},
+		{`\sum_{i=1}^{n} \sum_{j=1}^{i} x`, []string{"n", "x"}, []float64{3, 2}, 12},
+		{`\sum_{x=1}^{2} x + x`, []string{"x"}, []float64{10}, 13},
+		{`\frac{1}{2} + \sqrt{4}`, nil, nil, 2.5},
+	}
+
+	for _, tt := range tests {
+		f := compile(t, tt.input, tt.vars...)
+		if got := f(tt.args); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s at %v: got %v, want %v", tt.input, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		vars  []string
+	}{
+		{`x + y`, []string{"x"}},
+		{`x = 1`, []string{"x"}},
+		{`\int_0^1 x dx`, nil},
+		{`\lim_{x \to 0} x`, nil},
+		{`\det A`, []string{"A"}},
+	}
+
+	for _, tt := range tests {
+		root, _ := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.input, err)
+		}
+		if _, err := eval.Compile(expr, tt.vars); err == nil {
+			t.Errorf("%s: expected error", tt.input)
+		}
+	}
+}
+
+func TestCompileShortArgs(t *testing.T) {
+	for _, input := range []string{`x + n`, `\sum_{i=1}^{n} x`} {
+		f := compile(t, input, "x", "n")
+		if got := f([]float64{1}); !math.IsNaN(got) {
+			t.Errorf("%s with one argument: got %v, want NaN", input, got)
+		}
+	}
+}
+
+func TestCompileAllocs(t *testing.T) {
+	f := compile(t, `\sum_{i=1}^{n} i x`, "n", "x")
+	args := []float64{10, 2}
+	if allocs := testing.AllocsPerRun(100, func() { f(args) }); allocs > 0 {
+		t.Errorf("got %v allocations per call, want 0", allocs)
+	}
+}
+
+func BenchmarkCompiled(b *testing.B) {
+	f := compile(b, `\frac{\sin x}{x} + x^2 e^{-y}`, "x", "y")
+	args := []float64{0.5, 1.5}
+	for i := 0; i < b.N; i++ {
+		f(args)
+	}
+}
+
+func BenchmarkCompiledSum(b *testing.B) {
+	f := compile(b, `\sum_{i=1}^{n} \frac{x^i}{i}`, "n", "x")
+	args := []float64{10, 0.5}
+	for i := 0; i < b.N; i++ {
+		f(args)
+	}
+}
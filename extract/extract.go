@@ -0,0 +1,70 @@
+// Package extract finds the formulas in whole documents and parses each of
+// them, keeping track of where in the document it was found.
+package extract
+
+import (
+	"context"
+	"strings"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/source"
+)
+
+// Snippet is a formula found in a document.
+type Snippet struct {
+	File    string // name of the document
+	Math    string // the formula without its delimiters
+	Display bool   // display math rather than inline math
+	Env     string // name of the math environment, if any
+	Offset  int    // byte offset of Math in the document
+	Line    int    // line of Offset, starting at 1
+	Column  int    // column of Offset in runes, starting at 1
+
+	// Result of parsing Math. Error positions are byte offsets in Math.
+	Root   ast.Node
+	Errors []parser.ParseError
+
+	file *source.File
+}
+
+// Position returns the location in the document of the byte offset pos in
+// Math, such as the position of an error.
+func (s Snippet) Position(pos int) source.Position {
+	return s.file.Position(s.Offset + pos)
+}
+
+// reject records that the profile does not accept how the snippet is
+// written.
+func (s *Snippet) reject(code parser.Code, severity parser.Severity, msg string) {
+	s.Errors = append(s.Errors, parser.ParseError{Code: code, Severity: severity, Message: msg})
+}
+
+// unclosed records that the closing delimiter of the snippet is missing.
+func (s *Snippet) unclosed(closing string) {
+	s.Errors = append(s.Errors, parser.ParseError{Message: "missing closing " + closing, Pos: len(s.Math)})
+}
+
+// findUnescaped returns the offset of the first occurrence of closing at or
+// after start that is not preceded by a backslash escape, or len(src).
+func findUnescaped(src string, start int, closing string) int {
+	for i := start; i < len(src); i++ {
+		if strings.HasPrefix(src[i:], closing) {
+			return i
+		}
+		if src[i] == '\\' {
+			i++
+		}
+	}
+	return len(src)
+}
+
+// snippet returns the parsed snippet of the document between the byte
+// offsets start and end.
+func snippet(f *source.File, start, end int, display bool, env string, profile parser.Profile) Snippet {
+	s := Snippet{File: f.Name(), Math: f.Source()[start:end], Display: display, Env: env, Offset: start, file: f}
+	pos := f.Position(start)
+	s.Line, s.Column = pos.Line, pos.Column
+	s.Root, s.Errors = parser.ParseContext(context.Background(), s.Math, parser.Options{Profile: profile})
+	return s
+}
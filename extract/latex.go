@@ -0,0 +1,187 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/source"
+)
+
+// mathEnvironments are the environments whose body is math.
+var mathEnvironments = map[string]bool{
+	"math": true, "displaymath": true,
+	"equation": true, "equation*": true,
+	"align": true, "align*": true,
+	"alignat": true, "alignat*": true,
+	"flalign": true, "flalign*": true,
+	"gather": true, "gather*": true,
+	"multline": true, "multline*": true,
+	"eqnarray": true, "eqnarray*": true,
+}
+
+// verbatimEnvironments are the environments whose body is skipped.
+var verbatimEnvironments = map[string]bool{
+	"verbatim": true, "verbatim*": true, "Verbatim": true,
+	"lstlisting": true, "minted": true, "comment": true,
+}
+
+// LaTeX returns the formulas of a LaTeX document: inline math in $...$ and
+// \(...\), display math in $$...$$ and \[...\], and the bodies of math
+// environments such as equation and align. Comments, \verb and verbatim
+// environments are skipped. A formula without its closing delimiter
+// extends to the end of the document and gets an additional error.
+func LaTeX(file, src string) []Snippet {
+	return LaTeXProfile(file, src, parser.Default)
+}
+
+// LaTeXProfile is like LaTeX, but parses the formulas in the dialect of
+// profile. Math environments and $$ display math that the profile does not
+// accept are reported as errors of their formulas.
+func LaTeXProfile(file, src string, profile parser.Profile) []Snippet {
+	s := &latexScanner{file: source.NewFile(file, src), src: src, profile: profile}
+	s.scan()
+	return s.snippets
+}
+
+type latexScanner struct {
+	file     *source.File
+	src      string
+	pos      int
+	snippets []Snippet
+	profile  parser.Profile
+}
+
+func (s *latexScanner) scan() {
+	for s.pos < len(s.src) {
+		rest := s.src[s.pos:]
+		switch {
+		case rest[0] == '%':
+			s.skipComment()
+		case strings.HasPrefix(rest, "$$"):
+			s.math(2, "$$", true, "")
+			if s.profile.RejectDeprecated {
+				s.last().reject(parser.ErrDeprecated, parser.Error, `$$ is deprecated, use \[...\]`)
+			}
+		case rest[0] == '$':
+			s.math(1, "$", false, "")
+		case strings.HasPrefix(rest, `\(`):
+			s.math(2, `\)`, false, "")
+		case strings.HasPrefix(rest, `\[`):
+			s.math(2, `\]`, true, "")
+		case strings.HasPrefix(rest, `\begin{`):
+			s.environment()
+		case strings.HasPrefix(rest, `\verb`) && !isLetterAt(rest, 5):
+			s.skipVerb()
+		case rest[0] == '\\':
+			s.skipEscape()
+		default:
+			s.pos++
+		}
+	}
+}
+
+// last returns the snippet read last.
+func (s *latexScanner) last() *Snippet {
+	return &s.snippets[len(s.snippets)-1]
+}
+
+// math reads a formula that starts after an opening delimiter of length
+// open and ends at closing.
+func (s *latexScanner) math(open int, closing string, display bool, env string) {
+	start := s.pos + open
+	end := findUnescaped(s.src, start, closing)
+	snippet := snippet(s.file, start, end, display, env, s.profile)
+	if end == len(s.src) {
+		snippet.unclosed(closing)
+		s.pos = end
+	} else {
+		s.pos = end + len(closing)
+	}
+	s.snippets = append(s.snippets, snippet)
+}
+
+// environment handles \begin{name}: math environments yield their body,
+// verbatim environments are skipped.
+func (s *latexScanner) environment() {
+	nameStart := s.pos + len(`\begin{`)
+	n := strings.IndexByte(s.src[nameStart:], '}')
+	if n < 0 {
+		s.pos = len(s.src)
+		return
+	}
+	name := s.src[nameStart : nameStart+n]
+	bodyStart := nameStart + n + 1
+	closing := `\end{` + name + `}`
+
+	switch {
+	case mathEnvironments[name]:
+		// The column count of alignat is not part of the formula
+		if strings.HasPrefix(name, "alignat") {
+			bodyStart = skipArgument(s.src, bodyStart)
+		}
+		s.pos = bodyStart
+		s.math(0, closing, name != "math", name)
+		if !s.profile.HasEnvironment(name) {
+			msg := "environment " + name + " is not available in the " + s.profile.String() + " profile"
+			s.last().reject(parser.ErrUnsupportedEnvironment, s.profile.UnknownCommands, msg)
+		}
+	case verbatimEnvironments[name]:
+		end := strings.Index(s.src[bodyStart:], closing)
+		if end < 0 {
+			s.pos = len(s.src)
+			return
+		}
+		s.pos = bodyStart + end + len(closing)
+	default:
+		s.pos = bodyStart
+	}
+}
+
+// skipArgument returns the offset after the braced argument at pos, if any.
+func skipArgument(src string, pos int) int {
+	if pos < len(src) && src[pos] == '{' {
+		if n := strings.IndexByte(src[pos:], '}'); n >= 0 {
+			return pos + n + 1
+		}
+	}
+	return pos
+}
+
+func (s *latexScanner) skipComment() {
+	if n := strings.IndexByte(s.src[s.pos:], '\n'); n >= 0 {
+		s.pos += n + 1
+	} else {
+		s.pos = len(s.src)
+	}
+}
+
+// skipVerb skips \verb|...| with any delimiter character.
+func (s *latexScanner) skipVerb() {
+	i := s.pos + len(`\verb`)
+	if i < len(s.src) && s.src[i] == '*' {
+		i++
+	}
+	if i >= len(s.src) {
+		s.pos = len(s.src)
+		return
+	}
+	delim := s.src[i]
+	if n := strings.IndexByte(s.src[i+1:], delim); n >= 0 {
+		s.pos = i + 1 + n + 1
+	} else {
+		s.pos = len(s.src)
+	}
+}
+
+// skipEscape skips a backslash and the character after it, so that \$ and
+// \% are not taken for math or comments.
+func (s *latexScanner) skipEscape() {
+	s.pos += 2
+	if s.pos > len(s.src) {
+		s.pos = len(s.src)
+	}
+}
+
+func isLetterAt(s string, i int) bool {
+	return i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z')
+}
@@ -0,0 +1,97 @@
+package extract_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/extract"
+	"github.com/neox5/texmax/parser"
+)
+
+const document = `\documentclass{article}
+% A comment with $not math$
+\begin{document}
+Euler: $e^{i\pi} + 1 = 0$ costs \$5.
+\[ \frac{a}{b} \]
+\begin{verbatim}
+$x$ is verbatim
+\end{verbatim}
+Inline \verb|$y$| and \(\sqrt{2}\).
+\begin{equation}
+  x^2 + y^2 = z^2
+\end{equation}
+$$\sum_{i=1}^n i$$
+\end{document}
+`
+
+func TestLaTeX(t *testing.T) {
+	want := []struct {
+		math         string
+		display      bool
+		env          string
+		line, column int
+	}{
+		{`e^{i\pi} + 1 = 0`, false, "", 4, 9},
+		{` \frac{a}{b} `, true, "", 5, 3},
+		{`\sqrt{2}`, false, "", 9, 25},
+		{"\n  x^2 + y^2 = z^2\n", true, "equation", 10, 17},
+		{`\sum_{i=1}^n i`, true, "", 13, 3},
+	}
+
+	got := extract.LaTeX("doc.tex", document)
+	if len(got) != len(want) {
+		t.Fatalf("got %d snippets, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Math != w.math || g.Display != w.display || g.Env != w.env {
+			t.Errorf("snippet %d: got %q (display %v, env %q), want %q (display %v, env %q)",
+				i, g.Math, g.Display, g.Env, w.math, w.display, w.env)
+		}
+		if g.Line != w.line || g.Column != w.column {
+			t.Errorf("snippet %d: at %d:%d, want %d:%d", i, g.Line, g.Column, w.line, w.column)
+		}
+		if g.File != "doc.tex" || g.Root == nil || len(g.Errors) > 0 {
+			t.Errorf("snippet %d: file %q, errors %v", i, g.File, g.Errors)
+		}
+	}
+}
+
+func TestLaTeXErrors(t *testing.T) {
+	got := extract.LaTeX("doc.tex", "Text\n$\\frac{a}$ and $x + ")
+	if len(got) != 2 {
+		t.Fatalf("got %d snippets, want 2", len(got))
+	}
+	if len(got[0].Errors) == 0 {
+		t.Errorf("%q: expected errors", got[0].Math)
+	}
+	if pos := got[0].Position(got[0].Errors[0].Pos); pos.Filename != "doc.tex" || pos.Line != 2 {
+		t.Errorf("error at %s, want doc.tex line 2", pos)
+	}
+	if errs := got[1].Errors; len(errs) != 1 || errs[0].Message != "missing closing $" {
+		t.Errorf("%q: got errors %v, want missing closing $", got[1].Math, errs)
+	}
+}
+
+func TestLaTeXProfile(t *testing.T) {
+	got := extract.LaTeXProfile("doc.tex", document, parser.Strict)
+	codes := map[string]parser.Code{}
+	for _, s := range got {
+		for _, e := range s.Errors {
+			codes[s.Math] = e.Code
+		}
+	}
+	if codes[`\sum_{i=1}^n i`] != parser.ErrDeprecated {
+		t.Errorf("$$ in the strict profile: got %v, want a deprecation error", codes)
+	}
+	if len(codes) != 1 {
+		t.Errorf("got errors for %d snippets, want 1", len(codes))
+	}
+
+	got = extract.LaTeXProfile("doc.tex", `\begin{align} x \end{align}`, parser.Strict)
+	if errs := got[0].Errors; len(errs) != 1 || errs[0].Code != parser.ErrUnsupportedEnvironment {
+		t.Errorf("align in the strict profile: got %v, want an unsupported environment", errs)
+	}
+	if got := extract.LaTeXProfile("doc.tex", `\begin{align} x \end{align}`, parser.AMSMath); len(got[0].Errors) != 0 {
+		t.Errorf("align in the amsmath profile: unexpected errors %v", got[0].Errors)
+	}
+}
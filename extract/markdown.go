@@ -0,0 +1,205 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/source"
+)
+
+// Markdown returns the formulas of a Markdown document in the GitHub and
+// Pandoc dialects: inline math in $...$ and $`...`$, display math in
+// $$...$$ and fenced code blocks with the info string "math". Other code
+// blocks, code spans and escaped dollars are skipped. As in Pandoc, inline
+// math must not start after or end before a space, and a closing dollar
+// must not be followed by a digit, so "$5 and $10" is not math.
+func Markdown(file, src string) []Snippet {
+	return MarkdownProfile(file, src, parser.Default)
+}
+
+// MarkdownProfile is like Markdown, but parses the formulas in the dialect
+// of profile.
+func MarkdownProfile(file, src string, profile parser.Profile) []Snippet {
+	s := &markdownScanner{file: source.NewFile(file, src), src: src, profile: profile}
+	s.scan()
+	return s.snippets
+}
+
+type markdownScanner struct {
+	file     *source.File
+	src      string
+	pos      int
+	snippets []Snippet
+	profile  parser.Profile
+}
+
+func (s *markdownScanner) scan() {
+	for s.pos < len(s.src) {
+		rest := s.src[s.pos:]
+		switch {
+		case s.atLineStart() && isFence(rest):
+			s.fence()
+		case strings.HasPrefix(rest, "$$"):
+			s.display()
+		case strings.HasPrefix(rest, "$`"):
+			s.backtickMath()
+		case rest[0] == '$':
+			s.inline()
+		case rest[0] == '`':
+			s.codeSpan()
+		case rest[0] == '\\' && len(rest) > 1 && isPunct(rest[1]):
+			s.pos += 2
+		default:
+			s.pos++
+		}
+	}
+}
+
+// atLineStart reports whether only up to three spaces precede pos on its
+// line, so that a fence may start there.
+func (s *markdownScanner) atLineStart() bool {
+	start := strings.LastIndexByte(s.src[:s.pos], '\n') + 1
+	indent := s.src[start:s.pos]
+	return len(indent) <= 3 && strings.Trim(indent, " ") == ""
+}
+
+// isFence reports whether s starts with a code fence of at least three
+// backticks or tildes.
+func isFence(s string) bool {
+	return strings.HasPrefix(s, "```") || strings.HasPrefix(s, "~~~")
+}
+
+// fence skips a fenced code block, or yields its body if its info string
+// is "math".
+func (s *markdownScanner) fence() {
+	rest := s.src[s.pos:]
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	marker := rest[:n]
+
+	lineEnd := strings.IndexByte(rest, '\n')
+	if lineEnd < 0 {
+		s.pos = len(s.src)
+		return
+	}
+	info := strings.TrimSpace(rest[n:lineEnd])
+	bodyStart := s.pos + lineEnd + 1
+
+	// The closing fence is a line of at least as many fence characters
+	end, next := len(s.src), len(s.src)
+	for i := bodyStart; i < len(s.src); {
+		lineEnd := strings.IndexByte(s.src[i:], '\n')
+		line := s.src[i:]
+		if lineEnd >= 0 {
+			line = s.src[i : i+lineEnd]
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, marker) && strings.Trim(trimmed, marker[:1]) == "" {
+			end, next = i, i+len(line)
+			break
+		}
+		if lineEnd < 0 {
+			break
+		}
+		i += lineEnd + 1
+	}
+
+	if info == "math" {
+		snippet := snippet(s.file, bodyStart, end, true, "", s.profile)
+		if end == len(s.src) {
+			snippet.unclosed(marker)
+		}
+		s.snippets = append(s.snippets, snippet)
+	}
+	s.pos = next
+}
+
+func (s *markdownScanner) display() {
+	start := s.pos + 2
+	end := findUnescaped(s.src, start, "$$")
+	snippet := snippet(s.file, start, end, true, "", s.profile)
+	if end == len(s.src) {
+		snippet.unclosed("$$")
+		s.pos = end
+	} else {
+		s.pos = end + 2
+	}
+	s.snippets = append(s.snippets, snippet)
+}
+
+// backtickMath reads GitHub's $`...`$ inline math.
+func (s *markdownScanner) backtickMath() {
+	start := s.pos + 2
+	n := strings.Index(s.src[start:], "`$")
+	if n < 0 {
+		s.pos++
+		return
+	}
+	s.snippets = append(s.snippets, snippet(s.file, start, start+n, false, "", s.profile))
+	s.pos = start + n + 2
+}
+
+// inline reads $...$ following the Pandoc rules. A dollar that does not
+// start math is text.
+func (s *markdownScanner) inline() {
+	start := s.pos + 1
+	if start >= len(s.src) || isSpace(s.src[start]) {
+		s.pos++
+		return
+	}
+	for i := start; i < len(s.src); i++ {
+		switch s.src[i] {
+		case '\\':
+			i++
+		case '\n':
+			// Inline math does not span paragraphs
+			if strings.HasPrefix(strings.TrimLeft(s.src[i+1:], " \t"), "\n") {
+				s.pos++
+				return
+			}
+		case '$':
+			if isSpace(s.src[i-1]) || i+1 < len(s.src) && isDigit(s.src[i+1]) {
+				continue
+			}
+			s.snippets = append(s.snippets, snippet(s.file, start, i, false, "", s.profile))
+			s.pos = i + 1
+			return
+		}
+	}
+	s.pos++
+}
+
+// codeSpan skips a code span: a run of backticks up to the next run of
+// the same length. A run without a match is text.
+func (s *markdownScanner) codeSpan() {
+	rest := s.src[s.pos:]
+	n := len(rest) - len(strings.TrimLeft(rest, "`"))
+	marker := rest[:n]
+	for i := n; i < len(rest); {
+		j := strings.Index(rest[i:], marker)
+		if j < 0 {
+			break
+		}
+		i += j
+		run := len(rest[i:]) - len(strings.TrimLeft(rest[i:], "`"))
+		if run == n {
+			s.pos += i + n
+			return
+		}
+		i += run
+	}
+	s.pos += n
+}
+
+// isPunct reports whether c is ASCII punctuation, which Markdown allows to
+// be escaped with a backslash.
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
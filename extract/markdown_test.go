@@ -0,0 +1,58 @@
+package extract_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/extract"
+)
+
+const markdown = "# Notes\n" +
+	"Euler: $e^{i\\pi} + 1 = 0$, escaped \\$x$ is text.\n" +
+	"Costs $5 and $10.\n\n" +
+	"Code `$not math$` and ``a `$b$` c``, GitHub $`\\sqrt{2}`$.\n" +
+	"$$\n\\frac{a}{b}\n$$\n" +
+	"```go\nx := \"$y$\"\n```\n" +
+	"```math\n\\sum_{i=1}^n i\n```\n"
+
+func TestMarkdown(t *testing.T) {
+	want := []struct {
+		math         string
+		display      bool
+		line, column int
+	}{
+		{`e^{i\pi} + 1 = 0`, false, 2, 9},
+		{`\sqrt{2}`, false, 5, 47},
+		{"\n\\frac{a}{b}\n", true, 6, 3},
+		{"\\sum_{i=1}^n i\n", true, 13, 1},
+	}
+
+	got := extract.Markdown("notes.md", markdown)
+	if len(got) != len(want) {
+		t.Fatalf("got %d snippets, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Math != w.math || g.Display != w.display {
+			t.Errorf("snippet %d: got %q (display %v), want %q (display %v)", i, g.Math, g.Display, w.math, w.display)
+		}
+		if g.Line != w.line || g.Column != w.column {
+			t.Errorf("snippet %d: at %d:%d, want %d:%d", i, g.Line, g.Column, w.line, w.column)
+		}
+		if len(g.Errors) > 0 {
+			t.Errorf("snippet %d: unexpected errors %v", i, g.Errors)
+		}
+	}
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	got := extract.Markdown("notes.md", "Text $\\frac{a}$ and\n$$x +")
+	if len(got) != 2 {
+		t.Fatalf("got %d snippets, want 2", len(got))
+	}
+	if len(got[0].Errors) == 0 {
+		t.Errorf("%q: expected errors", got[0].Math)
+	}
+	if errs := got[1].Errors; len(errs) != 1 || errs[0].Message != "missing closing $$" {
+		t.Errorf("%q: got errors %v, want missing closing $$", got[1].Math, errs)
+	}
+}
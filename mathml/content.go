@@ -0,0 +1,182 @@
+package mathml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/neox5/texmax/semantic"
+)
+
+// contentOps maps semantic operations to Content MathML operator elements.
+var contentOps = map[semantic.Op]string{
+	semantic.Plus:      "plus",
+	semantic.Minus:     "minus",
+	semantic.Negate:    "minus",
+	semantic.Times:     "times",
+	semantic.Divide:    "divide",
+	semantic.Mod:       "rem",
+	semantic.Power:     "power",
+	semantic.Equal:     "eq",
+	semantic.NotEqual:  "neq",
+	semantic.Less:      "lt",
+	semantic.LessEq:    "leq",
+	semantic.Greater:   "gt",
+	semantic.GreaterEq: "geq",
+	semantic.Approx:    "approx",
+	semantic.Abs:       "abs",
+	semantic.Floor:     "floor",
+	semantic.Ceil:      "ceiling",
+	semantic.Index:     "selector",
+	semantic.Sum:       "sum",
+	semantic.Product:   "product",
+	semantic.Integral:  "int",
+	semantic.Limit:     "limit",
+}
+
+// contentFunctions maps function names to Content MathML elements.
+var contentFunctions = map[string]string{
+	"sin":    "sin",
+	"cos":    "cos",
+	"tan":    "tan",
+	"cot":    "cot",
+	"sec":    "sec",
+	"csc":    "csc",
+	"arcsin": "arcsin",
+	"arccos": "arccos",
+	"arctan": "arctan",
+	"sinh":   "sinh",
+	"cosh":   "cosh",
+	"tanh":   "tanh",
+	"exp":    "exp",
+	"ln":     "ln",
+	"log":    "log",
+	"max":    "max",
+	"min":    "min",
+	"arg":    "arg",
+	"det":    "determinant",
+}
+
+// WriteContent writes e as a Content MathML <math> element. Nothing is
+// written if e has no Content MathML equivalent.
+func WriteContent(w io.Writer, e semantic.Expr) error {
+	var buf bytes.Buffer
+	c := &contentWriter{xmlWriter{w: &buf}}
+	c.open("math", "xmlns", "http://www.w3.org/1998/Math/MathML")
+	if err := c.expr(e); err != nil {
+		return err
+	}
+	c.close("math")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+type contentWriter struct {
+	xmlWriter
+}
+
+func (c *contentWriter) expr(e semantic.Expr) error {
+	switch e := e.(type) {
+	case *semantic.Number:
+		c.text("cn", e.Value)
+	case *semantic.Ident:
+		c.text("ci", e.String())
+	case *semantic.Apply:
+		return c.apply(e)
+	case *semantic.Bind:
+		return c.bind(e)
+	default:
+		return fmt.Errorf("mathml: unsupported expression %T", e)
+	}
+	return nil
+}
+
+func (c *contentWriter) apply(e *semantic.Apply) error {
+	c.open("apply")
+	switch e.Op {
+	case semantic.Function:
+		if name, ok := contentFunctions[e.Func]; ok {
+			c.empty(name)
+		} else {
+			c.text("ci", e.Func, "type", "function")
+		}
+		// log with an explicit base: \log_b x
+		if e.Func == "log" && len(e.Args) == 2 {
+			c.open("logbase")
+			if err := c.expr(e.Args[1]); err != nil {
+				return err
+			}
+			c.close("logbase")
+			if err := c.expr(e.Args[0]); err != nil {
+				return err
+			}
+			c.close("apply")
+			return nil
+		}
+
+	case semantic.Root:
+		c.empty("root")
+		if len(e.Args) == 2 {
+			c.open("degree")
+			if err := c.expr(e.Args[1]); err != nil {
+				return err
+			}
+			c.close("degree")
+		}
+		if err := c.expr(e.Args[0]); err != nil {
+			return err
+		}
+		c.close("apply")
+		return nil
+
+	case semantic.Binomial:
+		c.text("csymbol", "binomial", "cd", "combinat1")
+
+	case semantic.PlusMinus:
+		c.text("csymbol", "plusminus", "cd", "multiops")
+
+	default:
+		name, ok := contentOps[e.Op]
+		if !ok {
+			return fmt.Errorf("mathml: %s has no Content MathML equivalent", e.Op)
+		}
+		c.empty(name)
+	}
+
+	for _, arg := range e.Args {
+		if err := c.expr(arg); err != nil {
+			return err
+		}
+	}
+	c.close("apply")
+	return nil
+}
+
+func (c *contentWriter) bind(e *semantic.Bind) error {
+	c.open("apply")
+	c.empty(contentOps[e.Op])
+	if e.Var != nil {
+		c.open("bvar")
+		c.text("ci", e.Var.String())
+		c.close("bvar")
+	}
+	if e.Lower != nil {
+		c.open("lowlimit")
+		if err := c.expr(e.Lower); err != nil {
+			return err
+		}
+		c.close("lowlimit")
+	}
+	if e.Upper != nil {
+		c.open("uplimit")
+		if err := c.expr(e.Upper); err != nil {
+			return err
+		}
+		c.close("uplimit")
+	}
+	if err := c.expr(e.Body); err != nil {
+		return err
+	}
+	c.close("apply")
+	return nil
+}
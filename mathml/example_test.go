@@ -0,0 +1,45 @@
+package mathml_test
+
+import (
+	"os"
+
+	"github.com/neox5/texmax/mathml"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func ExampleWriteContent() {
+	root, _ := parser.New(tokenizer.Tokenize(`\frac{a}{\sqrt{b}}`)).Parse()
+	expr, _ := semantic.Structure(root)
+
+	mathml.WriteContent(os.Stdout, expr)
+
+	// Output:
+	// <math xmlns="http://www.w3.org/1998/Math/MathML">
+	//   <apply>
+	//     <divide/>
+	//     <ci>a</ci>
+	//     <apply>
+	//       <root/>
+	//       <ci>b</ci>
+	//     </apply>
+	//   </apply>
+	// </math>
+}
+
+func ExampleWriteOpenMath() {
+	root, _ := parser.New(tokenizer.Tokenize(`\binom{n}{2}`)).Parse()
+	expr, _ := semantic.Structure(root)
+
+	mathml.WriteOpenMath(os.Stdout, expr)
+
+	// Output:
+	// <OMOBJ xmlns="http://www.openmath.org/OpenMath">
+	//   <OMA>
+	//     <OMS cd="combinat1" name="binomial"/>
+	//     <OMV name="n"/>
+	//     <OMI>2</OMI>
+	//   </OMA>
+	// </OMOBJ>
+}
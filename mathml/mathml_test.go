@@ -0,0 +1,35 @@
+package mathml_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/neox5/texmax/mathml"
+	"github.com/neox5/texmax/semantic"
+)
+
+// unsupported is an expression neither format can express.
+type unsupported struct{}
+
+func (unsupported) String() string { return "?" }
+
+func TestWriteErrorsWriteNothing(t *testing.T) {
+	e := &semantic.Apply{Op: semantic.Plus, Args: []semantic.Expr{
+		&semantic.Ident{Name: "a"},
+		&semantic.Apply{Op: semantic.Times, Args: []semantic.Expr{&semantic.Number{Value: "2"}, unsupported{}}},
+	}}
+
+	for name, write := range map[string]func(io.Writer, semantic.Expr) error{
+		"content":  mathml.WriteContent,
+		"openmath": mathml.WriteOpenMath,
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf, e); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if buf.Len() > 0 {
+			t.Errorf("%s: partial output\n%s", name, buf.String())
+		}
+	}
+}
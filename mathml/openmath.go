@@ -0,0 +1,229 @@
+package mathml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/neox5/texmax/semantic"
+)
+
+// symbol is an OpenMath symbol: a name defined in a content dictionary.
+type symbol struct {
+	cd   string
+	name string
+}
+
+// openMathOps maps semantic operations to OpenMath symbols.
+var openMathOps = map[semantic.Op]symbol{
+	semantic.Plus:      {"arith1", "plus"},
+	semantic.Minus:     {"arith1", "minus"},
+	semantic.Negate:    {"arith1", "unary_minus"},
+	semantic.Times:     {"arith1", "times"},
+	semantic.Divide:    {"arith1", "divide"},
+	semantic.Mod:       {"integer1", "remainder"},
+	semantic.Power:     {"arith1", "power"},
+	semantic.Root:      {"arith1", "root"},
+	semantic.PlusMinus: {"multiops", "plusminus"},
+	semantic.Equal:     {"relation1", "eq"},
+	semantic.NotEqual:  {"relation1", "neq"},
+	semantic.Less:      {"relation1", "lt"},
+	semantic.LessEq:    {"relation1", "leq"},
+	semantic.Greater:   {"relation1", "gt"},
+	semantic.GreaterEq: {"relation1", "geq"},
+	semantic.Approx:    {"relation1", "approx"},
+	semantic.Abs:       {"arith1", "abs"},
+	semantic.Floor:     {"rounding1", "floor"},
+	semantic.Ceil:      {"rounding1", "ceiling"},
+	semantic.Binomial:  {"combinat1", "binomial"},
+	semantic.Index:     {"linalg1", "vector_selector"},
+}
+
+// openMathFunctions maps function names to OpenMath symbols.
+var openMathFunctions = map[string]symbol{
+	"sin":    {"transc1", "sin"},
+	"cos":    {"transc1", "cos"},
+	"tan":    {"transc1", "tan"},
+	"cot":    {"transc1", "cot"},
+	"sec":    {"transc1", "sec"},
+	"csc":    {"transc1", "csc"},
+	"arcsin": {"transc1", "arcsin"},
+	"arccos": {"transc1", "arccos"},
+	"arctan": {"transc1", "arctan"},
+	"sinh":   {"transc1", "sinh"},
+	"cosh":   {"transc1", "cosh"},
+	"tanh":   {"transc1", "tanh"},
+	"exp":    {"transc1", "exp"},
+	"ln":     {"transc1", "ln"},
+	"log":    {"transc1", "log"},
+	"max":    {"minmax1", "max"},
+	"min":    {"minmax1", "min"},
+	"arg":    {"complex1", "argument"},
+	"det":    {"linalg1", "determinant"},
+}
+
+// WriteOpenMath writes e as an OpenMath XML object. Nothing is written if
+// e has no OpenMath equivalent.
+func WriteOpenMath(w io.Writer, e semantic.Expr) error {
+	var buf bytes.Buffer
+	o := &openMathWriter{xmlWriter{w: &buf}}
+	o.open("OMOBJ", "xmlns", "http://www.openmath.org/OpenMath")
+	if err := o.expr(e); err != nil {
+		return err
+	}
+	o.close("OMOBJ")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+type openMathWriter struct {
+	xmlWriter
+}
+
+func (o *openMathWriter) symbol(s symbol) {
+	o.empty("OMS", "cd", s.cd, "name", s.name)
+}
+
+func (o *openMathWriter) variable(v *semantic.Ident) {
+	o.empty("OMV", "name", v.String())
+}
+
+func (o *openMathWriter) integer(value string) {
+	o.text("OMI", value)
+}
+
+func (o *openMathWriter) expr(e semantic.Expr) error {
+	switch e := e.(type) {
+	case *semantic.Number:
+		if strings.Contains(e.Value, ".") {
+			o.empty("OMF", "dec", e.Value)
+		} else {
+			o.integer(e.Value)
+		}
+	case *semantic.Ident:
+		o.variable(e)
+	case *semantic.Apply:
+		return o.apply(e)
+	case *semantic.Bind:
+		return o.bind(e)
+	default:
+		return fmt.Errorf("openmath: unsupported expression %T", e)
+	}
+	return nil
+}
+
+func (o *openMathWriter) apply(e *semantic.Apply) error {
+	args := e.Args
+	var head symbol
+
+	switch e.Op {
+	case semantic.Function:
+		s, ok := openMathFunctions[e.Func]
+		if !ok {
+			return fmt.Errorf("openmath: unknown function %s", e.Func)
+		}
+		head = s
+		// transc1.log takes the base first: log(b, x)
+		if e.Func == "log" {
+			base := semantic.Expr(&semantic.Number{Value: "10"})
+			if len(args) == 2 {
+				base = args[1]
+			}
+			args = []semantic.Expr{base, args[0]}
+		}
+	case semantic.Root:
+		head = openMathOps[e.Op]
+		if len(args) == 1 {
+			args = []semantic.Expr{args[0], &semantic.Number{Value: "2"}}
+		}
+	case semantic.Index:
+		// vector_selector takes the index first
+		head = openMathOps[e.Op]
+		args = []semantic.Expr{args[1], args[0]}
+	default:
+		s, ok := openMathOps[e.Op]
+		if !ok {
+			return fmt.Errorf("openmath: %s has no OpenMath equivalent", e.Op)
+		}
+		head = s
+	}
+
+	o.open("OMA")
+	o.symbol(head)
+	for _, arg := range args {
+		if err := o.expr(arg); err != nil {
+			return err
+		}
+	}
+	o.close("OMA")
+	return nil
+}
+
+func (o *openMathWriter) bind(e *semantic.Bind) error {
+	if e.Var == nil {
+		return fmt.Errorf("openmath: %s without bound variable", e.Op)
+	}
+
+	var head symbol
+	var domain []semantic.Expr
+	var interval symbol
+
+	switch e.Op {
+	case semantic.Sum, semantic.Product:
+		if e.Lower == nil || e.Upper == nil {
+			return fmt.Errorf("openmath: %s needs lower and upper limits", e.Op)
+		}
+		head = symbol{"arith1", e.Op.String()}
+		interval = symbol{"interval1", "integer_interval"}
+		domain = []semantic.Expr{e.Lower, e.Upper}
+	case semantic.Integral:
+		switch {
+		case e.Lower == nil && e.Upper == nil:
+			head = symbol{"calculus1", "int"}
+		case e.Lower != nil && e.Upper != nil:
+			head = symbol{"calculus1", "defint"}
+			interval = symbol{"interval1", "interval"}
+			domain = []semantic.Expr{e.Lower, e.Upper}
+		default:
+			return fmt.Errorf("openmath: integral needs both limits or none")
+		}
+	case semantic.Limit:
+		head = symbol{"limit1", "limit"}
+	default:
+		return fmt.Errorf("openmath: %s has no OpenMath equivalent", e.Op)
+	}
+
+	o.open("OMA")
+	o.symbol(head)
+
+	if domain != nil {
+		o.open("OMA")
+		o.symbol(interval)
+		for _, d := range domain {
+			if err := o.expr(d); err != nil {
+				return err
+			}
+		}
+		o.close("OMA")
+	}
+	if e.Op == semantic.Limit {
+		if err := o.expr(e.Lower); err != nil {
+			return err
+		}
+		o.symbol(symbol{"limit1", "both_sides"})
+	}
+
+	o.open("OMBIND")
+	o.symbol(symbol{"fns1", "lambda"})
+	o.open("OMBVAR")
+	o.variable(e.Var)
+	o.close("OMBVAR")
+	if err := o.expr(e.Body); err != nil {
+		return err
+	}
+	o.close("OMBIND")
+
+	o.close("OMA")
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package mathml exports semantic expression trees as Content MathML and
+// OpenMath XML for interchange with computer algebra systems.
+package mathml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlWriter writes indented XML and remembers the first write error.
+type xmlWriter struct {
+	w     io.Writer
+	depth int
+	err   error
+}
+
+func (x *xmlWriter) line(format string, args ...any) {
+	if x.err != nil {
+		return
+	}
+	_, x.err = fmt.Fprintf(x.w, "%s%s\n", strings.Repeat("  ", x.depth), fmt.Sprintf(format, args...))
+}
+
+// open writes a start tag and increases the indentation.
+func (x *xmlWriter) open(tag string, attrs ...string) {
+	x.line("<%s%s>", tag, attributes(attrs))
+	x.depth++
+}
+
+// close decreases the indentation and writes an end tag.
+func (x *xmlWriter) close(tag string) {
+	x.depth--
+	x.line("</%s>", tag)
+}
+
+// empty writes a self-closing element.
+func (x *xmlWriter) empty(tag string, attrs ...string) {
+	x.line("<%s%s/>", tag, attributes(attrs))
+}
+
+// text writes an element with escaped character content.
+func (x *xmlWriter) text(tag, content string, attrs ...string) {
+	x.line("<%s%s>%s</%s>", tag, attributes(attrs), escape(content), tag)
+}
+
+// attributes formats name/value pairs as XML attributes.
+func attributes(pairs []string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fmt.Fprintf(&sb, " %s=\"%s\"", pairs[i], escape(pairs[i+1]))
+	}
+	return sb.String()
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
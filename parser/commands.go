@@ -1,16 +1,13 @@
 package parser
 
-import (
-	"fmt"
-
-	"github.com/neox5/texmax/ast"
-)
+import "github.com/neox5/texmax/ast"
 
 // parseCommand handles LaTeX commands (tokens that start with \)
 func (p *Parser) parseCommand() ast.Node {
 	token := p.next() // consume the COMMAND token
 	cmd := token.Value
 	pos := token.Pos
+	p.checkProfile(token)
 
 	// Check command type in order of likelihood/specificity
 	switch {
@@ -19,25 +16,35 @@ func (p *Parser) parseCommand() ast.Node {
 	case isOperator(cmd):
 		return p.parseOperator(cmd, pos)
 	case isGreekLetter(cmd):
-		return p.parseGreekLetter(cmd, pos)
+		return p.parseGreekLetter(token)
+	case isSymbolicOperator(cmd):
+		return p.parseSymbolicOperator(token)
+	case isSizedDelimiter(cmd):
+		return p.parseSizedDelimiter(token)
+	case isDelimiterCommand(cmd):
+		return &ast.DelimiterNode{Start: pos, Stop: token.End(), Kind: delimiterCommands[cmd]}
 	}
 
 	// Handle specific command types with arguments
 	switch cmd {
-	case "frac":
-		return p.parseFractionCommand(pos)
+	case "frac", "dfrac", "tfrac", "cfrac":
+		return p.parseFractionCommand(cmd, pos)
+	case "genfrac":
+		return p.parseGenfracCommand(pos)
 	case "sqrt":
 		return p.parseSqrtCommand(pos)
-	case "binom":
-		return p.parseBinomCommand(pos)
+	case "binom", "dbinom", "tbinom":
+		return p.parseBinomCommand(cmd, pos)
 	case "left":
 		return p.parseDelimitedExpression(pos)
-	case "right":
-		// Handle \right outside of a \left...\right context
-		p.addError("unexpected \\right without matching \\left", pos)
-		return nil
+	case "middle":
+		return p.parseMiddle(token)
+	case "right", "\\", "over", "choose", "atop", "above":
+		// \right outside of a \left...\right context, a line break
+		// outside of an environment, or a generalized fraction in place of
+		// an argument
+		return p.unexpected(token)
 	default:
-		p.addError(fmt.Sprintf("unsupported command: \\%s", cmd), pos)
-		return nil
+		return p.unsupportedCommand(token)
 	}
 }
@@ -10,27 +10,40 @@ func (p *Parser) parseDelimitedExpression(startPos int) ast.Node {
 	// Parse the left delimiter directly
 	leftDelimiter := p.parseDelimiter()
 	if leftDelimiter == nil {
-		p.addError("expected delimiter after \\left", p.peek().Pos)
-		return nil
+		p.missingDelimiter("left")
 	}
 
-	// Parse the content between delimiters
+	// Parse the content between delimiters, collecting its \middle
+	// separators
+	middle, middleDepth := p.middle, p.middleDepth
+	p.middle, p.middleDepth = nil, p.depth+1
 	content := p.parseExpression()
+	separators := p.middle
+	p.middle, p.middleDepth = middle, middleDepth
 
 	// Ensure we have a \right command
 	if p.peek().Type != tokenizer.COMMAND || p.peek().Value != "right" {
-		p.addError("expected \\right to close \\left", p.peek().Pos)
-		// Return just the content as we couldn't complete the delimited expression
-		return content
+		pos := p.peek().Pos
+		p.report(ParseError{
+			Code:    ErrMissingRight,
+			Message: "expected \\right to close \\left",
+			Pos:     pos,
+			End:     pos,
+			Related: []Related{{Message: "\\left opened here", Pos: startPos, End: startPos + len(`\left`)}},
+			Fixes:   []Fix{insert("insert \\right.", pos, `\right.`)},
+		})
+		// Keep what was parsed as we couldn't complete the delimited expression
+		return p.partial(startPos, "expected \\right to close \\left", leftDelimiter, content)
 	}
 	p.next() // consume \right
 
 	// Parse the right delimiter directly
 	rightDelimiter := p.parseDelimiter()
 	if rightDelimiter == nil {
-		p.addError("expected delimiter after \\right", p.peek().Pos)
-		// Return just the content as we couldn't complete the delimited expression
-		return content
+		p.missingDelimiter("right")
+	}
+	if leftDelimiter == nil || rightDelimiter == nil {
+		return p.partial(startPos, "missing delimiter", leftDelimiter, content, rightDelimiter)
 	}
 
 	// Only create a DelimitedExpressionNode if all parts were successfully parsed
@@ -39,5 +52,91 @@ func (p *Parser) parseDelimitedExpression(startPos int) ast.Node {
 		LeftDelimiter:  leftDelimiter,
 		Content:        content,
 		RightDelimiter: rightDelimiter,
+		Middle:         elementsOf(content, separators),
+	}
+}
+
+// elementsOf returns the separators that are elements of content. Others
+// ended up in a nested construct, such as the denominator of an \over.
+func elementsOf(content *ast.ExpressionNode, separators []*ast.DelimiterNode) []*ast.DelimiterNode {
+	var found []*ast.DelimiterNode
+	for _, s := range separators {
+		for _, el := range content.Elements {
+			if el == ast.Node(s) {
+				found = append(found, s)
+			}
+		}
+	}
+	return found
+}
+
+// parseMiddle parses a \middle separator of the \left...\right the parser
+// is in. It is returned as a delimiter that spans the command.
+func (p *Parser) parseMiddle(t tokenizer.Token) ast.Node {
+	d, _ := p.parseDelimiter().(*ast.DelimiterNode)
+	if d == nil {
+		p.missingDelimiter("middle")
+		return &ast.ErrorNode{Start: t.Pos, Stop: t.End(), Message: "expected delimiter after \\middle"}
+	}
+	separator := &ast.DelimiterNode{Start: t.Pos, Stop: d.End(), Kind: d.Kind}
+	if p.middleDepth == 0 || p.depth != p.middleDepth {
+		msg := "\\middle outside of \\left...\\right"
+		if p.middleDepth > 0 {
+			msg = "\\middle in a group within \\left...\\right"
+		}
+		p.addError(ErrUnexpectedToken, msg, t.Pos, separator.End())
+		return &ast.ErrorNode{Start: t.Pos, Stop: separator.End(), Message: msg, Elements: []ast.Node{d}}
+	}
+	p.middle = append(p.middle, separator)
+	return separator
+}
+
+// sizedDelimiter is the size and role of a sized delimiter command.
+type sizedDelimiter struct {
+	size int
+	role ast.DelimiterRole
+}
+
+// sizedDelimiters maps the sized delimiter commands such as \big and
+// \Biggl to their size and role.
+var sizedDelimiters = func() map[string]sizedDelimiter {
+	roles := map[string]ast.DelimiterRole{
+		"": ast.OrdinaryRole, "l": ast.OpenRole, "r": ast.CloseRole, "m": ast.RelationRole,
+	}
+	m := map[string]sizedDelimiter{}
+	for i, name := range []string{"big", "Big", "bigg", "Bigg"} {
+		for suffix, role := range roles {
+			m[name+suffix] = sizedDelimiter{size: i + 1, role: role}
+		}
+	}
+	return m
+}()
+
+func isSizedDelimiter(cmd string) bool {
+	_, ok := sizedDelimiters[cmd]
+	return ok
+}
+
+// parseSizedDelimiter parses a delimiter of fixed size such as \bigl(.
+func (p *Parser) parseSizedDelimiter(t tokenizer.Token) ast.Node {
+	d := p.parseDelimiter()
+	if d == nil {
+		p.missingDelimiter(t.Value)
+		return &ast.ErrorNode{Start: t.Pos, Stop: t.End(), Message: "expected delimiter after \\" + t.Value}
 	}
+	s := sizedDelimiters[t.Value]
+	return &ast.SizedDelimiterNode{Start: t.Pos, Size: s.size, Role: s.role, Delimiter: d}
+}
+
+// missingDelimiter reports a \left or \right without a delimiter and
+// suggests the empty delimiter.
+func (p *Parser) missingDelimiter(cmd string) {
+	pos := p.peek().Pos
+	p.report(ParseError{
+		Code:    ErrMissingDelimiter,
+		Message: "expected delimiter after \\" + cmd,
+		Pos:     pos,
+		End:     pos,
+		Fixes:   []Fix{insert("use the empty delimiter", pos, ".")},
+	})
 }
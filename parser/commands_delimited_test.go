@@ -0,0 +1,180 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+func TestSizedDelimiters(t *testing.T) {
+	tests := []struct {
+		input string
+		size  int
+		role  ast.DelimiterRole
+		kind  ast.Delimiter
+	}{
+		{`\big(`, 1, ast.OrdinaryRole, ast.LeftParen},
+		{`\Bigl[`, 2, ast.OpenRole, ast.LeftBracket},
+		{`\biggr\}`, 3, ast.CloseRole, ast.RightBrace},
+		{`\Biggm|`, 4, ast.RelationRole, ast.Vert},
+		{`\bigl\langle`, 1, ast.OpenRole, ast.LeftAngle},
+		{`\Bigr.`, 2, ast.CloseRole, ast.NoDelimiter},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.Parse(tt.input)
+		if len(errs) != 0 {
+			t.Fatalf("%s: unexpected errors %v", tt.input, errs)
+		}
+		n, ok := root.(*ast.ExpressionNode).Elements[0].(*ast.SizedDelimiterNode)
+		if !ok {
+			t.Fatalf("%s: got %#v, want a sized delimiter", tt.input, root.(*ast.ExpressionNode).Elements[0])
+		}
+		if d := n.Delimiter.(*ast.DelimiterNode); n.Size != tt.size || n.Role != tt.role || d.Kind != tt.kind {
+			t.Errorf("%s: got size %d, role %s, %s, want %d, %s, %s", tt.input, n.Size, n.Role, d.Kind, tt.size, tt.role, tt.kind)
+		}
+		if n.Pos() != 0 || n.End() != len(tt.input) {
+			t.Errorf("%s: spans %d-%d, want 0-%d", tt.input, n.Pos(), n.End(), len(tt.input))
+		}
+	}
+}
+
+func TestMiddle(t *testing.T) {
+	input := `\left\{ x \middle| x > 0 \middle\| y \right\}`
+	root, errs := parser.Parse(input)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	d, ok := root.(*ast.ExpressionNode).Elements[0].(*ast.DelimitedExpressionNode)
+	if !ok {
+		t.Fatalf("got %#v, want a delimited expression", root.(*ast.ExpressionNode).Elements[0])
+	}
+	if len(d.Middle) != 2 {
+		t.Fatalf("got %d separators, want 2", len(d.Middle))
+	}
+	if m := d.Middle[0]; m.Kind != ast.Vert || m.Pos() != 10 || m.End() != 18 {
+		t.Errorf("first separator %s at %d-%d, want | at 10-18", m.Kind, m.Pos(), m.End())
+	}
+	if m := d.Middle[1]; m.Kind != ast.DoubleVert {
+		t.Errorf("second separator %s, want \\|", m.Kind)
+	}
+	elements := d.Content.(*ast.ExpressionNode).Elements
+	if elements[1] != ast.Node(d.Middle[0]) {
+		t.Errorf("separator is not an element of the content: %#v", elements)
+	}
+
+	// Separators belong to the innermost \left...\right
+	root, _ = parser.Parse(`\left( \left[ a \middle| b \right] \right)`)
+	outer := root.(*ast.ExpressionNode).Elements[0].(*ast.DelimitedExpressionNode)
+	inner := outer.Content.(*ast.ExpressionNode).Elements[0].(*ast.DelimitedExpressionNode)
+	if len(outer.Middle) != 0 || len(inner.Middle) != 1 {
+		t.Errorf("got %d outer and %d inner separators, want 0 and 1", len(outer.Middle), len(inner.Middle))
+	}
+}
+
+func TestMisplacedMiddle(t *testing.T) {
+	for _, input := range []string{`a \middle| b`, `\left( {a \middle| b} \right)`} {
+		root, errs := parser.Parse(input)
+		if len(errs) != 1 || errs[0].Code != parser.ErrUnexpectedToken {
+			t.Errorf("%s: errors = %v, want one unexpected token", input, errs)
+		}
+		checkSpans(t, root)
+	}
+}
+
+func TestDelimiterKinds(t *testing.T) {
+	tests := []struct {
+		left, right string
+		kinds       [2]ast.Delimiter
+		glyphs      string
+	}{
+		{`(`, `)`, [2]ast.Delimiter{ast.LeftParen, ast.RightParen}, "()"},
+		{`\lbrack`, `\rbrack`, [2]ast.Delimiter{ast.LeftBracket, ast.RightBracket}, "[]"},
+		{`\lbrace`, `\rbrace`, [2]ast.Delimiter{ast.LeftBrace, ast.RightBrace}, "{}"},
+		{`<`, `>`, [2]ast.Delimiter{ast.LeftAngle, ast.RightAngle}, "⟨⟩"},
+		{`\lgroup`, `\rgroup`, [2]ast.Delimiter{ast.LeftGroup, ast.RightGroup}, "⟮⟯"},
+		{`\lmoustache`, `\rmoustache`, [2]ast.Delimiter{ast.LeftMoustache, ast.RightMoustache}, "⎰⎱"},
+		{`\ulcorner`, `\lrcorner`, [2]ast.Delimiter{ast.UpperLeftCorner, ast.LowerRightCorner}, "⌜⌟"},
+		{`\lvert`, `\rvert`, [2]ast.Delimiter{ast.LeftVert, ast.RightVert}, "||"},
+		{`\Vert`, `\|`, [2]ast.Delimiter{ast.DoubleVert, ast.DoubleVert}, "‖‖"},
+		{`/`, `\backslash`, [2]ast.Delimiter{ast.Slash, ast.Backslash}, "/\\"},
+		{`\uparrow`, `\Downarrow`, [2]ast.Delimiter{ast.UpArrow, ast.DoubleDownArrow}, "↑⇓"},
+		{`.`, `\Updownarrow`, [2]ast.Delimiter{ast.NoDelimiter, ast.DoubleUpDownArrow}, "⇕"},
+	}
+
+	for _, tt := range tests {
+		input := `\left` + tt.left + ` x \right` + tt.right
+		root, errs := parser.Parse(input)
+		if len(errs) != 0 {
+			t.Fatalf("%s: unexpected errors %v", input, errs)
+		}
+		checkSpans(t, root)
+		d := root.(*ast.ExpressionNode).Elements[0].(*ast.DelimitedExpressionNode)
+		left, right := d.LeftDelimiter.(*ast.DelimiterNode), d.RightDelimiter.(*ast.DelimiterNode)
+		if left.Kind != tt.kinds[0] || right.Kind != tt.kinds[1] {
+			t.Errorf("%s: got %s and %s, want %s and %s", input, left.Kind, right.Kind, tt.kinds[0], tt.kinds[1])
+		}
+		if glyphs := left.Kind.Glyph() + right.Kind.Glyph(); glyphs != tt.glyphs {
+			t.Errorf("%s: glyphs %q, want %q", input, glyphs, tt.glyphs)
+		}
+		if left.End() != len(`\left`)+len(tt.left) {
+			t.Errorf("%s: left delimiter ends at %d, want %d", input, left.End(), len(`\left`)+len(tt.left))
+		}
+	}
+}
+
+func TestDelimiterClasses(t *testing.T) {
+	tests := []struct {
+		kind   ast.Delimiter
+		class  ast.DelimiterClass
+		mirror ast.Delimiter
+	}{
+		{ast.LeftParen, ast.OpenDelimiter, ast.RightParen},
+		{ast.RightAngle, ast.CloseDelimiter, ast.LeftAngle},
+		{ast.LowerLeftCorner, ast.OpenDelimiter, ast.LowerRightCorner},
+		{ast.LeftDoubleVert, ast.OpenDelimiter, ast.RightDoubleVert},
+		{ast.Vert, ast.FenceDelimiter, ast.Vert},
+		{ast.UpDownArrow, ast.FenceDelimiter, ast.UpDownArrow},
+		{ast.NoDelimiter, ast.FenceDelimiter, ast.NoDelimiter},
+	}
+
+	for _, tt := range tests {
+		if c := tt.kind.Class(); c != tt.class {
+			t.Errorf("%s: class %s, want %s", tt.kind, c, tt.class)
+		}
+		if m := tt.kind.Mirror(); m != tt.mirror {
+			t.Errorf("%s: mirror %s, want %s", tt.kind, m, tt.mirror)
+		}
+	}
+}
+
+func TestStandaloneDelimiters(t *testing.T) {
+	tests := []struct {
+		input string
+		kinds []ast.Delimiter
+	}{
+		{`\lbrace x \rbrace`, []ast.Delimiter{ast.LeftBrace, ast.RightBrace}},
+		{`\|x\|`, []ast.Delimiter{ast.DoubleVert, ast.DoubleVert}},
+		{`\langle a \rangle`, []ast.Delimiter{ast.LeftAngle, ast.RightAngle}},
+		{`\lfloor x \rfloor + \uparrow`, []ast.Delimiter{ast.LeftFloor, ast.RightFloor, ast.UpArrow}},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.Parse(tt.input)
+		if len(errs) != 0 {
+			t.Fatalf("%s: unexpected errors %v", tt.input, errs)
+		}
+		checkSpans(t, root)
+		var kinds []ast.Delimiter
+		for _, e := range root.(*ast.ExpressionNode).Elements {
+			if d, ok := e.(*ast.DelimiterNode); ok {
+				kinds = append(kinds, d.Kind)
+			}
+		}
+		if !reflect.DeepEqual(kinds, tt.kinds) {
+			t.Errorf("%s: got delimiters %v, want %v", tt.input, kinds, tt.kinds)
+		}
+	}
+}
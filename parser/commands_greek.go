@@ -1,6 +1,9 @@
 package parser
 
-import "github.com/neox5/texmax/ast"
+import (
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
 
 // List of Greek letter commands in LaTeX
 var greekLetters = map[string]string{
@@ -64,11 +67,12 @@ func isGreekLetter(name string) bool {
 }
 
 // parseGreek parses a Greek letter command and returns a SymbolNode
-func (p *Parser) parseGreekLetter(commandName string, startPos int) ast.Node {
+func (p *Parser) parseGreekLetter(t tokenizer.Token) ast.Node {
 	// Look up the Unicode representation of the Greek letter
-	if symbol, ok := greekLetters[commandName]; ok {
+	if symbol, ok := greekLetters[t.Value]; ok {
 		return &ast.SymbolNode{
-			Start: startPos,
+			Start: t.Pos,
+			Stop:  t.End(),
 			Value: symbol,
 		}
 	}
@@ -76,3 +80,26 @@ func (p *Parser) parseGreekLetter(commandName string, startPos int) ast.Node {
 	// This shouldn't happen if IsGreekLetter is checked first
 	return nil
 }
+
+// greekNames maps the Unicode Greek letters back to their command names.
+var greekNames = func() map[string]string {
+	names := make(map[string]string, len(greekLetters))
+	for name, symbol := range greekLetters {
+		names[symbol] = name
+	}
+	return names
+}()
+
+// GreekLetterName returns the LaTeX command name of a Greek letter symbol
+// as stored in ast.SymbolNode, e.g. "alpha" for "α".
+func GreekLetterName(symbol string) (string, bool) {
+	name, ok := greekNames[symbol]
+	return name, ok
+}
+
+// GreekLetter returns the Unicode symbol of a Greek letter command name,
+// e.g. "α" for "alpha".
+func GreekLetter(name string) (string, bool) {
+	symbol, ok := greekLetters[name]
+	return symbol, ok
+}
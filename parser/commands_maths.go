@@ -62,8 +62,10 @@ func (p *Parser) parseOperator(operatorName string, startPos int) ast.Node {
 
 	// For \lim, we should validate that it only has a lower limit
 	if operatorName == "lim" && upperLimit != nil {
-		p.addError("\\lim can only have a lower limit", upperLimit.Pos())
-		upperLimit = nil // Ignore upper limit for \lim
+		msg := "\\lim can only have a lower limit"
+		p.addError(ErrInvalidLimit, msg, upperLimit.Pos(), upperLimit.End())
+		// Keep the upper limit, marked as an error
+		upperLimit = &ast.ErrorNode{Start: upperLimit.Pos(), Stop: upperLimit.End(), Message: msg, Elements: []ast.Node{upperLimit}}
 	}
 
 	return &ast.LimitedOperatorNode{
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+// symbolicOperators maps LaTeX binary operator and relation commands
+// to their Unicode representation.
+var symbolicOperators = map[string]string{
+	// Binary operators
+	"cdot":     "·",
+	"times":    "×",
+	"div":      "÷",
+	"pm":       "±",
+	"mp":       "∓",
+	"ast":      "∗",
+	"circ":     "∘",
+	"cup":      "∪",
+	"cap":      "∩",
+	"setminus": "∖",
+	"wedge":    "∧",
+	"vee":      "∨",
+
+	// Relations
+	"leq":    "≤",
+	"le":     "≤",
+	"geq":    "≥",
+	"ge":     "≥",
+	"neq":    "≠",
+	"ne":     "≠",
+	"approx": "≈",
+	"equiv":  "≡",
+	"sim":    "∼",
+	"simeq":  "≃",
+	"cong":   "≅",
+	"propto": "∝",
+	"ll":     "≪",
+	"gg":     "≫",
+	"in":     "∈",
+	"notin":  "∉",
+	"subset": "⊂",
+	"supset": "⊃",
+
+	// Arrows
+	"to":             "→",
+	"rightarrow":     "→",
+	"leftarrow":      "←",
+	"Rightarrow":     "⇒",
+	"Leftarrow":      "⇐",
+	"Leftrightarrow": "⇔",
+	"mapsto":         "↦",
+}
+
+// isSymbolicOperator checks if a command is a binary operator or relation.
+func isSymbolicOperator(name string) bool {
+	_, ok := symbolicOperators[name]
+	return ok
+}
+
+// parseSymbolicOperator creates an OperatorNode for commands like \leq,
+// \cdot. The node spans the command, not its Unicode representation.
+func (p *Parser) parseSymbolicOperator(t tokenizer.Token) ast.Node {
+	return &ast.OperatorNode{
+		Start: t.Pos,
+		Stop:  t.End(),
+		Value: symbolicOperators[t.Value],
+	}
+}
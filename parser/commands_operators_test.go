@@ -0,0 +1,37 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+func TestSymbolicOperatorSpans(t *testing.T) {
+	tests := []struct {
+		input      string
+		value      string
+		start, end int
+	}{
+		{`a \leq b`, "≤", 2, 6},
+		{`a \cdot b`, "·", 2, 7},
+		{`x \to 0`, "→", 2, 5},
+		{`a+b`, "+", 1, 2},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.Parse(tt.input)
+		if len(errs) != 0 {
+			t.Fatalf("%s: unexpected errors %v", tt.input, errs)
+		}
+		var op *ast.OperatorNode
+		for _, e := range root.(*ast.ExpressionNode).Elements {
+			if o, ok := e.(*ast.OperatorNode); ok {
+				op = o
+			}
+		}
+		if op == nil || op.Value != tt.value || op.Pos() != tt.start || op.End() != tt.end {
+			t.Errorf("%s: got %#v, want %s at %d-%d", tt.input, op, tt.value, tt.start, tt.end)
+		}
+	}
+}
@@ -1,28 +1,191 @@
 package parser
 
-import "github.com/neox5/texmax/ast"
+import (
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+// styles maps the fraction and binomial commands to their math style.
+var styles = map[string]ast.Style{
+	"dfrac":  ast.DisplayStyle,
+	"tfrac":  ast.TextStyle,
+	"cfrac":  ast.ContinuedStyle,
+	"dbinom": ast.DisplayStyle,
+	"tbinom": ast.TextStyle,
+}
+
+// parseFractionCommand parses a LaTeX fraction command such as
+// \frac{numerator}{denominator}, \dfrac, \tfrac or \cfrac[l].
+func (p *Parser) parseFractionCommand(cmd string, startPos int) ast.Node {
+	align := ""
+	if cmd == "cfrac" {
+		align = p.parseAlignment()
+	}
 
-// parseFractionCommand parses a LaTeX fraction command: \frac{numerator}{denominator}
-func (p *Parser) parseFractionCommand(startPos int) ast.Node {
 	numerator := p.parseGroupedStrict()
 	if numerator == nil {
-		p.addError("expected numerator after \\frac", startPos)
-		return nil
+		return p.missingArgument(startPos, "expected numerator after \\"+cmd, "\\"+cmd)
 	}
 
 	denominator := p.parseGroupedStrict()
 	if denominator == nil {
-		p.addError("expected denominator after \\frac{...}", startPos)
-		return nil
+		return p.missingArgument(startPos, "expected denominator after \\"+cmd+"{...}", "\\"+cmd, numerator)
 	}
 
 	return &ast.FractionNode{
 		Start:       startPos,
 		Numerator:   numerator,
 		Denominator: denominator,
+		Style:       styles[cmd],
+		Align:       align,
 	}
 }
 
+// parseAlignment parses the optional alignment of \cfrac: [l], [c] or [r].
+// The centered alignment is returned as "".
+func (p *Parser) parseAlignment() string {
+	arg := p.parseOptionalArgument()
+	if arg == nil {
+		return ""
+	}
+	expr := arg.(*ast.ExpressionNode)
+	if len(expr.Elements) == 1 {
+		if sym, ok := expr.Elements[0].(*ast.SymbolNode); ok {
+			switch sym.Value {
+			case "l", "r":
+				return sym.Value
+			case "c":
+				return ""
+			}
+		}
+	}
+	p.addError(ErrInvalidArgument, "expected l, c or r as alignment of \\cfrac", expr.Pos(), expr.End())
+	return ""
+}
+
+// parseGenfracCommand parses the generalized fraction of amsmath:
+// \genfrac{left}{right}{thickness}{style}{numerator}{denominator}. Empty
+// delimiters, thickness and style arguments select the defaults. A
+// fraction with delimiters is returned in an ast.DelimitedExpressionNode,
+// except for the binomial coefficient, which is a bar-less fraction in
+// parentheses.
+func (p *Parser) parseGenfracCommand(startPos int) ast.Node {
+	left := p.parseDelimiterArgument()
+	if left == nil {
+		return p.missingArgument(startPos, "expected left delimiter after \\genfrac", `\genfrac`)
+	}
+	right := p.parseDelimiterArgument()
+	if right == nil {
+		return p.missingArgument(startPos, "expected right delimiter after \\genfrac{...}", `\genfrac`, left)
+	}
+
+	var thickness string
+	ok := p.parseSpecialArgument("a dimension such as 1pt", func() bool {
+		var valid bool
+		thickness, valid = p.parseDimension()
+		return valid
+	})
+	if !ok {
+		return p.missingArgument(startPos, "expected thickness after \\genfrac{...}{...}", `\genfrac`, left, right)
+	}
+
+	var style ast.Style
+	ok = p.parseSpecialArgument("a style 0, 1, 2 or 3", func() bool {
+		t := p.peek()
+		if t.Type != tokenizer.NUMBER || len(t.Value) != 1 || t.Value[0] > '3' {
+			return false
+		}
+		p.next()
+		style = ast.DisplayStyle + ast.Style(t.Value[0]-'0')
+		return true
+	})
+	if !ok {
+		return p.missingArgument(startPos, "expected style after \\genfrac{...}{...}{...}", `\genfrac`, left, right)
+	}
+
+	numerator := p.parseGroupedStrict()
+	if numerator == nil {
+		return p.missingArgument(startPos, "expected numerator after \\genfrac{...}{...}{...}{...}", `\genfrac`, left, right)
+	}
+	denominator := p.parseGroupedStrict()
+	if denominator == nil {
+		return p.missingArgument(startPos, "expected denominator after \\genfrac{...}{...}{...}{...}{...}", `\genfrac`, left, right, numerator)
+	}
+
+	fraction := &ast.FractionNode{
+		Start:       startPos,
+		Numerator:   numerator,
+		Denominator: denominator,
+		Thickness:   thickness,
+		Style:       style,
+	}
+	l, r := left.(*ast.DelimiterNode).Kind, right.(*ast.DelimiterNode).Kind
+	switch {
+	case l == ast.NoDelimiter && r == ast.NoDelimiter:
+		return fraction
+	case l == ast.LeftParen && r == ast.RightParen && !fraction.Ruled():
+		return &ast.BinomNode{Start: startPos, Upper: numerator, Lower: denominator, Style: style}
+	}
+	return &ast.DelimitedExpressionNode{
+		Start:          startPos,
+		Stop:           denominator.End(),
+		LeftDelimiter:  left,
+		Content:        fraction,
+		RightDelimiter: right,
+	}
+}
+
+// parseDelimiterArgument parses a delimiter argument of \genfrac: a
+// delimiter, possibly in braces, or empty braces for no delimiter, which
+// is returned as ast.NoDelimiter. It returns nil if the argument is missing.
+func (p *Parser) parseDelimiterArgument() ast.Node {
+	start := p.peek().Pos
+	if p.peek().Type != tokenizer.LBRACE {
+		if d := p.parseDelimiter(); d != nil {
+			return d
+		}
+		p.expectedBrace()
+		return nil
+	}
+
+	kind := ast.NoDelimiter
+	p.parseSpecialArgument("a delimiter", func() bool {
+		d, ok := p.parseDelimiter().(*ast.DelimiterNode)
+		if ok {
+			kind = d.Kind
+		}
+		return ok
+	})
+	return &ast.DelimiterNode{Start: start, Stop: p.lastEnd(), Kind: kind}
+}
+
+// parseSpecialArgument parses a braced argument that is not math, such as
+// the thickness of \genfrac. The content of a non-empty argument is read
+// by parse, which returns false if it is not of the expected form; then it
+// is reported and skipped. It returns false if the argument is missing.
+func (p *Parser) parseSpecialArgument(expected string, parse func() bool) bool {
+	if p.peek().Type != tokenizer.LBRACE {
+		p.expectedBrace()
+		return false
+	}
+	open := p.next() // consume '{'
+
+	start := p.peek().Pos
+	if !p.atClosing() && (!parse() || p.peek().Type != tokenizer.RBRACE) {
+		for level := 0; p.peek().Type != tokenizer.EOF && (level > 0 || !p.atClosing()); {
+			switch p.next().Type {
+			case tokenizer.LBRACE:
+				level++
+			case tokenizer.RBRACE:
+				level--
+			}
+		}
+		p.addError(ErrInvalidArgument, "expected "+expected, start, max(start, p.lastEnd()))
+	}
+	p.closeGroup(open)
+	return true
+}
+
 // parseSqrtCommand parses a LaTeX square root command: \sqrt[n]{x} or \sqrt{x}
 func (p *Parser) parseSqrtCommand(startPos int) ast.Node {
 	// Parse optional index if present (e.g., \sqrt[3]{x} for cube root)
@@ -31,8 +194,7 @@ func (p *Parser) parseSqrtCommand(startPos int) ast.Node {
 	// Parse the radicand (expression under the root)
 	radicand := p.parseGroupedOrSingle()
 	if radicand == nil {
-		p.addError("expected radicand after \\sqrt", startPos)
-		return nil
+		return p.missingArgument(startPos, "expected radicand after \\sqrt", `\sqrt`, index)
 	}
 
 	return &ast.SqrtNode{
@@ -42,23 +204,30 @@ func (p *Parser) parseSqrtCommand(startPos int) ast.Node {
 	}
 }
 
-// parseBinomCommand parses a LaTeX binomial coefficient: \binom{n}{k}
-func (p *Parser) parseBinomCommand(startPos int) ast.Node {
+// parseBinomCommand parses a LaTeX binomial coefficient such as
+// \binom{n}{k}, \dbinom or \tbinom.
+func (p *Parser) parseBinomCommand(cmd string, startPos int) ast.Node {
 	upper := p.parseGroupedStrict()
 	if upper == nil {
-		p.addError("expected upper value after \\binom", startPos)
-		return nil
+		return p.missingArgument(startPos, "expected upper value after \\"+cmd, "\\"+cmd)
 	}
 
 	lower := p.parseGroupedStrict()
 	if lower == nil {
-		p.addError("expected lower value after \\binom{...}", startPos)
-		return nil
+		return p.missingArgument(startPos, "expected lower value after \\"+cmd+"{...}", "\\"+cmd, upper)
 	}
 
 	return &ast.BinomNode{
 		Start: startPos,
 		Upper: upper,
 		Lower: lower,
+		Style: styles[cmd],
 	}
 }
+
+// missingArgument reports a command at startPos that lacks an argument and
+// returns an error node keeping the arguments parsed before.
+func (p *Parser) missingArgument(startPos int, msg, cmd string, parsed ...ast.Node) ast.Node {
+	p.addError(ErrMissingArgument, msg, startPos, startPos+len(cmd))
+	return p.partial(startPos, msg, parsed...)
+}
@@ -0,0 +1,119 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+func TestFractionVariants(t *testing.T) {
+	tests := []struct {
+		input     string
+		style     ast.Style
+		align     string
+		thickness string
+	}{
+		{`\frac{a}{b}`, ast.InheritStyle, "", ""},
+		{`\dfrac{a}{b}`, ast.DisplayStyle, "", ""},
+		{`\tfrac{a}{b}`, ast.TextStyle, "", ""},
+		{`\cfrac{a}{b}`, ast.ContinuedStyle, "", ""},
+		{`\cfrac[l]{a}{b}`, ast.ContinuedStyle, "l", ""},
+		{`\cfrac[r]{a}{b}`, ast.ContinuedStyle, "r", ""},
+		{`\cfrac[c]{a}{b}`, ast.ContinuedStyle, "", ""},
+		{`\genfrac{}{}{}{}{a}{b}`, ast.InheritStyle, "", ""},
+		{`\genfrac{}{}{2pt}{0}{a}{b}`, ast.DisplayStyle, "", "2pt"},
+		{`\genfrac..{0pt}{3}{a}{b}`, ast.ScriptScriptStyle, "", "0pt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			root, errs := parser.Parse(tt.input)
+			if len(errs) != 0 {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+			frac, ok := root.(*ast.ExpressionNode).Elements[0].(*ast.FractionNode)
+			if !ok {
+				t.Fatalf("got %#v, want a fraction", root.(*ast.ExpressionNode).Elements[0])
+			}
+			if frac.Style != tt.style || frac.Align != tt.align || frac.Thickness != tt.thickness {
+				t.Errorf("got style %q, align %q, thickness %q, want %q, %q, %q",
+					frac.Style, frac.Align, frac.Thickness, tt.style, tt.align, tt.thickness)
+			}
+			if frac.End() != len(tt.input) {
+				t.Errorf("fraction ends at %d, want %d", frac.End(), len(tt.input))
+			}
+		})
+	}
+}
+
+func TestBinomVariants(t *testing.T) {
+	tests := []struct {
+		input string
+		style ast.Style
+	}{
+		{`\binom{n}{k}`, ast.InheritStyle},
+		{`\dbinom{n}{k}`, ast.DisplayStyle},
+		{`\tbinom{n}{k}`, ast.TextStyle},
+		{`\genfrac(){0pt}{1}{n}{k}`, ast.TextStyle},
+		{`\genfrac{(}{)}{0pt}{}{n}{k}`, ast.InheritStyle},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.Parse(tt.input)
+		if len(errs) != 0 {
+			t.Fatalf("%s: unexpected errors %v", tt.input, errs)
+		}
+		binom, ok := root.(*ast.ExpressionNode).Elements[0].(*ast.BinomNode)
+		if !ok || binom.Style != tt.style {
+			t.Errorf("%s: got %#v, want a binomial of style %q", tt.input, root.(*ast.ExpressionNode).Elements[0], tt.style)
+		}
+	}
+}
+
+func TestGenfracDelimiters(t *testing.T) {
+	input := `\genfrac[\rangle{}{}{a}{b}`
+	root, errs := parser.Parse(input)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	checkSpans(t, root)
+	d, ok := root.(*ast.ExpressionNode).Elements[0].(*ast.DelimitedExpressionNode)
+	if !ok {
+		t.Fatalf("got %#v, want a delimited expression", root.(*ast.ExpressionNode).Elements[0])
+	}
+	left, right := d.LeftDelimiter.(*ast.DelimiterNode), d.RightDelimiter.(*ast.DelimiterNode)
+	if left.Kind != ast.LeftBracket || right.Kind != ast.RightAngle {
+		t.Errorf("delimiters %s and %s, want [ and \\rangle", left.Kind, right.Kind)
+	}
+	if _, ok := d.Content.(*ast.FractionNode); !ok || d.End() != len(input) {
+		t.Errorf("got content %#v ending at %d, want a fraction ending at %d", d.Content, d.End(), len(input))
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		pos, end int
+	}{
+		{`\cfrac[x]{a}{b}`, 6, 9},
+		{`\genfrac{x}{}{}{}{a}{b}`, 9, 10},
+		{`\genfrac{}{}{1}{}{a}{b}`, 13, 14},
+		{`\genfrac{}{}{}{4}{a}{b}`, 15, 16},
+		{`\genfrac{}{}{1pt x}{}{a}{b}`, 13, 18},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.Parse(tt.input)
+		if len(errs) != 1 || errs[0].Code != parser.ErrInvalidArgument {
+			t.Errorf("%s: errors = %v, want one invalid argument", tt.input, errs)
+			continue
+		}
+		if errs[0].Pos != tt.pos || errs[0].End != tt.end {
+			t.Errorf("%s: error at %d-%d, want %d-%d", tt.input, errs[0].Pos, errs[0].End, tt.pos, tt.end)
+		}
+		if _, ok := root.(*ast.ExpressionNode).Elements[0].(*ast.FractionNode); !ok {
+			t.Errorf("%s: got %#v, want a fraction", tt.input, root.(*ast.ExpressionNode).Elements[0])
+		}
+	}
+}
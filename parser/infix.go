@@ -1,15 +1,31 @@
 package parser
 
-import "github.com/neox5/texmax/ast"
+import (
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
 
 func (p *Parser) parseSuperscript(left ast.Node) ast.Node {
-	p.next() // consume '^'
+	t := p.next() // consume '^'
 	exp := p.parseGroupedOrSingle()
-	return &ast.SuperscriptNode{Base: left, Exponent: exp}
+	if exp == nil {
+		return p.missingScript(left, t)
+	}
+	return &ast.SuperscriptNode{Start: left.Pos(), Base: left, Exponent: exp}
 }
 
 func (p *Parser) parseSubscript(left ast.Node) ast.Node {
-	p.next() // consume '_'
+	t := p.next() // consume '_'
 	idx := p.parseGroupedOrSingle()
-	return &ast.SubscriptNode{Base: left, Subscript: idx }
+	if idx == nil {
+		return p.missingScript(left, t)
+	}
+	return &ast.SubscriptNode{Start: left.Pos(), Base: left, Subscript: idx}
+}
+
+// missingScript returns an error node for the base left followed by the
+// script token t without an argument.
+func (p *Parser) missingScript(left ast.Node, t tokenizer.Token) ast.Node {
+	msg := p.missingAfter("'" + t.Value + "'")
+	return p.partial(left.Pos(), msg, left)
 }
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+// infixFractions are the TeX generalized fraction commands. Each divides
+// the expression it appears in, up to the enclosing braces, \left...\right
+// or the whole formula, into numerator and denominator.
+var infixFractions = map[string]bool{
+	"over":   true, // a fraction
+	"choose": true, // a binomial coefficient
+	"atop":   true, // a fraction without bar
+	"above":  true, // a fraction with a bar of the thickness given after it
+}
+
+// units are the units of a TeX dimension.
+var units = map[string]bool{
+	"pt": true, "pc": true, "in": true, "bp": true, "cm": true, "mm": true,
+	"dd": true, "cc": true, "sp": true, "em": true, "ex": true,
+}
+
+// infixFraction is a generalized fraction whose denominator is still
+// being parsed.
+type infixFraction struct {
+	cmd       tokenizer.Token
+	numerator *ast.ExpressionNode
+	thickness string
+	end       int // position after the command and its dimension
+}
+
+// parseInfixFraction parses a generalized fraction command, which takes
+// the expression parsed so far as its numerator.
+func (p *Parser) parseInfixFraction(numerator *ast.ExpressionNode) *infixFraction {
+	t := p.next()
+	p.checkProfile(t)
+	p.deprecated(t)
+	if len(numerator.Elements) == 0 {
+		numerator.Start = t.Pos
+	}
+
+	f := &infixFraction{cmd: t, numerator: numerator, end: t.End()}
+	switch t.Value {
+	case "atop":
+		f.thickness = "0pt"
+	case "above":
+		if dim, ok := p.parseDimension(); ok {
+			f.thickness = dim
+			f.end = p.lastEnd()
+		} else {
+			p.report(ParseError{
+				Code:    ErrMissingArgument,
+				Message: "expected a dimension such as 1pt after \\above",
+				Pos:     t.Pos,
+				End:     t.End(),
+				Fixes:   []Fix{insert("insert a thickness of 1pt", t.End(), " 1pt")},
+			})
+		}
+	}
+	return f
+}
+
+// node returns the fraction with the given denominator elements.
+func (f *infixFraction) node(denominator []ast.Node) ast.Node {
+	den := &ast.ExpressionNode{Start: f.end, Elements: denominator}
+	if f.cmd.Value == "choose" {
+		return &ast.BinomNode{Start: f.numerator.Start, Upper: f.numerator, Lower: den}
+	}
+	return &ast.FractionNode{
+		Start:       f.numerator.Start,
+		Numerator:   f.numerator,
+		Denominator: den,
+		Thickness:   f.thickness,
+	}
+}
+
+// ambiguousFraction reports a second generalized fraction command in an
+// expression and returns it as an error node. TeX ignores it, so the
+// denominator of the first one continues after it.
+func (p *Parser) ambiguousFraction(first *infixFraction) ast.Node {
+	t := p.next()
+	if t.Value == "above" {
+		p.parseDimension()
+	}
+	msg := "ambiguous \\" + t.Value + " in the same group as \\" + first.cmd.Value + ", add braces"
+	p.report(ParseError{
+		Code:    ErrAmbiguousFraction,
+		Message: msg,
+		Pos:     t.Pos,
+		End:     p.lastEnd(),
+		Related: []Related{{Message: "first fraction here", Pos: first.cmd.Pos, End: first.cmd.End()}},
+	})
+	return &ast.ErrorNode{Start: t.Pos, Stop: p.lastEnd(), Message: msg}
+}
+
+// parseDimension parses a dimension such as 1pt, -0.5em or .2ex and
+// returns it as written. If there is none, it consumes nothing.
+func (p *Parser) parseDimension() (string, bool) {
+	p.peek() // skip spaces
+	i, dim, digits := p.pos, "", false
+	for ; i < len(p.tokens); i++ {
+		t := p.tokens[i]
+		if i > p.pos && t.Pos != p.tokens[i-1].End() {
+			break
+		}
+		sign := i == p.pos && t.Type == tokenizer.OPERATOR && (t.Value == "-" || t.Value == "+")
+		if !sign && t.Type != tokenizer.NUMBER && t.Type != tokenizer.PERIOD {
+			break
+		}
+		digits = digits || t.Type == tokenizer.NUMBER
+		dim += t.Value
+	}
+
+	// The unit follows as two letters
+	if !digits || i+1 >= len(p.tokens) {
+		return "", false
+	}
+	a, b := p.tokens[i], p.tokens[i+1]
+	if a.Type != tokenizer.SYMBOL || b.Type != tokenizer.SYMBOL || b.Pos != a.End() || !units[a.Value+b.Value] {
+		return "", false
+	}
+	p.pos = i + 2
+	return dim + a.Value + b.Value, true
+}
@@ -0,0 +1,118 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+// symbols returns the values of the symbols and numbers below n, in order.
+func symbols(n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.SymbolNode:
+		return n.Value
+	case *ast.NumberNode:
+		return n.Value
+	}
+	s := ""
+	for _, c := range children(n) {
+		s += symbols(c)
+	}
+	return s
+}
+
+func TestInfixFractions(t *testing.T) {
+	tests := []struct {
+		input     string
+		binom     bool
+		num, den  string
+		thickness string
+	}{
+		{`{a+1 \over b}`, false, "a1", "b", ""},
+		{`{n \choose k}`, true, "n", "k", ""},
+		{`{a \atop b}`, false, "a", "b", "0pt"},
+		{`{a \above 1.5pt b}`, false, "a", "b", "1.5pt"},
+		{`{a \above -.2em b}`, false, "a", "b", "-.2em"},
+		{`x \over y`, false, "x", "y", ""},
+		{`\left( a \over b \right)`, false, "a", "b", ""},
+		{`{\over b}`, false, "", "b", ""},
+		{`{a \over}`, false, "a", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			root, errs := parser.Parse(tt.input)
+			if len(errs) != 0 {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+			checkSpans(t, root)
+
+			var found ast.Node
+			var walk func(ast.Node)
+			walk = func(n ast.Node) {
+				switch n.(type) {
+				case *ast.FractionNode, *ast.BinomNode:
+					found = n
+				}
+				for _, c := range children(n) {
+					walk(c)
+				}
+			}
+			walk(root)
+
+			var num, den, thickness string
+			switch n := found.(type) {
+			case *ast.FractionNode:
+				if tt.binom {
+					t.Fatalf("got a fraction, want a binomial")
+				}
+				num, den, thickness = symbols(n.Numerator), symbols(n.Denominator), n.Thickness
+			case *ast.BinomNode:
+				if !tt.binom {
+					t.Fatalf("got a binomial, want a fraction")
+				}
+				num, den = symbols(n.Upper), symbols(n.Lower)
+			default:
+				t.Fatalf("no fraction in %#v", root)
+			}
+			if num != tt.num || den != tt.den || thickness != tt.thickness {
+				t.Errorf("got %q over %q of thickness %q, want %q over %q of thickness %q",
+					num, den, thickness, tt.num, tt.den, tt.thickness)
+			}
+		})
+	}
+}
+
+func TestAmbiguousFraction(t *testing.T) {
+	root, errs := parser.Parse(`{a \over b \choose c}`)
+	if len(errs) != 1 || errs[0].Code != parser.ErrAmbiguousFraction {
+		t.Fatalf("errors = %v, want one ambiguous fraction", errs)
+	}
+	if errs[0].Pos != 11 || errs[0].End != 18 || len(errs[0].Related) != 1 || errs[0].Related[0].Pos != 3 {
+		t.Errorf("error %+v, want at 11-18 related to 3", errs[0])
+	}
+	checkSpans(t, root)
+
+	// Braces resolve the ambiguity
+	if _, errs := parser.Parse(`{a \over {b \choose c}}`); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestAboveWithoutDimension(t *testing.T) {
+	_, errs := parser.Parse(`{a \above b}`)
+	if len(errs) != 1 || errs[0].Code != parser.ErrMissingArgument {
+		t.Fatalf("errors = %v, want a missing dimension", errs)
+	}
+	if got := parser.ApplyFix(`{a \above b}`, errs[0].Fixes[0]); got != `{a \above 1pt b}` {
+		t.Errorf("fix gives %q", got)
+	}
+}
+
+func TestMisplacedInfixFraction(t *testing.T) {
+	_, errs := parser.Parse(`x^\over y`)
+	if len(errs) == 0 || errs[0].Code != parser.ErrUnexpectedToken {
+		t.Errorf("errors = %v, want an unexpected token", errs)
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+// Limits bounds the resources a parse may use, for input from untrusted
+// sources. A zero field means no limit. There is no limit on macro
+// expansion, as the parser does not define or expand macros: the output
+// never grows beyond the tokens of the input.
+type Limits struct {
+	MaxInputLength int // length of the input in bytes
+	MaxTokens      int // number of tokens, not counting EOF
+	MaxDepth       int // nesting of groups, braced or unbraced arguments and \left...\right
+}
+
+// DefaultLimits are limits suitable for formulas typed by people.
+var DefaultLimits = Limits{
+	MaxInputLength: 64 << 10,
+	MaxTokens:      16 << 10,
+	MaxDepth:       128,
+}
+
+// checkInterval is the number of nodes parsed between checks whether the
+// context is done.
+const checkInterval = 256
+
+// Options configures ParseContext.
+type Options struct {
+	Profile Profile // dialect to accept
+	Limits
+}
+
+// ParseContext is like Parse, but accepts the dialect of opts.Profile and
+// stops with an error when the input exceeds opts.Limits or ctx is done.
+// Input beyond the point where the parse stopped is kept in an
+// ast.ErrorNode.
+func ParseContext(ctx context.Context, input string, opts Options) (ast.Node, []ParseError) {
+	limits := opts.Limits
+	if limits.MaxInputLength > 0 && len(input) > limits.MaxInputLength {
+		msg := fmt.Sprintf("input of %d bytes exceeds the limit of %d", len(input), limits.MaxInputLength)
+		return rejected(input, ErrInputTooLong, msg, limits.MaxInputLength)
+	}
+	tokens, err := tokenizer.TokenizeLimit(input, limits.MaxTokens)
+	if err != nil {
+		msg := fmt.Sprintf("input exceeds the limit of %d tokens", limits.MaxTokens)
+		return rejected(input, ErrTooManyTokens, msg, tokens[len(tokens)-1].Pos)
+	}
+
+	p := New(tokens)
+	p.SetProfile(opts.Profile)
+	p.ctx = ctx
+	p.maxDepth = limits.MaxDepth
+	return p.Parse()
+}
+
+// rejected returns the result for input that is not parsed at all: a tree
+// of a single error node and the error, located at pos.
+func rejected(input string, code Code, msg string, pos int) (ast.Node, []ParseError) {
+	root := &ast.ExpressionNode{Stop: len(input)}
+	if input != "" {
+		root.Elements = []ast.Node{&ast.ErrorNode{Stop: len(input), Message: msg}}
+	}
+	return root, []ParseError{{Code: code, Message: msg, Pos: pos, End: len(input)}}
+}
+
+// canceled reports whether the parse has to stop because its context is
+// done. It checks the context on the first and then every checkInterval
+// calls. Once stopped, every expression ends, so the parse unwinds without
+// consuming more input.
+func (p *Parser) canceled() bool {
+	if p.ctx == nil || p.stopped >= 0 {
+		return p.stopped >= 0
+	}
+	p.steps++
+	if p.steps%checkInterval != 1 {
+		return false
+	}
+	if err := p.ctx.Err(); err != nil {
+		pos := p.peek().Pos
+		p.addError(ErrCanceled, "parse stopped: "+err.Error(), pos, p.inputEnd())
+		p.stopped = pos
+		return true
+	}
+	return false
+}
+
+// tooDeep skips the tokens of an expression nested deeper than allowed up
+// to its closing token and returns them as an error node. The error is
+// reported once.
+func (p *Parser) tooDeep() *ast.ExpressionNode {
+	start := p.peek().Pos
+	msg := fmt.Sprintf("nesting exceeds the limit of %d levels", p.maxDepth)
+	if !p.deepReported {
+		p.addError(ErrTooDeep, msg, start, start)
+		p.deepReported = true
+	}
+
+	level := 0
+	for p.peek().Type != tokenizer.EOF && (level > 0 || !p.atClosing()) {
+		t := p.next()
+		switch {
+		case t.Type == tokenizer.LBRACE, t.Type == tokenizer.COMMAND && t.Value == "left":
+			level++
+		case t.Type == tokenizer.RBRACE, t.Type == tokenizer.COMMAND && t.Value == "right":
+			level--
+		}
+	}
+
+	expr := &ast.ExpressionNode{Start: start}
+	if end := p.lastEnd(); end > start {
+		expr.Elements = []ast.Node{&ast.ErrorNode{Start: start, Stop: end, Message: msg}}
+	}
+	return expr
+}
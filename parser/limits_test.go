@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		limits parser.Limits
+		code   parser.Code
+	}{
+		{"input length", strings.Repeat("x", 11), parser.Limits{MaxInputLength: 10}, parser.ErrInputTooLong},
+		{"tokens", "a+b+c", parser.Limits{MaxTokens: 4}, parser.ErrTooManyTokens},
+		{"depth", "{{{{x}}}}", parser.Limits{MaxDepth: 3}, parser.ErrTooDeep},
+		{"depth in arguments", `\frac{\sqrt{\left( x \right)}}{2}`, parser.Limits{MaxDepth: 2}, parser.ErrTooDeep},
+		{"depth in unbraced arguments", strings.Repeat(`\sqrt`, 1000) + "x", parser.Limits{MaxDepth: 10}, parser.ErrTooDeep},
+		{"depth in unbraced scripts", "x^" + strings.Repeat(`\sqrt`, 1000) + "y", parser.Limits{MaxDepth: 10}, parser.ErrTooDeep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, errs := parser.ParseContext(context.Background(), tt.input, parser.Options{Limits: tt.limits})
+			if len(errs) != 1 || errs[0].Code != tt.code {
+				t.Fatalf("errors = %v, want one %s", errs, tt.code.Name())
+			}
+			if root.Pos() != 0 || root.End() != len(tt.input) {
+				t.Errorf("root spans %d-%d, want 0-%d", root.Pos(), root.End(), len(tt.input))
+			}
+			checkSpans(t, root)
+		})
+	}
+}
+
+func TestLimitsAllowInput(t *testing.T) {
+	input := `\frac{\sqrt{x}}{2}`
+	limits := parser.Limits{MaxInputLength: len(input), MaxTokens: 13, MaxDepth: 2}
+	if _, errs := parser.ParseContext(context.Background(), input, parser.Options{Limits: limits}); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestDeepNestingWithinLimit(t *testing.T) {
+	// The closing braces after the skipped levels are still matched
+	input := strings.Repeat("{", 1000) + "x" + strings.Repeat("}", 1000) + "+y"
+	root, errs := parser.ParseContext(context.Background(), input, parser.Options{Limits: parser.DefaultLimits})
+	if len(errs) != 1 || errs[0].Code != parser.ErrTooDeep {
+		t.Fatalf("errors = %v, want one too-deep error", errs)
+	}
+	if n := len(children(root)); n != 3 {
+		t.Errorf("got %d elements, want the group, + and y", n)
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input := strings.Repeat(`\frac{a}{b} + `, 100) + "c"
+	root, errs := parser.ParseContext(ctx, input, parser.Options{})
+	if len(errs) != 1 || errs[0].Code != parser.ErrCanceled {
+		t.Fatalf("errors = %v, want one canceled error", errs)
+	}
+	if root.End() != len(input) {
+		t.Errorf("root ends at %d, want %d", root.End(), len(input))
+	}
+	checkSpans(t, root)
+}
@@ -1,16 +1,183 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
 
-type ParseError struct {
+	"github.com/neox5/texmax/source"
+)
+
+// Severity tells how serious a diagnostic is.
+type Severity int
+
+const (
+	Error   Severity = iota // the input is invalid
+	Warning                 // the input is accepted but likely wrong
+	Hint                    // a suggestion for better input
+)
+
+// String returns the lowercase name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Hint:
+		return "hint"
+	default:
+		return "unknown"
+	}
+}
+
+// Code identifies the kind of a diagnostic. Codes are stable across
+// releases, so tools may match on them; the zero Code is used by parsers
+// that do not classify their errors.
+type Code int
+
+const (
+	ErrUnexpectedToken        Code = iota + 1 // E0001 a token that cannot start an expression
+	ErrExpectedBrace                          // E0002 a command argument without braces
+	ErrUnsupportedCommand                     // E0003 an unknown command
+	ErrUnclosedGroup                          // E0004 a '{' without '}'
+	ErrUnclosedOptional                       // E0005 a '[' of an optional argument without ']'
+	ErrMissingArgument                        // E0006 a command without a required argument
+	ErrUnmatchedRight                         // E0007 a \right without \left
+	ErrMissingRight                           // E0008 a \left without \right
+	ErrMissingDelimiter                       // E0009 a \left or \right without a delimiter
+	ErrDuplicateScript                        // E0010 two subscripts or superscripts on one base
+	ErrInvalidLimit                           // E0011 a limit the operator does not take
+	ErrMissingBackslash                       // E0012 a function name written without backslash
+	ErrInputTooLong                           // E0013 input longer than Limits.MaxInputLength
+	ErrTooManyTokens                          // E0014 input of more than Limits.MaxTokens tokens
+	ErrTooDeep                                // E0015 nesting deeper than Limits.MaxDepth
+	ErrCanceled                               // E0016 a parse whose context is done
+	ErrDeprecated                             // E0017 a construct the profile rejects as deprecated
+	ErrUnsupportedEnvironment                 // E0018 an environment the profile does not define
+	ErrAmbiguousFraction                      // E0019 a group with more than one \over, \choose, \atop or \above
+	ErrInvalidArgument                        // E0020 an argument that is not of the form the command takes
+)
+
+var codeNames = map[Code]string{
+	ErrUnexpectedToken:        "unexpected-token",
+	ErrExpectedBrace:          "expected-brace",
+	ErrUnsupportedCommand:     "unsupported-command",
+	ErrUnclosedGroup:          "unclosed-group",
+	ErrUnclosedOptional:       "unclosed-optional",
+	ErrMissingArgument:        "missing-argument",
+	ErrUnmatchedRight:         "unmatched-right",
+	ErrMissingRight:           "missing-right",
+	ErrMissingDelimiter:       "missing-delimiter",
+	ErrDuplicateScript:        "duplicate-script",
+	ErrInvalidLimit:           "invalid-limit",
+	ErrMissingBackslash:       "missing-backslash",
+	ErrInputTooLong:           "input-too-long",
+	ErrTooManyTokens:          "too-many-tokens",
+	ErrTooDeep:                "too-deep",
+	ErrCanceled:               "canceled",
+	ErrDeprecated:             "deprecated",
+	ErrUnsupportedEnvironment: "unsupported-environment",
+	ErrAmbiguousFraction:      "ambiguous-fraction",
+	ErrInvalidArgument:        "invalid-argument",
+}
+
+// String returns the code as "E0003", or "" for the zero Code.
+func (c Code) String() string {
+	if c == 0 {
+		return ""
+	}
+	return fmt.Sprintf("E%04d", int(c))
+}
+
+// Name returns the symbolic name of the code, e.g. "unsupported-command".
+func (c Code) Name() string {
+	return codeNames[c]
+}
+
+// Related is a location that helps to understand a diagnostic, such as the
+// \left that a missing \right belongs to.
+type Related struct {
 	Message string
+	Pos     int // byte offset of the start
+	End     int // byte offset after the end
+}
+
+// Edit replaces the input between Pos and End with NewText. Pos equal to
+// End inserts.
+type Edit struct {
 	Pos     int
+	End     int
+	NewText string
+}
+
+// Fix is a suggested change that resolves a diagnostic. Its edits do not
+// overlap and can be applied mechanically.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// ParseError is a diagnostic reported by a parser. Pos and End span the
+// offending input as byte offsets; End equals Pos for a point.
+type ParseError struct {
+	Message  string
+	Pos      int
+	End      int
+	Code     Code
+	Severity Severity
+	Related  []Related
+	Fixes    []Fix
 }
 
 func (e ParseError) String() string {
 	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
 }
 
-func (p *Parser) addError(msg string, pos int) {
-	p.errors = append(p.errors, ParseError{msg, pos})
+// Error implements the error interface.
+func (e ParseError) Error() string {
+	return e.String()
+}
+
+// Position resolves the byte offset of the error in the parsed file.
+func (e ParseError) Position(f *source.File) source.Position {
+	return f.Position(e.Pos)
+}
+
+// ApplyFix returns input with the edits of fix applied.
+func ApplyFix(input string, fix Fix) string {
+	out, last := "", 0
+	for _, edit := range sortedEdits(fix.Edits) {
+		out += input[last:edit.Pos] + edit.NewText
+		last = edit.End
+	}
+	return out + input[last:]
+}
+
+// sortedEdits returns the edits ordered by position.
+func sortedEdits(edits []Edit) []Edit {
+	sorted := append([]Edit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Pos < sorted[j].Pos })
+	return sorted
+}
+
+// addError reports an error spanning the input from pos to end.
+func (p *Parser) addError(code Code, msg string, pos, end int) {
+	p.report(ParseError{Code: code, Message: msg, Pos: pos, End: end})
+}
+
+// report adds a diagnostic. Once the parse is canceled, the errors of the
+// constructs it leaves unfinished are not reported.
+func (p *Parser) report(e ParseError) {
+	if p.stopped >= 0 {
+		return
+	}
+	if e.End < e.Pos {
+		e.End = e.Pos
+	}
+	p.errors = append(p.errors, e)
+}
+
+// insert returns a fix that inserts text at pos.
+func insert(msg string, pos int, text string) Fix {
+	return Fix{Message: msg, Edits: []Edit{{Pos: pos, End: pos, NewText: text}}}
 }
@@ -0,0 +1,70 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+)
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		code     parser.Code
+		pos, end int
+		related  int // position of the related location, or -1
+		fixed    string
+	}{
+		{`x + \foo`, parser.ErrUnsupportedCommand, 4, 8, -1, ""},
+		{`\left( x`, parser.ErrMissingRight, 8, 8, 0, `\left( x\right.`},
+		{`\left x \right)`, parser.ErrMissingDelimiter, 6, 6, -1, `\left .x \right)`},
+		{`\frac{a}b`, parser.ErrExpectedBrace, 8, 9, -1, `\frac{a}{b}`},
+		{`\frac{a}12`, parser.ErrExpectedBrace, 8, 10, -1, `\frac{a}{1}2`},
+		{`\sqrt[3 x`, parser.ErrUnclosedOptional, 9, 9, 5, `\sqrt[3 x]`},
+		{`x \right)`, parser.ErrUnmatchedRight, 2, 8, -1, `x )`},
+		{`\sum_a_b x`, parser.ErrDuplicateScript, 6, 7, -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, errs := parser.Parse(tt.input)
+			if len(errs) == 0 {
+				t.Fatal("no errors")
+			}
+			e := errs[0]
+			if e.Code != tt.code || e.Pos != tt.pos || e.End != tt.end {
+				t.Errorf("got %s %d-%d, want %s %d-%d", e.Code, e.Pos, e.End, tt.code, tt.pos, tt.end)
+			}
+			if e.Severity != parser.Error {
+				t.Errorf("severity = %s, want error", e.Severity)
+			}
+			if tt.related >= 0 && (len(e.Related) != 1 || e.Related[0].Pos != tt.related) {
+				t.Errorf("related = %+v, want position %d", e.Related, tt.related)
+			}
+			if tt.fixed == "" {
+				return
+			}
+			if len(e.Fixes) == 0 {
+				t.Fatal("no fix")
+			}
+			if got := parser.ApplyFix(tt.input, e.Fixes[0]); got != tt.fixed {
+				t.Errorf("fixed = %q, want %q", got, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	c := parser.ErrUnsupportedCommand
+	if c.String() != "E0003" || c.Name() != "unsupported-command" {
+		t.Errorf("got %s %s", c, c.Name())
+	}
+}
+
+func TestParseErrorIsError(t *testing.T) {
+	var err error = parser.ParseError{Message: "expected '{'", Pos: 3}
+	var pe parser.ParseError
+	if !errors.As(err, &pe) || pe.Pos != 3 {
+		t.Errorf("errors.As failed for %v", err)
+	}
+}
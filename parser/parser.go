@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"context"
+
 	"github.com/neox5/texmax/ast"
 	"github.com/neox5/texmax/tokenizer"
 )
@@ -11,15 +13,32 @@ type Parser struct {
 	prefix map[tokenizer.TokenType]func() ast.Node
 	infix  map[tokenizer.TokenType]func(ast.Node) ast.Node
 	errors []ParseError
+
+	optional int // depth of optional arguments, in which ']' closes
+	profile  Profile
+
+	// \middle separators of the innermost \left...\right, whose content
+	// is at depth middleDepth, or 0 outside of \left...\right
+	middle      []*ast.DelimiterNode
+	middleDepth int
+
+	// Resource limits, see ParseContext
+	ctx          context.Context
+	maxDepth     int
+	depth        int  // nesting of the current expression
+	deepReported bool // whether the nesting limit was reported
+	steps        int  // calls of canceled
+	stopped      int  // position at which the context was done, or -1
 }
 
 func New(ts []tokenizer.Token) *Parser {
 	p := &Parser{
-		tokens: ts,
-		pos:    0,
-		prefix: make(map[tokenizer.TokenType]func() ast.Node),
-		infix:  make(map[tokenizer.TokenType]func(ast.Node) ast.Node),
-		errors: []ParseError{},
+		tokens:  ts,
+		pos:     0,
+		prefix:  make(map[tokenizer.TokenType]func() ast.Node),
+		infix:   make(map[tokenizer.TokenType]func(ast.Node) ast.Node),
+		errors:  []ParseError{},
+		stopped: -1,
 	}
 
 	// Prefix registration
@@ -37,48 +56,102 @@ func New(ts []tokenizer.Token) *Parser {
 	return p
 }
 
+// Parse tokenizes and parses a LaTeX math expression.
+func Parse(input string) (ast.Node, []ParseError) {
+	return New(tokenizer.Tokenize(input)).Parse()
+}
+
+// Parse parses the whole input. It does not stop at the first error:
+// input that cannot be parsed is reported and kept in ast.ErrorNode, so
+// every token of the input belongs to some node of the returned tree,
+// whose root spans the whole input. Constructs that are not closed end at
+// the next synchronization point: a closing brace, \right, or the end of
+// the input.
 func (p *Parser) Parse() (ast.Node, []ParseError) {
 	expr := p.parseExpression()
+	for p.stopped < 0 && p.peek().Type != tokenizer.EOF {
+		// A '}' or \right that closes nothing
+		expr.Elements = append(expr.Elements, p.unexpected(p.next()))
+		expr.Elements = append(expr.Elements, p.parseExpression().Elements...)
+	}
+	expr.Start, expr.Stop = 0, p.inputEnd()
+	if p.stopped >= 0 {
+		// Keep the input that was not parsed
+		if p.stopped < expr.Stop {
+			expr.Elements = append(expr.Elements, &ast.ErrorNode{Start: p.stopped, Stop: expr.Stop, Message: "not parsed"})
+		}
+		return expr, p.errors
+	}
+	p.checkFunctionNames()
 	return expr, p.errors
 }
 
-// parseExpression parses a sequence of nodes that form an expression
+// parseExpression parses a sequence of nodes up to the next
+// synchronization point.
 func (p *Parser) parseExpression() *ast.ExpressionNode {
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		return p.tooDeep()
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
 	start := p.peek().Pos
 	var elements []ast.Node
+	var fraction *infixFraction
 
-	for {
-		curr := p.peek()
-		// Exit conditions
-		if curr.Type == tokenizer.EOF ||
-			curr.Type == tokenizer.RBRACE ||
-			(curr.Type == tokenizer.DELIMITER && curr.Value == "]") ||
-			(curr.Type == tokenizer.COMMAND && curr.Value == "right") {
-			break
+	for !p.atClosing() {
+		if t := p.peek(); t.Type == tokenizer.COMMAND && infixFractions[t.Value] {
+			// A generalized fraction takes the whole expression apart
+			if fraction == nil {
+				fraction = p.parseInfixFraction(&ast.ExpressionNode{Start: start, Elements: elements})
+				elements = nil
+			} else {
+				elements = append(elements, p.ambiguousFraction(fraction))
+			}
+			continue
 		}
-
-		n := p.parseNode(LOWEST)
-		if n != nil {
+		if n := p.parseNode(LOWEST); n != nil {
 			elements = append(elements, n)
-		} else {
-			// If parseNode returns nil, we should advance past the current token
-			// to avoid infinite loops (unless we're at EOF)
-			if p.peek().Type != tokenizer.EOF {
-				p.next()
-			}
 		}
 	}
 
+	if fraction != nil {
+		return &ast.ExpressionNode{Start: start, Elements: []ast.Node{fraction.node(elements)}}
+	}
 	return &ast.ExpressionNode{Start: start, Elements: elements}
 }
 
-func (p *Parser) parseNode(precedence int) ast.Node {
+// atClosing reports whether the next token ends an expression: the end of
+// input, '}', \right or, in an optional argument, ']'. Every expression
+// ends once the parse is canceled.
+func (p *Parser) atClosing() bool {
+	if p.canceled() {
+		return true
+	}
 	t := p.peek()
+	switch t.Type {
+	case tokenizer.EOF, tokenizer.RBRACE:
+		return true
+	case tokenizer.COMMAND:
+		return t.Value == "right"
+	case tokenizer.DELIMITER:
+		return t.Value == "]" && p.optional > 0
+	}
+	return false
+}
+
+// parseNode parses a node with the infix operators binding tighter than
+// precedence. At the end of an expression, where an argument is missing,
+// it returns nil for the caller to report.
+func (p *Parser) parseNode(precedence int) ast.Node {
+	if p.atClosing() {
+		return nil
+	}
 
+	t := p.peek()
 	prefix := p.prefix[t.Type]
 	if prefix == nil {
-		p.addError("no prefix token: "+t.Value, t.Pos)
-		return nil
+		return p.unexpected(p.next())
 	}
 
 	left := prefix()
@@ -93,3 +166,71 @@ func (p *Parser) parseNode(precedence int) ast.Node {
 
 	return left
 }
+
+// unexpected reports a consumed token that cannot appear where it is and
+// returns it as an error node.
+func (p *Parser) unexpected(t tokenizer.Token) ast.Node {
+	e := ParseError{
+		Code:    ErrUnexpectedToken,
+		Message: "unexpected " + describe(t),
+		Pos:     t.Pos,
+		End:     t.End(),
+	}
+	switch {
+	case t.Type == tokenizer.RBRACE:
+		e.Fixes = []Fix{{Message: "remove '}'", Edits: []Edit{{Pos: t.Pos, End: t.End()}}}}
+	case t.Type == tokenizer.COMMAND && t.Value == "right":
+		e.Code = ErrUnmatchedRight
+		e.Message = "unexpected \\right without matching \\left"
+		e.Fixes = []Fix{{Message: "remove \\right", Edits: []Edit{{Pos: t.Pos, End: t.End()}}}}
+	case t.Type == tokenizer.ILLEGAL && t.Value == "&":
+		e.Message = "alignment tab '&' outside of an environment"
+	case t.Type == tokenizer.COMMAND && t.Value == "\\":
+		e.Message = "line break '\\\\' outside of an environment"
+	}
+	p.report(e)
+	return &ast.ErrorNode{Start: t.Pos, Stop: t.End(), Message: e.Message}
+}
+
+// partial reports a construct from start up to the last consumed token
+// that could not be completed, and returns it as an error node keeping
+// the parts that were parsed. Nil parts are left out.
+func (p *Parser) partial(start int, msg string, parts ...ast.Node) ast.Node {
+	n := &ast.ErrorNode{Start: start, Stop: p.lastEnd(), Message: msg}
+	for _, part := range parts {
+		if part != nil {
+			n.Elements = append(n.Elements, part)
+		}
+	}
+	return n
+}
+
+// lastEnd returns the position after the last consumed token other than
+// a space.
+func (p *Parser) lastEnd() int {
+	for i := min(p.pos, len(p.tokens)) - 1; i >= 0; i-- {
+		if p.tokens[i].Type != tokenizer.SPACE {
+			return p.tokens[i].End()
+		}
+	}
+	return 0
+}
+
+// inputEnd returns the length of the input.
+func (p *Parser) inputEnd() int {
+	if len(p.tokens) == 0 {
+		return 0
+	}
+	return p.tokens[len(p.tokens)-1].End()
+}
+
+// describe names a token in an error message.
+func describe(t tokenizer.Token) string {
+	switch t.Type {
+	case tokenizer.EOF:
+		return "end of input"
+	case tokenizer.COMMAND:
+		return "'\\" + t.Value + "'"
+	}
+	return "'" + t.Value + "'"
+}
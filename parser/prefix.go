@@ -31,62 +31,85 @@ func (p *Parser) parseDelimiter() ast.Node {
 	startPos := token.Pos
 
 	switch token.Type {
-	case tokenizer.DELIMITER:
-		// Regular delimiter like (, ), [, ], |
-		p.next() // consume the delimiter token
-		return &ast.DelimiterNode{
-			Start: startPos,
-			Value: token.Value,
+	case tokenizer.DELIMITER, tokenizer.OPERATOR:
+		// Regular delimiter like (, ), [, ], |, and /, < and >
+		if kind, ok := delimiterTokens[token.Value]; ok {
+			p.next() // consume the delimiter token
+			return &ast.DelimiterNode{Start: startPos, Stop: token.End(), Kind: kind}
 		}
 
+	case tokenizer.PERIOD:
+		// Empty delimiter as in \right.
+		p.next()
+		return &ast.DelimiterNode{Start: startPos, Stop: token.End(), Kind: ast.NoDelimiter}
+
 	case tokenizer.COMMAND:
 		// Command delimiter like \{, \}, \langle, \rangle, etc.
-		p.next() // consume the command token
-		value := token.Value
-
-		// Store delimiter commands using standardized names
-		switch value {
-		// Empty delimiter
-		case ".":
-			return &ast.DelimiterNode{Start: startPos, Value: "."}
-
-		// Braces
-		case "{":
-			return &ast.DelimiterNode{Start: startPos, Value: "{"}
-		case "}":
-			return &ast.DelimiterNode{Start: startPos, Value: "}"}
-
-		// Vertical bar
-		case "lvert", "rvert":
-			return &ast.DelimiterNode{Start: startPos, Value: "|"}
-		// Norm (double vertical bar)
-		case "|", "lVert", "rVert":
-			return &ast.DelimiterNode{Start: startPos, Value: "||"}
-
-		// Angle brackets
-		case "langle":
-			return &ast.DelimiterNode{Start: startPos, Value: "langle"}
-		case "rangle":
-			return &ast.DelimiterNode{Start: startPos, Value: "rangle"}
-
-		// Floor
-		case "lfloor":
-			return &ast.DelimiterNode{Start: startPos, Value: "lfloor"}
-		case "rfloor":
-			return &ast.DelimiterNode{Start: startPos, Value: "rfloor"}
-
-		// Ceiling
-		case "lceil":
-			return &ast.DelimiterNode{Start: startPos, Value: "lceil"}
-		case "rceil":
-			return &ast.DelimiterNode{Start: startPos, Value: "rceil"}
-
-		// Backslash
-		case "backslash":
-			return &ast.DelimiterNode{Start: startPos, Value: "backslash"}
+		if kind, ok := delimiterCommands[token.Value]; ok {
+			p.next() // consume the command token
+			p.checkProfile(token)
+			return &ast.DelimiterNode{Start: startPos, Stop: token.End(), Kind: kind}
 		}
 	}
 
 	// If we reach here, it wasn't a valid delimiter
 	return nil
 }
+
+// isDelimiterCommand reports whether a command is a delimiter that can
+// stand alone, such as \langle. The empty delimiter \. cannot.
+func isDelimiterCommand(name string) bool {
+	_, ok := delimiterCommands[name]
+	return ok && name != "."
+}
+
+// delimiterTokens maps the characters that are delimiters to their kinds.
+var delimiterTokens = map[string]ast.Delimiter{
+	"(": ast.LeftParen,
+	")": ast.RightParen,
+	"[": ast.LeftBracket,
+	"]": ast.RightBracket,
+	"|": ast.Vert,
+	"/": ast.Slash,
+	"<": ast.LeftAngle,
+	">": ast.RightAngle,
+}
+
+// delimiterCommands maps the delimiter commands to their kinds.
+var delimiterCommands = map[string]ast.Delimiter{
+	".":           ast.NoDelimiter,
+	"{":           ast.LeftBrace,
+	"}":           ast.RightBrace,
+	"lbrace":      ast.LeftBrace,
+	"rbrace":      ast.RightBrace,
+	"lbrack":      ast.LeftBracket,
+	"rbrack":      ast.RightBracket,
+	"langle":      ast.LeftAngle,
+	"rangle":      ast.RightAngle,
+	"lfloor":      ast.LeftFloor,
+	"rfloor":      ast.RightFloor,
+	"lceil":       ast.LeftCeil,
+	"rceil":       ast.RightCeil,
+	"lgroup":      ast.LeftGroup,
+	"rgroup":      ast.RightGroup,
+	"lmoustache":  ast.LeftMoustache,
+	"rmoustache":  ast.RightMoustache,
+	"ulcorner":    ast.UpperLeftCorner,
+	"urcorner":    ast.UpperRightCorner,
+	"llcorner":    ast.LowerLeftCorner,
+	"lrcorner":    ast.LowerRightCorner,
+	"vert":        ast.Vert,
+	"lvert":       ast.LeftVert,
+	"rvert":       ast.RightVert,
+	"|":           ast.DoubleVert,
+	"Vert":        ast.DoubleVert,
+	"lVert":       ast.LeftDoubleVert,
+	"rVert":       ast.RightDoubleVert,
+	"backslash":   ast.Backslash,
+	"uparrow":     ast.UpArrow,
+	"downarrow":   ast.DownArrow,
+	"updownarrow": ast.UpDownArrow,
+	"Uparrow":     ast.DoubleUpArrow,
+	"Downarrow":   ast.DoubleDownArrow,
+	"Updownarrow": ast.DoubleUpDownArrow,
+}
@@ -0,0 +1,167 @@
+package parser
+
+import "github.com/neox5/texmax/tokenizer"
+
+// Profile is a dialect of LaTeX math, such as the subset a renderer
+// displays. The zero Profile accepts everything the parser knows.
+type Profile struct {
+	Name string
+
+	// Missing lists the commands known to the parser that the dialect
+	// does not define.
+	Missing map[string]bool
+
+	// Environments lists the math environments of the dialect. A nil map
+	// accepts all.
+	Environments map[string]bool
+
+	// RejectDeprecated makes TeX constructs superseded by LaTeX errors: the
+	// infix fractions \over, \choose, \atop and \above, and $$ display math.
+	RejectDeprecated bool
+
+	// UnknownCommands is the severity of commands the dialect does not
+	// define. Permissive profiles report them as warnings.
+	UnknownCommands Severity
+}
+
+// HasCommand reports whether the dialect defines the command name, which
+// the parser knows.
+func (pr Profile) HasCommand(name string) bool {
+	return !pr.Missing[name]
+}
+
+// HasEnvironment reports whether the dialect defines the environment.
+func (pr Profile) HasEnvironment(name string) bool {
+	return pr.Environments == nil || pr.Environments[name]
+}
+
+// String returns the name of the profile.
+func (pr Profile) String() string {
+	if pr.Name == "" {
+		return "default"
+	}
+	return pr.Name
+}
+
+// deprecatedCommands maps the deprecated TeX commands to their LaTeX
+// replacements.
+var deprecatedCommands = map[string]string{
+	"over":   `\frac`,
+	"choose": `\binom`,
+	"atop":   `\genfrac`,
+	"above":  `\genfrac`,
+}
+
+// extraGreek are the capital Greek letters that look like Latin ones and
+// omicron. LaTeX does not define them.
+var extraGreek = []string{
+	"Alpha", "Beta", "Epsilon", "Zeta", "Eta", "Iota", "Kappa", "Mu", "Nu",
+	"Omicron", "Rho", "Tau", "Chi", "omicron",
+}
+
+// amsmathCommands are the known commands that need the amsmath package.
+var amsmathCommands = []string{
+	"binom", "dbinom", "tbinom", "dfrac", "tfrac", "cfrac", "genfrac",
+	"lvert", "rvert", "lVert", "rVert", "mod",
+}
+
+// amssymbCommands are the known commands that need the amssymb package.
+var amssymbCommands = []string{"ulcorner", "urcorner", "llcorner", "lrcorner"}
+
+// coreEnvironments are the math environments of LaTeX itself.
+var coreEnvironments = []string{"math", "displaymath", "equation", "eqnarray", "eqnarray*", "array"}
+
+// amsmathEnvironments are the math environments of the amsmath package.
+var amsmathEnvironments = []string{
+	"equation*", "align", "align*", "alignat", "alignat*", "flalign", "flalign*",
+	"gather", "gather*", "multline", "multline*", "split", "aligned", "alignedat",
+	"gathered", "cases", "matrix", "pmatrix", "bmatrix", "Bmatrix", "vmatrix",
+	"Vmatrix", "smallmatrix", "subarray",
+}
+
+// Predefined profiles
+var (
+	// Default accepts everything the parser knows.
+	Default = Profile{Name: "default"}
+
+	// Strict is LaTeX without packages. Deprecated constructs are errors.
+	Strict = Profile{
+		Name:             "strict",
+		Missing:          set(extraGreek, amsmathCommands, amssymbCommands),
+		Environments:     set(coreEnvironments),
+		RejectDeprecated: true,
+	}
+
+	// AMSMath is LaTeX with the amsmath package, which advises against the
+	// deprecated constructs.
+	AMSMath = Profile{
+		Name:             "amsmath",
+		Missing:          set(extraGreek, amssymbCommands),
+		Environments:     set(coreEnvironments, amsmathEnvironments),
+		RejectDeprecated: true,
+	}
+
+	// KaTeX is the dialect of the KaTeX renderer.
+	KaTeX = Profile{
+		Name: "katex",
+		Environments: set([]string{
+			"equation", "equation*", "align", "align*", "alignat", "alignat*",
+			"gather", "gather*", "split", "aligned", "alignedat", "gathered",
+			"cases", "dcases", "rcases", "drcases", "array", "darray", "matrix",
+			"pmatrix", "bmatrix", "Bmatrix", "vmatrix", "Vmatrix", "smallmatrix",
+			"subarray", "CD",
+		}),
+	}
+
+	// MathJax is the dialect of MathJax with its AMS extensions.
+	MathJax = Profile{
+		Name:         "mathjax",
+		Missing:      set(extraGreek),
+		Environments: set(coreEnvironments, amsmathEnvironments, []string{"CD"}),
+	}
+)
+
+func set(lists ...[]string) map[string]bool {
+	m := map[string]bool{}
+	for _, list := range lists {
+		for _, s := range list {
+			m[s] = true
+		}
+	}
+	return m
+}
+
+// SetProfile sets the dialect the parser accepts.
+func (p *Parser) SetProfile(profile Profile) {
+	p.profile = profile
+}
+
+// checkProfile reports a known command that the profile does not define.
+// The command is parsed all the same.
+func (p *Parser) checkProfile(t tokenizer.Token) {
+	if p.profile.HasCommand(t.Value) {
+		return
+	}
+	p.report(ParseError{
+		Code:     ErrUnsupportedCommand,
+		Severity: p.profile.UnknownCommands,
+		Message:  "\\" + t.Value + " is not available in the " + p.profile.String() + " profile",
+		Pos:      t.Pos,
+		End:      t.End(),
+	})
+}
+
+// deprecated reports a deprecated command if the profile rejects them,
+// and tells whether it did.
+func (p *Parser) deprecated(t tokenizer.Token) bool {
+	if !p.profile.RejectDeprecated {
+		return false
+	}
+	p.report(ParseError{
+		Code:    ErrDeprecated,
+		Message: "\\" + t.Value + " is deprecated, use " + deprecatedCommands[t.Value],
+		Pos:     t.Pos,
+		End:     t.End(),
+	})
+	return true
+}
@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+)
+
+func TestProfiles(t *testing.T) {
+	tests := []struct {
+		input   string
+		profile parser.Profile
+		code    parser.Code // zero if the input is accepted
+	}{
+		{`\binom{n}{k}`, parser.Default, 0},
+		{`\binom{n}{k}`, parser.KaTeX, 0},
+		{`\binom{n}{k}`, parser.AMSMath, 0},
+		{`\binom{n}{k}`, parser.Strict, parser.ErrUnsupportedCommand},
+		{`\Alpha + \alpha`, parser.KaTeX, 0},
+		{`\Alpha + \alpha`, parser.MathJax, parser.ErrUnsupportedCommand},
+		{`\left\lvert x \right\rvert`, parser.Strict, parser.ErrUnsupportedCommand},
+		{`\left\ulcorner x \right\urcorner`, parser.AMSMath, parser.ErrUnsupportedCommand},
+		{`\left\ulcorner x \right\urcorner`, parser.KaTeX, 0},
+		{`\dfrac{1}{2}`, parser.Strict, parser.ErrUnsupportedCommand},
+		{`\dfrac{1}{2}`, parser.MathJax, 0},
+		{`a \over b`, parser.Strict, parser.ErrDeprecated},
+		{`a \choose b`, parser.AMSMath, parser.ErrDeprecated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile.String()+"/"+tt.input, func(t *testing.T) {
+			_, errs := parser.ParseContext(context.Background(), tt.input, parser.Options{Profile: tt.profile})
+			if tt.code == 0 {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || errs[0].Code != tt.code {
+				t.Fatalf("errors = %v, want %s", errs, tt.code)
+			}
+			if errs[0].Severity != parser.Error {
+				t.Errorf("severity = %s, want error", errs[0].Severity)
+			}
+		})
+	}
+}
+
+func TestPermissiveProfile(t *testing.T) {
+	profile := parser.Strict
+	profile.UnknownCommands = parser.Warning
+	for _, input := range []string{`\foo + x`, `\binom{n}{k}`} {
+		_, errs := parser.ParseContext(context.Background(), input, parser.Options{Profile: profile})
+		if len(errs) != 1 || errs[0].Severity != parser.Warning {
+			t.Errorf("%s: errors = %v, want one warning", input, errs)
+		}
+	}
+}
+
+func TestSuggestionsFollowProfile(t *testing.T) {
+	// \Betta is closest to \Beta, which MathJax does not define
+	_, errs := parser.Parse(`\Betta`)
+	if len(errs) != 1 || len(errs[0].Fixes) == 0 || errs[0].Fixes[0].Message != `did you mean \Beta?` {
+		t.Fatalf("default profile: errors = %v, want a fix to \\Beta", errs)
+	}
+	_, errs = parser.ParseContext(context.Background(), `\Betta`, parser.Options{Profile: parser.MathJax})
+	for _, fix := range errs[0].Fixes {
+		if fix.Message == `did you mean \Beta?` {
+			t.Errorf("mathjax profile suggests \\Beta")
+		}
+	}
+}
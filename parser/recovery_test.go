@@ -0,0 +1,167 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+// children returns the child nodes of n.
+func children(n ast.Node) []ast.Node {
+	var nodes []ast.Node
+	add := func(children ...ast.Node) {
+		for _, c := range children {
+			if c != nil {
+				nodes = append(nodes, c)
+			}
+		}
+	}
+	switch n := n.(type) {
+	case *ast.ExpressionNode:
+		add(n.Elements...)
+	case *ast.ErrorNode:
+		add(n.Elements...)
+	case *ast.DelimitedExpressionNode:
+		add(n.LeftDelimiter, n.Content, n.RightDelimiter)
+	case *ast.SuperscriptNode:
+		add(n.Base, n.Exponent)
+	case *ast.SubscriptNode:
+		add(n.Base, n.Subscript)
+	case *ast.FractionNode:
+		add(n.Numerator, n.Denominator)
+	case *ast.LimitedOperatorNode:
+		add(n.LowerLimit, n.UpperLimit)
+	case *ast.SqrtNode:
+		add(n.Index, n.Radicand)
+	case *ast.BinomNode:
+		add(n.Upper, n.Lower)
+	case *ast.SizedDelimiterNode:
+		add(n.Delimiter)
+	}
+	return nodes
+}
+
+// checkSpans reports nodes that are not within the span of their parent.
+func checkSpans(t *testing.T, n ast.Node) {
+	t.Helper()
+	for _, c := range children(n) {
+		if c.Pos() < n.Pos() || c.End() > n.End() {
+			t.Errorf("%T %d-%d is outside of its parent %T %d-%d", c, c.Pos(), c.End(), n, n.Pos(), n.End())
+		}
+		checkSpans(t, c)
+	}
+}
+
+func TestRecoveryCoversInput(t *testing.T) {
+	inputs := []string{
+		`\frac{a}`,
+		`\frac{a`,
+		`\frac{a}{b}}+c`,
+		`a}+b`,
+		`x \right) + y`,
+		`\left( x`,
+		`\left x \right)`,
+		`\left\foo x \right)`,
+		`x^`,
+		`{x_} + 1`,
+		`\sum_ x`,
+		`\sqrt[3`,
+		`\sqrt[3]`,
+		`\binom{n}`,
+		`a & b \\ c`,
+		`\lim^2_x y`,
+		`\foo{x} + \aplha`,
+		`\left\langle x \right\rangle`,
+		`[0, 1]`,
+		`{a \over b \over c}`,
+		`{a \above b}`,
+		`x^\choose y`,
+		`\cfrac[q]{a}`,
+		`\genfrac()`,
+		`\genfrac{x}{}{zz}{7}{a}{b}`,
+		`\genfrac{}{}{\frac{1}{2}}{}{a}`,
+		`\genfrac[]{}{}{a}{b} + \dbinom{n}`,
+		`\bigl x \bigr)`,
+		`\middle| x`,
+		`\left( a \middle \right)`,
+		`\left\{ x \middle| x > 0`,
+		`\alpha`,
+		`a \leq b`,
+		`\cdot`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			root, _ := parser.Parse(input)
+			if root.Pos() != 0 || root.End() != len(input) {
+				t.Errorf("root spans %d-%d, want 0-%d", root.Pos(), root.End(), len(input))
+			}
+			checkSpans(t, root)
+
+			// Every token must be within an element of the root
+			elements := children(root)
+			for _, tok := range tokenizer.Tokenize(input) {
+				if tok.Type == tokenizer.SPACE || tok.Type == tokenizer.EOF {
+					continue
+				}
+				covered := false
+				for _, el := range elements {
+					covered = covered || el.Pos() <= tok.Pos && tok.End() <= el.End()
+				}
+				if !covered {
+					t.Errorf("token %q at %d is not covered", tok.Value, tok.Pos)
+				}
+			}
+		})
+	}
+}
+
+func TestFractionKeepsNumerator(t *testing.T) {
+	root, errs := parser.Parse(`\frac{a}`)
+	if len(errs) == 0 {
+		t.Fatal("no errors")
+	}
+	elements := root.(*ast.ExpressionNode).Elements
+	if len(elements) != 1 {
+		t.Fatalf("got %d elements, want 1", len(elements))
+	}
+	errNode, ok := elements[0].(*ast.ErrorNode)
+	if !ok || len(errNode.Elements) != 1 {
+		t.Fatalf("got %#v, want an error node with the numerator", elements[0])
+	}
+	numerator := errNode.Elements[0].(*ast.ExpressionNode)
+	if sym, ok := numerator.Elements[0].(*ast.SymbolNode); !ok || sym.Value != "a" {
+		t.Errorf("numerator = %#v, want a", numerator.Elements[0])
+	}
+	if errNode.Pos() != 0 || errNode.End() != 8 {
+		t.Errorf("error node spans %d-%d, want 0-8", errNode.Pos(), errNode.End())
+	}
+}
+
+func TestUnclosedGroupKeepsNextToken(t *testing.T) {
+	// The group ends at \right without consuming it, so the \left is closed
+	root, errs := parser.Parse(`\left( \frac{a}{b \right)`)
+	if len(errs) != 1 || errs[0].Code != parser.ErrUnclosedGroup {
+		t.Fatalf("errors = %v, want one unclosed group", errs)
+	}
+	elements := root.(*ast.ExpressionNode).Elements
+	if _, ok := elements[0].(*ast.DelimitedExpressionNode); !ok || len(elements) != 1 {
+		t.Errorf("elements = %#v, want one delimited expression", elements)
+	}
+}
+
+func TestBracketsOutsideOptionalArgument(t *testing.T) {
+	root, errs := parser.Parse(`[a] + \sqrt[n]{x}`)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	elements := root.(*ast.ExpressionNode).Elements
+	if len(elements) != 5 {
+		t.Fatalf("got %d elements, want 5", len(elements))
+	}
+	if d, ok := elements[2].(*ast.DelimiterNode); !ok || d.Kind != ast.RightBracket {
+		t.Errorf("elements[2] = %#v, want ]", elements[2])
+	}
+}
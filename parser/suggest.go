@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+// structuralCommands are the commands with arguments that parseCommand
+// handles itself.
+var structuralCommands = []string{
+	"frac", "dfrac", "tfrac", "cfrac", "genfrac", "sqrt", "binom", "dbinom", "tbinom", "left", "middle", "right",
+}
+
+// knownCommands is the sorted list of all command names the parser accepts.
+var knownCommands = func() []string {
+	var names []string
+	for _, table := range []map[string]bool{nonArgumentFunctions, operators, infixFractions} {
+		for name := range table {
+			names = append(names, name)
+		}
+	}
+	for _, table := range []map[string]string{greekLetters, symbolicOperators} {
+		for name := range table {
+			if isLetters(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	for name := range delimiterCommands {
+		if isLetters(name) {
+			names = append(names, name)
+		}
+	}
+	for name := range sizedDelimiters {
+		names = append(names, name)
+	}
+	names = append(names, structuralCommands...)
+	sort.Strings(names)
+	return names
+}()
+
+// suggest returns the commands of the profile closest to the unknown
+// command name, best first. Only commands within a small edit distance
+// qualify: one edit for names of up to four letters, two for longer ones.
+// A known command is not suggested for itself where it cannot be used.
+func suggest(name string, profile Profile) []string {
+	if len(name) < 2 {
+		return nil
+	}
+	limit := 1
+	if len(name) > 4 {
+		limit = 2
+	}
+
+	best, found := limit+1, []string(nil)
+	for _, known := range knownCommands {
+		if known == name || !profile.HasCommand(known) {
+			continue
+		}
+		d := editDistance(name, known)
+		switch {
+		case d < best:
+			best, found = d, []string{known}
+		case d == best:
+			found = append(found, known)
+		}
+	}
+	if len(found) > 3 {
+		found = found[:3]
+	}
+	return found
+}
+
+// editDistance returns the optimal string alignment distance of a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters that turn a into b. Transpositions count once, so
+// "aplha" is one edit away from "alpha".
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// unsupportedCommand reports an unknown command together with replacements
+// for the commands it is likely a misspelling of, and returns it as an
+// error node.
+func (p *Parser) unsupportedCommand(t tokenizer.Token) ast.Node {
+	e := ParseError{
+		Code:     ErrUnsupportedCommand,
+		Severity: p.profile.UnknownCommands,
+		Message:  "unsupported command: \\" + t.Value,
+		Pos:      t.Pos,
+		End:      t.End(),
+	}
+	for _, name := range suggest(t.Value, p.profile) {
+		e.Fixes = append(e.Fixes, Fix{
+			Message: "did you mean \\" + name + "?",
+			Edits:   []Edit{{Pos: t.Pos, End: t.End(), NewText: "\\" + name}},
+		})
+	}
+	p.report(e)
+	return &ast.ErrorNode{Start: t.Pos, Stop: t.End(), Message: e.Message}
+}
+
+// checkFunctionNames warns about function names such as "sin" written as
+// plain letters, which LaTeX typesets as the product s·i·n in italics.
+func (p *Parser) checkFunctionNames() {
+	for i := 0; i < len(p.tokens); {
+		j := i
+		for j < len(p.tokens) && p.tokens[j].Type == tokenizer.SYMBOL && isLetters(p.tokens[j].Value) &&
+			(j == i || p.tokens[j].Pos == p.tokens[j-1].End()) {
+			j++
+		}
+		if j == i {
+			i++
+			continue
+		}
+
+		word := ""
+		for _, t := range p.tokens[i:j] {
+			word += t.Value
+		}
+		if j-i > 1 && (isNonArgumentFunction(word) || isOperator(word)) {
+			pos := p.tokens[i].Pos
+			p.report(ParseError{
+				Code:     ErrMissingBackslash,
+				Severity: Warning,
+				Message:  word + " is typeset as a product of variables",
+				Pos:      pos,
+				End:      p.tokens[j-1].End(),
+				Fixes:    []Fix{insert("use \\"+word, pos, "\\")},
+			})
+		}
+		i = j
+	}
+}
+
+func isLetters(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,83 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+)
+
+func TestSuggestions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{`\aplha`, []string{`\alpha`}},
+		{`\fracc{1}{2}`, []string{`\frac{1}{2}`}},
+		{`\lamda`, []string{`\lambda`}},
+		{`\sqtr{x}`, []string{`\sqrt{x}`}},
+		{`\lfoor x`, []string{`\lfloor x`}},
+		{`\xyzzy`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, errs := parser.Parse(tt.input)
+			if len(errs) == 0 || errs[0].Code != parser.ErrUnsupportedCommand {
+				t.Fatalf("errors = %v, want unsupported command", errs)
+			}
+			var got []string
+			for _, fix := range errs[0].Fixes {
+				got = append(got, parser.ApplyFix(tt.input, fix))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fixed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoSelfSuggestion(t *testing.T) {
+	for _, input := range []string{`\lfloor x \rfloor`, `\langle`, `\lbrace`, `\vert`, `\uparrow`, `\backslash`} {
+		_, errs := parser.Parse(input)
+		for _, e := range errs {
+			for _, fix := range e.Fixes {
+				if parser.ApplyFix(input, fix) == input {
+					t.Errorf("%s: %s suggests the command itself", input, e.Message)
+				}
+			}
+		}
+	}
+}
+
+func TestMissingBackslash(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // fixed input, or "" for no warning
+	}{
+		{`sin x`, `\sin x`},
+		{`2 + log(x)`, `2 + \log(x)`},
+		{`\sin x`, ""},
+		{`s i n`, ""},
+		{`sinx`, ""},
+		{`x`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, errs := parser.Parse(tt.input)
+			var got string
+			for _, e := range errs {
+				if e.Code == parser.ErrMissingBackslash {
+					if e.Severity != parser.Warning {
+						t.Errorf("severity = %s, want warning", e.Severity)
+					}
+					got = parser.ApplyFix(tt.input, e.Fixes[0])
+				}
+			}
+			if got != tt.want {
+				t.Errorf("fixed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
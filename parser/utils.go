@@ -25,26 +25,71 @@ func (p *Parser) next() tokenizer.Token {
 
 func (p *Parser) parseGroupedStrict() ast.Node {
 	if p.peek().Type != tokenizer.LBRACE {
-		p.addError("expected '{'", p.peek().Pos)
+		p.expectedBrace()
 		return nil
 	}
 
-	p.next() // consume '{'
+	open := p.next() // consume '{'
 
-	// Parse the expression inside the braces
+	// Parse the expression inside the braces, where ']' closes nothing
+	optional := p.optional
+	p.optional = 0
 	expr := p.parseExpression()
+	p.optional = optional
+	expr.Start = open.Pos
 
+	expr.Stop = p.closeGroup(open)
+	return expr
+}
+
+// closeGroup consumes the '}' of the group opened by open and returns the
+// position after it. A missing '}' is reported, and the group ends at the
+// synchronization point.
+func (p *Parser) closeGroup(open tokenizer.Token) int {
 	if p.peek().Type != tokenizer.RBRACE {
-		p.addError("expected '}'", p.peek().Pos)
+		pos := p.peek().Pos
+		p.report(ParseError{
+			Code:    ErrUnclosedGroup,
+			Message: "expected '}'",
+			Pos:     pos,
+			End:     pos,
+			Related: []Related{{Message: "group opened here", Pos: open.Pos, End: open.End()}},
+			Fixes:   []Fix{insert("insert '}'", pos, "}")},
+		})
+		return p.lastEnd()
 	}
-	p.next() // consume '}'
-	return expr
+	return p.next().End() // consume '}'
+}
+
+// expectedBrace reports a missing '{'. A single symbol, digit or command,
+// which TeX would take as the argument, is suggested to be put in braces.
+func (p *Parser) expectedBrace() {
+	t := p.peek()
+	e := ParseError{Code: ErrExpectedBrace, Message: "expected '{'", Pos: t.Pos, End: t.End()}
+	switch t.Type {
+	case tokenizer.SYMBOL, tokenizer.NUMBER, tokenizer.COMMAND:
+		end := t.End()
+		if t.Type == tokenizer.NUMBER {
+			end = t.Pos + 1 // only the first digit is the argument
+		}
+		e.Fixes = []Fix{{Message: "put the argument in braces", Edits: []Edit{
+			{Pos: t.Pos, End: t.Pos, NewText: "{"},
+			{Pos: end, End: end, NewText: "}"},
+		}}}
+	}
+	p.report(e)
 }
 
 func (p *Parser) parseGroupedOrSingle() ast.Node {
 	if p.peek().Type == tokenizer.LBRACE {
 		return p.parseGroupedStrict()
 	}
+	// An unbraced argument nests like a group, as in \sqrt\sqrt x
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		return p.tooDeep()
+	}
+	p.depth++
+	defer func() { p.depth-- }()
 	return p.parseNode(HIGHEST)
 }
 
@@ -53,18 +98,18 @@ func (p *Parser) parseLimits() (ast.Node, ast.Node) {
 	for {
 		switch p.peek().Type {
 		case tokenizer.SUPERSCRIPT:
-			p.next()
+			t := p.next()
 			if upper != nil {
-				p.addError("duplicate upper limit", p.peek().Pos)
+				p.addError(ErrDuplicateScript, "duplicate upper limit", t.Pos, t.End())
 			}
-			upper = p.parseGroupedOrSingle()
+			upper = p.parseLimit(t)
 
 		case tokenizer.SUBSCRIPT:
-			p.next()
+			t := p.next()
 			if lower != nil {
-				p.addError("duplicate lower limit", p.peek().Pos)
+				p.addError(ErrDuplicateScript, "duplicate lower limit", t.Pos, t.End())
 			}
-			lower = p.parseGroupedOrSingle()
+			lower = p.parseLimit(t)
 
 		default:
 			return lower, upper
@@ -72,6 +117,25 @@ func (p *Parser) parseLimits() (ast.Node, ast.Node) {
 	}
 }
 
+// parseLimit parses the limit after the script token t. A missing limit is
+// kept as an error node spanning t.
+func (p *Parser) parseLimit(t tokenizer.Token) ast.Node {
+	if limit := p.parseGroupedOrSingle(); limit != nil {
+		return limit
+	}
+	msg := p.missingAfter("'" + t.Value + "'")
+	return &ast.ErrorNode{Start: t.Pos, Stop: p.lastEnd(), Message: msg}
+}
+
+// missingAfter reports that the argument of what is missing at the next
+// token, which closes the expression, and returns the message.
+func (p *Parser) missingAfter(what string) string {
+	t := p.peek()
+	msg := "expected argument after " + what + " before " + describe(t)
+	p.addError(ErrMissingArgument, msg, t.Pos, t.Pos)
+	return msg
+}
+
 // parseOptionalArgument parses an optional argument enclosed in square brackets.
 // Used for commands like \sqrt[n]{x} where [n] is an optional index.
 // Returns nil if there is no optional argument.
@@ -81,17 +145,29 @@ func (p *Parser) parseOptionalArgument() ast.Node {
 		return nil // No optional argument
 	}
 
-	p.next() // consume '['
+	open := p.next() // consume '['
 
 	// Parse the expression inside the brackets
+	p.optional++
 	expr := p.parseExpression()
+	p.optional--
+	expr.Start = open.Pos
 
 	// Check for closing bracket
 	if p.peek().Type != tokenizer.DELIMITER || p.peek().Value != "]" {
-		p.addError("expected closing ']' for optional argument", p.peek().Pos)
+		pos := p.peek().Pos
+		p.report(ParseError{
+			Code:    ErrUnclosedOptional,
+			Message: "expected closing ']' for optional argument",
+			Pos:     pos,
+			End:     pos,
+			Related: []Related{{Message: "optional argument opened here", Pos: open.Pos, End: open.End()}},
+			Fixes:   []Fix{insert("insert ']'", pos, "]")},
+		})
 		// Even if there's an error, we'll return what we parsed so far
+		expr.Stop = p.lastEnd()
 	} else {
-		p.next() // consume ']'
+		expr.Stop = p.next().End() // consume ']'
 	}
 
 	return expr
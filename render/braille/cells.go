@@ -0,0 +1,46 @@
+package braille
+
+import "strings"
+
+// brailleASCII lists the North American Braille ASCII characters in the
+// order of their dot patterns: index i has dot k raised if bit k-1 of i
+// is set. This is also the order of the Unicode braille block.
+const brailleASCII = " A1B'K2L@CIF/MSP\"E3H9O6R^DJG>NTQ,*5<-U8V.%[$+X!&;:4\\0Z7(_?W]#Y)="
+
+// ToUnicode converts Braille ASCII to Unicode braille patterns (U+2800–U+283F).
+// Lowercase letters are treated like uppercase ones; characters outside
+// Braille ASCII are kept unchanged.
+func ToUnicode(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if i := strings.IndexRune(brailleASCII, r); i >= 0 {
+			sb.WriteRune(rune(0x2800 + i))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// fullCell is used for characters without a transcription rule.
+const fullCell = "="
+
+// letterCell returns the Braille ASCII cell of a Latin letter.
+func letterCell(r rune) string {
+	if r >= 'a' && r <= 'z' {
+		r -= 'a' - 'A'
+	}
+	return string(r)
+}
+
+// greekLetters maps Greek letters to the Latin letter cell that follows
+// the Greek indicator, shared by Nemeth and UEB.
+var greekLetters = map[rune]string{
+	'α': "A", 'β': "B", 'γ': "G", 'δ': "D", 'ε': "E", 'ζ': "Z",
+	'η': ":", 'θ': "?", 'ι': "I", 'κ': "K", 'λ': "L", 'μ': "M",
+	'ν': "N", 'ξ': "X", 'ο': "O", 'π': "P", 'ρ': "R", 'σ': "S",
+	'τ': "T", 'υ': "U", 'φ': "F", 'χ': "&", 'ψ': "Y", 'ω': "W",
+}
@@ -0,0 +1,125 @@
+package braille
+
+// Code selects the braille code used for transcription.
+type Code int
+
+const (
+	// Nemeth is the Nemeth Braille Code for Mathematics.
+	Nemeth Code = iota
+	// UEB is Unified English Braille with its technical material rules.
+	UEB
+)
+
+// table holds the indicators and symbol cells of one braille code. All
+// cells are written in Braille ASCII. Relations carry their surrounding
+// spaces where the code spaces them on the baseline.
+type table struct {
+	numeric string // numeric indicator
+	capital string // capital letter indicator
+	greek   string // Greek letter indicator
+	comma   string
+
+	operators  map[string]string
+	delimiters map[string]string // by glyph, see ast.Delimiter.Glyph
+	symbols    map[rune]string
+	bigOps     map[string]string
+}
+
+var nemeth = &table{
+	numeric: "#",
+	capital: ",",
+	greek:   ".",
+	comma:   ",",
+
+	operators: map[string]string{
+		"+": "+",
+		"-": "-",
+		"*": "*",
+		"·": "*",
+		"×": "@*",
+		"/": "_/",
+		"÷": "./",
+		"±": "+-",
+		"∓": "-+",
+		"∪": ".+",
+		"∩": ".%",
+		"=": " .K ",
+		"<": " \"K ",
+		">": " .1 ",
+		"≤": " \"K: ",
+		"≥": " .1: ",
+		"≠": " /.K ",
+		"≈": " @:@: ",
+		"∈": " @E ",
+		"→": " $33O ",
+	},
+	delimiters: map[string]string{
+		"(": "(",
+		")": ")",
+		"[": "@(",
+		"]": "@)",
+		"{": ".(",
+		"}": ".)",
+		"|": "\\",
+		"‖": "\\\\",
+		"/": "_/",
+	},
+	symbols: map[rune]string{
+		'∞':  "=",
+		'\'': "'",
+	},
+	bigOps: map[string]string{
+		"sum":  ".,S",
+		"prod": ".,P",
+		"int":  "!",
+		"lim":  "LIM",
+	},
+}
+
+var ueb = &table{
+	numeric: "#",
+	capital: ",",
+	greek:   ".",
+	comma:   "1",
+
+	operators: map[string]string{
+		"+": "\"6",
+		"-": "\"-",
+		"*": "\"8",
+		"·": "\"4",
+		"×": "\"8",
+		"/": "_/",
+		"÷": "\"/",
+		"±": "_6",
+		"=": " \"7 ",
+		"<": " @< ",
+		">": " @> ",
+		"≤": " _@< ",
+		"≥": " _@> ",
+		"≠": " \"7@: ",
+		"≈": " @9 ",
+		"∈": " ^E ",
+		"→": " \\O ",
+	},
+	delimiters: map[string]string{
+		"(": "\"<",
+		")": "\">",
+		"[": ".<",
+		"]": ".>",
+		"{": "_<",
+		"}": "_>",
+		"|": "_\\",
+		"‖": "_\\_\\",
+		"/": "_/",
+	},
+	symbols: map[rune]string{
+		'∞':  ",=",
+		'\'': "7",
+	},
+	bigOps: map[string]string{
+		"sum":  ",.S",
+		"prod": ",.P",
+		"int":  "!",
+		"lim":  "LIM",
+	},
+}
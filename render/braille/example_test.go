@@ -0,0 +1,42 @@
+package braille_test
+
+import (
+	"fmt"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/braille"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func ExampleRender() {
+	for _, input := range []string{
+		`\frac{a}{b}`,
+		`x^2 + y_1`,
+		`\sqrt[3]{x}`,
+		`\alpha = 2`,
+		`\sum_{i=1}^n i`,
+		`\frac{\frac{1}{2}}{x}`,
+	} {
+		root, _ := parser.New(tokenizer.Tokenize(input)).Parse()
+		fmt.Printf("%-14s %s\n", braille.Render(root, braille.Nemeth, braille.ASCII),
+			braille.Render(root, braille.UEB, braille.ASCII))
+	}
+
+	// Output:
+	// ?A/B#          ;(A./B;)
+	// X^2"+Y1        X9#B"6Y5#A
+	// <3>X]          %9#CX+
+	// .A .K #2       .A "7 #B
+	// ".,S%I.K1<N]I  ,.S5<I"7#A>9NI
+	// ,??1/2#,/X,#   ;(#A/B./X;)
+}
+
+func ExampleToUnicode() {
+	root, _ := parser.New(tokenizer.Tokenize(`x^2`)).Parse()
+	fmt.Println(braille.Render(root, braille.Nemeth, braille.Unicode))
+	fmt.Println(braille.ToUnicode("X^2"))
+
+	// Output:
+	// ⠭⠘⠆
+	// ⠭⠘⠆
+}
@@ -0,0 +1,460 @@
+// Package braille transcribes the AST into Nemeth Code or Unified English
+// Braille (UEB) for refreshable braille displays and embossers.
+//
+// The transcription is produced in North American Braille ASCII and can be
+// returned as is, e.g. for BRF files, or as Unicode braille patterns.
+// Fractions, radicals, level changes for scripts and Greek letters follow
+// the rules of the selected code; symbols without a rule are written as a
+// full cell.
+package braille
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/neox5/texmax/ast"
+)
+
+// Output selects the character set of the transcription.
+type Output int
+
+const (
+	// Unicode writes Unicode braille patterns (U+2800–U+283F).
+	Unicode Output = iota
+	// ASCII writes North American Braille ASCII.
+	ASCII
+)
+
+// Render returns the braille transcription of node.
+func Render(node ast.Node, code Code, out Output) string {
+	if node == nil {
+		return ""
+	}
+	t := NewTranscriber(code)
+	ast.Walk(t, node)
+	if out == ASCII {
+		return t.String()
+	}
+	return ToUnicode(t.String())
+}
+
+// Transcriber is an ast.Visitor that writes the Braille ASCII cells of
+// each node.
+type Transcriber struct {
+	code  Code
+	table *table
+	sb    strings.Builder
+
+	// Nemeth level indicator in effect, e.g. "^" for superscripts and
+	// ";^" for a superscript to a subscript
+	level string
+	// level indicator to restate before the next cell, if any
+	pending string
+	// depth of scripts and modifiers; relations are unspaced inside them
+	depth int
+
+	numeric   bool // the next number needs the numeric indicator (Nemeth)
+	lastDigit bool // the last cell written was a digit (UEB)
+}
+
+// NewTranscriber creates a new Transcriber for code.
+func NewTranscriber(code Code) *Transcriber {
+	t := &Transcriber{code: code, table: nemeth, numeric: true}
+	if code == UEB {
+		t.table = ueb
+	}
+	return t
+}
+
+// String returns the Braille ASCII written so far, without leading and
+// trailing blank cells.
+func (t *Transcriber) String() string {
+	return strings.TrimSpace(t.sb.String())
+}
+
+// write appends cells, restating a pending level indicator first.
+func (t *Transcriber) write(cells string) {
+	if cells == "" {
+		return
+	}
+	if t.pending != "" {
+		t.sb.WriteString(t.pending)
+		t.pending = ""
+	}
+	t.sb.WriteString(cells)
+	t.numeric = strings.HasSuffix(cells, " ")
+	t.lastDigit = false
+}
+
+// transcribe writes n at a nested depth.
+func (t *Transcriber) transcribe(n ast.Node) {
+	if n == nil {
+		return
+	}
+	t.depth++
+	n.Accept(t)
+	t.depth--
+}
+
+// Visit methods for container nodes
+func (t *Transcriber) VisitExpressionNode(node *ast.ExpressionNode) {
+	for _, element := range node.Elements {
+		element.Accept(t)
+	}
+}
+
+func (t *Transcriber) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	node.LeftDelimiter.Accept(t)
+	if node.Content != nil {
+		node.Content.Accept(t)
+	}
+	node.RightDelimiter.Accept(t)
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (t *Transcriber) VisitErrorNode(node *ast.ErrorNode) {
+	t.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (t *Transcriber) VisitSymbolNode(node *ast.SymbolNode) {
+	for _, r := range node.Value {
+		t.symbol(r)
+	}
+}
+
+// symbol writes a single letter or symbol.
+func (t *Transcriber) symbol(r rune) {
+	lower := unicode.ToLower(r)
+	capital := ""
+	if r != lower {
+		capital = t.table.capital
+	}
+
+	switch {
+	case lower >= 'a' && lower <= 'z':
+		// In UEB the letters a to j directly after a digit would be read
+		// as digits and need the grade 1 indicator
+		if t.code == UEB && t.lastDigit && lower <= 'j' && capital == "" {
+			t.write(";")
+		}
+		t.write(capital + letterCell(lower))
+	case greekLetters[lower] != "":
+		if t.code == Nemeth {
+			t.write(t.table.greek + capital + greekLetters[lower])
+		} else {
+			t.write(capital + t.table.greek + greekLetters[lower])
+		}
+	case t.table.symbols[r] != "":
+		t.write(t.table.symbols[r])
+	default:
+		t.write(fullCell)
+	}
+}
+
+func (t *Transcriber) VisitNumberNode(node *ast.NumberNode) {
+	var cells strings.Builder
+	if t.code == UEB || t.numeric {
+		cells.WriteString(t.table.numeric)
+	}
+	for _, r := range node.Value {
+		cells.WriteString(t.digit(r))
+	}
+	t.write(cells.String())
+	t.lastDigit = true
+}
+
+// digit returns the cell of a digit or decimal point. Nemeth uses the
+// lower cells, UEB the letters a to j.
+func (t *Transcriber) digit(r rune) string {
+	switch {
+	case r == '.' && t.code == Nemeth:
+		return "."
+	case r == '.':
+		return "4"
+	case t.code == Nemeth:
+		return string(r)
+	case r == '0':
+		return "J"
+	}
+	return string('A' + r - '1')
+}
+
+func (t *Transcriber) VisitOperatorNode(node *ast.OperatorNode) {
+	cells, ok := t.table.operators[node.Value]
+	if !ok {
+		if node.Value == "," {
+			cells = t.table.comma
+		} else {
+			cells = fullCell
+		}
+	}
+
+	if strings.HasPrefix(cells, " ") {
+		if t.depth > 0 {
+			cells = strings.TrimSpace(cells)
+		} else if t.code == Nemeth {
+			// The space before a comparison sign returns to the baseline
+			t.pending = ""
+		}
+	}
+
+	// A minus sign before a number keeps the Nemeth numeric indicator
+	numeric := t.numeric
+	t.write(cells)
+	if node.Value == "-" {
+		t.numeric = numeric
+	}
+}
+
+func (t *Transcriber) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	t.write(strings.ToUpper(node.Name) + " ")
+}
+
+func (t *Transcriber) VisitSpaceNode(node *ast.SpaceNode) {}
+
+func (t *Transcriber) VisitDelimiterNode(node *ast.DelimiterNode) {
+	if node.Kind == ast.NoDelimiter {
+		return
+	}
+	if cells, ok := t.table.delimiters[node.Kind.Glyph()]; ok {
+		t.write(cells)
+		return
+	}
+	t.write(fullCell)
+}
+
+// Visit methods for composite nodes
+func (t *Transcriber) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	node.Base.Accept(t)
+	t.script("^", "9", node.Exponent)
+}
+
+func (t *Transcriber) VisitSubscriptNode(node *ast.SubscriptNode) {
+	node.Base.Accept(t)
+	// Nemeth writes a numeric subscript to a letter without indicator
+	if t.code == Nemeth && t.level == "" && isLetter(node.Base) {
+		if num, ok := unwrap(node.Subscript).(*ast.NumberNode); ok && !strings.Contains(num.Value, ".") {
+			t.write(num.Value)
+			return
+		}
+	}
+	t.script(";", "5", node.Subscript)
+}
+
+// script writes n as a superscript or subscript. Nemeth changes the level
+// and returns to the enclosing level before the next cell; UEB applies the
+// indicator to the next item, grouping anything longer.
+func (t *Transcriber) script(nemethIndicator, uebIndicator string, n ast.Node) {
+	if t.code == UEB {
+		t.write(uebIndicator)
+		t.group(n)
+		return
+	}
+
+	saved := t.level
+	t.level = saved + nemethIndicator
+	t.write(t.level)
+	t.numeric = false
+	t.transcribe(n)
+	t.level = saved
+	t.pending = saved
+	if saved == "" {
+		t.pending = "\""
+	}
+}
+
+// group writes n, enclosed in UEB grouping indicators unless it is a
+// single item.
+func (t *Transcriber) group(n ast.Node) {
+	if isItem(n) {
+		t.transcribe(n)
+		return
+	}
+	t.write("<")
+	t.transcribe(n)
+	t.write(">")
+}
+
+func (t *Transcriber) VisitFractionNode(node *ast.FractionNode) {
+	if t.code == UEB {
+		// Numeric fractions are written with the digits of the
+		// denominator continuing the number
+		num, ok1 := unwrap(node.Numerator).(*ast.NumberNode)
+		den, ok2 := unwrap(node.Denominator).(*ast.NumberNode)
+		if ok1 && ok2 && isInteger(num.Value) && isInteger(den.Value) {
+			var cells strings.Builder
+			cells.WriteString(t.table.numeric)
+			for _, r := range num.Value + "/" + den.Value {
+				if r == '/' {
+					cells.WriteString("/")
+				} else {
+					cells.WriteString(t.digit(r))
+				}
+			}
+			t.write(cells.String())
+			t.lastDigit = true
+			return
+		}
+		t.write(";(")
+		t.transcribe(node.Numerator)
+		t.write("./")
+		t.transcribe(node.Denominator)
+		t.write(";)")
+		return
+	}
+
+	// Nemeth uses complex fraction indicators for fractions containing
+	// fractions
+	prefix := ""
+	if containsFraction(node.Numerator) || containsFraction(node.Denominator) {
+		prefix = ","
+	}
+	t.write(prefix + "?")
+	t.transcribe(node.Numerator)
+	t.write(prefix + "/")
+	t.transcribe(node.Denominator)
+	t.write(prefix + "#")
+}
+
+func (t *Transcriber) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	op, ok := t.table.bigOps[node.Operator]
+	if !ok {
+		op = strings.ToUpper(node.Operator)
+	}
+	if node.LowerLimit == nil && node.UpperLimit == nil {
+		t.write(op)
+		return
+	}
+
+	if t.code == UEB {
+		t.write(op)
+		if node.LowerLimit != nil {
+			t.write("5")
+			t.group(node.LowerLimit)
+		}
+		if node.UpperLimit != nil {
+			t.write("9")
+			t.group(node.UpperLimit)
+		}
+		return
+	}
+
+	// Nemeth writes integral limits as scripts and the limits of sums,
+	// products and lim as a modified expression: multipurpose indicator,
+	// operator, directly-under and directly-over indicators, termination
+	if node.Operator == "int" {
+		t.write(op)
+		if node.LowerLimit != nil {
+			t.script(";", "", node.LowerLimit)
+		}
+		if node.UpperLimit != nil {
+			t.script("^", "", node.UpperLimit)
+		}
+		return
+	}
+	t.write("\"" + op)
+	if node.LowerLimit != nil {
+		t.write("%")
+		t.numeric = false
+		t.transcribe(node.LowerLimit)
+	}
+	if node.UpperLimit != nil {
+		t.write("<")
+		t.numeric = false
+		t.transcribe(node.UpperLimit)
+	}
+	t.write("]")
+}
+
+func (t *Transcriber) VisitSqrtNode(node *ast.SqrtNode) {
+	if t.code == UEB {
+		t.write("%")
+		if node.Index != nil {
+			t.write("9")
+			t.group(node.Index)
+		}
+		t.transcribe(node.Radicand)
+		t.write("+")
+		return
+	}
+
+	// Nemeth writes the index of a radical before the radical sign
+	if node.Index != nil {
+		t.write("<")
+		t.numeric = false
+		t.transcribe(node.Index)
+	}
+	t.write(">")
+	t.numeric = false
+	t.transcribe(node.Radicand)
+	t.write("]")
+}
+
+// VisitBinomNode writes a binomial coefficient as a parenthesised pair.
+func (t *Transcriber) VisitBinomNode(node *ast.BinomNode) {
+	t.write(t.table.delimiters["("])
+	t.numeric = false
+	t.transcribe(node.Upper)
+	t.write(t.table.comma)
+	t.numeric = false
+	t.transcribe(node.Lower)
+	t.write(t.table.delimiters[")"])
+}
+
+func (t *Transcriber) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	node.Delimiter.Accept(t)
+}
+
+// unwrap returns the single element of an expression, or n itself.
+func unwrap(n ast.Node) ast.Node {
+	if expr, ok := n.(*ast.ExpressionNode); ok && len(expr.Elements) == 1 {
+		return unwrap(expr.Elements[0])
+	}
+	return n
+}
+
+// isItem reports whether n is a single letter, Greek letter or number.
+func isItem(n ast.Node) bool {
+	switch node := unwrap(n).(type) {
+	case *ast.NumberNode:
+		return true
+	case *ast.SymbolNode:
+		return len([]rune(node.Value)) == 1
+	}
+	return false
+}
+
+func isLetter(n ast.Node) bool {
+	sym, ok := unwrap(n).(*ast.SymbolNode)
+	return ok && len([]rune(sym.Value)) == 1 && unicode.IsLetter([]rune(sym.Value)[0])
+}
+
+func isInteger(s string) bool {
+	return s != "" && !strings.Contains(s, ".")
+}
+
+// containsFraction reports whether n contains a fraction at any depth.
+func containsFraction(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.FractionNode:
+		return true
+	case *ast.ExpressionNode:
+		for _, element := range node.Elements {
+			if containsFraction(element) {
+				return true
+			}
+		}
+	case *ast.DelimitedExpressionNode:
+		return containsFraction(node.Content)
+	case *ast.SuperscriptNode:
+		return containsFraction(node.Base) || containsFraction(node.Exponent)
+	case *ast.SubscriptNode:
+		return containsFraction(node.Base) || containsFraction(node.Subscript)
+	case *ast.SqrtNode:
+		return containsFraction(node.Index) || containsFraction(node.Radicand)
+	case *ast.BinomNode:
+		return containsFraction(node.Upper) || containsFraction(node.Lower)
+	}
+	return false
+}
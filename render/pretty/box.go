@@ -0,0 +1,145 @@
+package pretty
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// box is a rectangular block of text with a baseline. All lines have the
+// same width in runes; the baseline is the row that lines up with the
+// surrounding text.
+type box struct {
+	lines    []string
+	baseline int
+}
+
+// text creates a single-line box.
+func text(s string) box {
+	return box{lines: []string{s}}
+}
+
+func (b box) width() int {
+	if len(b.lines) == 0 {
+		return 0
+	}
+	return utf8.RuneCountInString(b.lines[0])
+}
+
+func (b box) height() int {
+	return len(b.lines)
+}
+
+// descent returns the number of rows below the baseline.
+func (b box) descent() int {
+	return b.height() - b.baseline - 1
+}
+
+// String joins the lines, dropping trailing spaces.
+func (b box) String() string {
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blank returns a box of spaces.
+func blank(width, height int) box {
+	lines := make([]string, height)
+	for i := range lines {
+		lines[i] = strings.Repeat(" ", width)
+	}
+	return box{lines: lines}
+}
+
+// padRight pads s with spaces to width runes.
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// center pads s with spaces on both sides to width runes.
+func center(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return s
+	}
+	left := (width - n) / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-n-left)
+}
+
+// beside places a and b next to each other so that row aRow of a lines up
+// with row bRow of b. The baseline of the result follows a.
+func beside(a, b box, aRow, bRow int) box {
+	shiftA, shiftB := 0, 0
+	if aRow < bRow {
+		shiftA = bRow - aRow
+	} else {
+		shiftB = aRow - bRow
+	}
+	height := max(shiftA+a.height(), shiftB+b.height())
+	wa, wb := a.width(), b.width()
+
+	lines := make([]string, height)
+	for i := range lines {
+		left := strings.Repeat(" ", wa)
+		if j := i - shiftA; j >= 0 && j < a.height() {
+			left = a.lines[j]
+		}
+		right := strings.Repeat(" ", wb)
+		if j := i - shiftB; j >= 0 && j < b.height() {
+			right = b.lines[j]
+		}
+		lines[i] = left + right
+	}
+	return box{lines: lines, baseline: a.baseline + shiftA}
+}
+
+// hcat joins boxes horizontally along their baselines.
+func hcat(boxes ...box) box {
+	if len(boxes) == 0 {
+		return text("")
+	}
+	out := boxes[0]
+	for _, b := range boxes[1:] {
+		out = beside(out, b, out.baseline, b.baseline)
+	}
+	return out
+}
+
+// vstack stacks boxes vertically, centering them horizontally. The
+// baseline of the result is the row baseline of box index at.
+func vstack(at int, boxes ...box) box {
+	width := 0
+	for _, b := range boxes {
+		width = max(width, b.width())
+	}
+	var lines []string
+	baseline := 0
+	for i, b := range boxes {
+		if i == at {
+			baseline = len(lines) + b.baseline
+		}
+		for _, line := range b.lines {
+			lines = append(lines, center(line, width))
+		}
+	}
+	return box{lines: lines, baseline: baseline}
+}
+
+// column builds a single-column box of height rows from a top glyph, a
+// repeated middle glyph and a bottom glyph.
+func column(top, middle, bottom string, height int) box {
+	if height <= 1 {
+		return text(middle)
+	}
+	lines := make([]string, height)
+	for i := range lines {
+		lines[i] = middle
+	}
+	lines[0] = top
+	lines[height-1] = bottom
+	return box{lines: lines}
+}
@@ -0,0 +1,174 @@
+package pretty
+
+import "github.com/neox5/texmax/ast"
+
+// Charset selects the characters used for drawing.
+type Charset int
+
+const (
+	// Unicode draws with box-drawing and mathematical code points.
+	Unicode Charset = iota
+	// ASCII restricts the output to 7-bit ASCII.
+	ASCII
+)
+
+// fence describes how a delimiter is drawn. Tall delimiters use top, fill
+// and bottom; middle, if set, replaces the fill on the baseline row.
+type fence struct {
+	single string
+	top    string
+	fill   string
+	bottom string
+	middle string
+}
+
+// glyphs holds the drawing characters of one charset.
+type glyphs struct {
+	fractionBar string
+	overbar     string
+	radical     string // radical sign for single-line radicands
+	radicalUp   string // rising stroke of tall radicals
+	radicalTick string // bottom of tall radicals
+	fences      map[ast.Delimiter]fence
+	bigOps      map[string]box
+	operators   map[string]string
+}
+
+var unicodeGlyphs = glyphs{
+	fractionBar: "─",
+	overbar:     "_",
+	radical:     "√",
+	radicalUp:   "╱",
+	radicalTick: "╲╱",
+	fences: map[ast.Delimiter]fence{
+		ast.NoDelimiter:       {"", "", "", "", ""},
+		ast.LeftParen:         {"(", "⎛", "⎜", "⎝", ""},
+		ast.RightParen:        {")", "⎞", "⎟", "⎠", ""},
+		ast.LeftBracket:       {"[", "⎡", "⎢", "⎣", ""},
+		ast.RightBracket:      {"]", "⎤", "⎥", "⎦", ""},
+		ast.LeftBrace:         {"{", "⎧", "⎪", "⎩", "⎨"},
+		ast.RightBrace:        {"}", "⎫", "⎪", "⎭", "⎬"},
+		ast.LeftAngle:         {"⟨", " ", " ", " ", "⟨"},
+		ast.RightAngle:        {"⟩", " ", " ", " ", "⟩"},
+		ast.LeftFloor:         {"⌊", "⎢", "⎢", "⎣", ""},
+		ast.RightFloor:        {"⌋", "⎥", "⎥", "⎦", ""},
+		ast.LeftCeil:          {"⌈", "⎡", "⎢", "⎢", ""},
+		ast.RightCeil:         {"⌉", "⎤", "⎥", "⎥", ""},
+		ast.LeftGroup:         {"⟮", "⎛", "⎜", "⎝", ""},
+		ast.RightGroup:        {"⟯", "⎞", "⎟", "⎠", ""},
+		ast.LeftMoustache:     {"⎰", "⎧", "⎪", "⎭", ""},
+		ast.RightMoustache:    {"⎱", "⎫", "⎪", "⎩", ""},
+		ast.UpperLeftCorner:   {"⌜", "⌜", " ", " ", ""},
+		ast.UpperRightCorner:  {"⌝", "⌝", " ", " ", ""},
+		ast.LowerLeftCorner:   {"⌞", " ", " ", "⌞", ""},
+		ast.LowerRightCorner:  {"⌟", " ", " ", "⌟", ""},
+		ast.Vert:              {"|", "│", "│", "│", ""},
+		ast.LeftVert:          {"|", "│", "│", "│", ""},
+		ast.RightVert:         {"|", "│", "│", "│", ""},
+		ast.DoubleVert:        {"‖", "‖", "‖", "‖", ""},
+		ast.LeftDoubleVert:    {"‖", "‖", "‖", "‖", ""},
+		ast.RightDoubleVert:   {"‖", "‖", "‖", "‖", ""},
+		ast.Slash:             {"/", " ", " ", " ", "/"},
+		ast.Backslash:         {"\\", " ", " ", " ", "\\"},
+		ast.UpArrow:           {"↑", "↑", "│", "│", ""},
+		ast.DownArrow:         {"↓", "│", "│", "↓", ""},
+		ast.UpDownArrow:       {"↕", "↑", "│", "↓", ""},
+		ast.DoubleUpArrow:     {"⇑", "⇑", "‖", "‖", ""},
+		ast.DoubleDownArrow:   {"⇓", "‖", "‖", "⇓", ""},
+		ast.DoubleUpDownArrow: {"⇕", "⇑", "‖", "⇓", ""},
+	},
+	bigOps: map[string]box{
+		"sum":  {lines: []string{"⎲", "⎳"}, baseline: 1},
+		"prod": {lines: []string{"┬─┬", "│ │"}, baseline: 1},
+		"int":  {lines: []string{"⌠", "⎮", "⌡"}, baseline: 1},
+		"lim":  {lines: []string{"lim"}},
+	},
+	operators: map[string]string{},
+}
+
+var asciiGlyphs = glyphs{
+	fractionBar: "-",
+	overbar:     "_",
+	radical:     "\\/",
+	radicalUp:   "/",
+	radicalTick: "\\/",
+	fences: map[ast.Delimiter]fence{
+		ast.NoDelimiter:       {"", "", "", "", ""},
+		ast.LeftParen:         {"(", "/", "|", "\\", ""},
+		ast.RightParen:        {")", "\\", "|", "/", ""},
+		ast.LeftBracket:       {"[", "[", "[", "[", ""},
+		ast.RightBracket:      {"]", "]", "]", "]", ""},
+		ast.LeftBrace:         {"{", "/", "|", "\\", "<"},
+		ast.RightBrace:        {"}", "\\", "|", "/", ">"},
+		ast.LeftAngle:         {"<", " ", " ", " ", "<"},
+		ast.RightAngle:        {">", " ", " ", " ", ">"},
+		ast.LeftFloor:         {"|_", "| ", "| ", "|_", ""},
+		ast.RightFloor:        {"_|", " |", " |", "_|", ""},
+		ast.LeftCeil:          {"|^", "|^", "| ", "| ", ""},
+		ast.RightCeil:         {"^|", "^|", " |", " |", ""},
+		ast.LeftGroup:         {"(", "/", "|", "\\", ""},
+		ast.RightGroup:        {")", "\\", "|", "/", ""},
+		ast.LeftMoustache:     {"(", "/", "|", "/", ""},
+		ast.RightMoustache:    {")", "\\", "|", "\\", ""},
+		ast.UpperLeftCorner:   {"'", "'", " ", " ", ""},
+		ast.UpperRightCorner:  {"'", "'", " ", " ", ""},
+		ast.LowerLeftCorner:   {",", " ", " ", ",", ""},
+		ast.LowerRightCorner:  {",", " ", " ", ",", ""},
+		ast.Vert:              {"|", "|", "|", "|", ""},
+		ast.LeftVert:          {"|", "|", "|", "|", ""},
+		ast.RightVert:         {"|", "|", "|", "|", ""},
+		ast.DoubleVert:        {"||", "||", "||", "||", ""},
+		ast.LeftDoubleVert:    {"||", "||", "||", "||", ""},
+		ast.RightDoubleVert:   {"||", "||", "||", "||", ""},
+		ast.Slash:             {"/", " ", " ", " ", "/"},
+		ast.Backslash:         {"\\", " ", " ", " ", "\\"},
+		ast.UpArrow:           {"^", "^", "|", "|", ""},
+		ast.DownArrow:         {"v", "|", "|", "v", ""},
+		ast.UpDownArrow:       {"^", "^", "|", "v", ""},
+		ast.DoubleUpArrow:     {"^", "^", "||", "||", ""},
+		ast.DoubleDownArrow:   {"v", "||", "||", "v", ""},
+		ast.DoubleUpDownArrow: {"^", "^", "||", "v", ""},
+	},
+	bigOps: map[string]box{
+		"sum":  {lines: []string{"___", "\\  ", "/__"}, baseline: 1},
+		"prod": {lines: []string{"_____", " | | ", " | | "}, baseline: 1},
+		"int":  {lines: []string{" /", " |", "/ "}, baseline: 1},
+		"lim":  {lines: []string{"lim"}},
+	},
+	operators: map[string]string{
+		"·": "*",
+		"×": "x",
+		"÷": "/",
+		"±": "+-",
+		"∓": "-+",
+		"∗": "*",
+		"≤": "<=",
+		"≥": ">=",
+		"≠": "!=",
+		"≈": "~=",
+		"≡": "==",
+		"∼": "~",
+		"→": "->",
+		"←": "<-",
+		"⇒": "=>",
+		"⇐": "<=",
+		"⇔": "<=>",
+		"↦": "|->",
+	},
+}
+
+func (c Charset) glyphs() *glyphs {
+	if c == ASCII {
+		return &asciiGlyphs
+	}
+	return &unicodeGlyphs
+}
+
+// spacedOperators are surrounded by spaces when used as binary operators.
+var spacedOperators = map[string]bool{
+	"+": true, "-": true, "±": true, "∓": true,
+	"=": true, "<": true, ">": true, "≤": true, "≥": true, "≠": true,
+	"≈": true, "≡": true, "∼": true, "≃": true, "≅": true, "∝": true,
+	"≪": true, "≫": true, "∈": true, "∉": true, "⊂": true, "⊃": true,
+	"→": true, "←": true, "⇒": true, "⇐": true, "⇔": true, "↦": true,
+}
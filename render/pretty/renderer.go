@@ -0,0 +1,306 @@
+// Package pretty lays out the AST as two-dimensional text, with stacked
+// fractions, raised exponents, radical signs and big operators with their
+// limits above and below, similar to SymPy's pretty printer.
+package pretty
+
+import (
+	"strings"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+// Render returns the two-dimensional text layout of node.
+func Render(node ast.Node, charset Charset) string {
+	if node == nil {
+		return ""
+	}
+	r := NewRenderer(charset)
+	ast.Walk(r, node)
+	return r.String()
+}
+
+// Renderer is an ast.Visitor that computes a text box for each node.
+// Every Visit method sets result to the box of the visited node.
+type Renderer struct {
+	charset Charset
+	glyphs  *glyphs
+	result  box
+
+	// middle maps the \middle separators being laid out to the content
+	// of their delimited expression, to whose height they grow
+	middle map[*ast.DelimiterNode]box
+}
+
+// NewRenderer creates a new Renderer drawing with the given charset.
+func NewRenderer(charset Charset) *Renderer {
+	return &Renderer{
+		charset: charset,
+		glyphs:  charset.glyphs(),
+		result:  text(""),
+	}
+}
+
+// String returns the layout of the last visited node.
+func (r *Renderer) String() string {
+	return r.result.String()
+}
+
+// layout returns the box of n.
+func (r *Renderer) layout(n ast.Node) box {
+	if n == nil {
+		return text("")
+	}
+	n.Accept(r)
+	return r.result
+}
+
+// fence draws a delimiter with the given height and baseline.
+func (r *Renderer) fence(kind ast.Delimiter, height, baseline int) box {
+	f, ok := r.glyphs.fences[kind]
+	if !ok {
+		g := kind.Glyph()
+		f = fence{g, g, g, g, ""}
+	}
+	if height <= 1 {
+		return text(f.single)
+	}
+	b := column(f.top, f.fill, f.bottom, height)
+	if f.middle != "" {
+		b.lines[baseline] = f.middle
+	}
+	// Pad so that all rows have the same width
+	width := 0
+	for _, line := range b.lines {
+		width = max(width, len([]rune(line)))
+	}
+	for i, line := range b.lines {
+		b.lines[i] = padRight(line, width)
+	}
+	b.baseline = baseline
+	return b
+}
+
+// Visit methods for container nodes
+func (r *Renderer) VisitExpressionNode(node *ast.ExpressionNode) {
+	var boxes []box
+	for i, element := range node.Elements {
+		b := r.layout(element)
+
+		if i > 0 && needsSpace(node.Elements[i-1]) && !isOpeningParen(element) {
+			boxes = append(boxes, text(" "))
+		}
+
+		// Binary operators are spaced, unary ones are not
+		if op, ok := element.(*ast.OperatorNode); ok && spacedOperators[op.Value] {
+			if i > 0 && !isOperator(node.Elements[i-1]) {
+				b = hcat(text(" "), b, text(" "))
+			}
+		}
+		boxes = append(boxes, b)
+	}
+	r.result = hcat(boxes...)
+}
+
+func (r *Renderer) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	content := r.layout(node.Content)
+	if len(node.Middle) > 0 {
+		// Lay out the content again with the separators grown to it
+		saved := r.middle
+		r.middle = map[*ast.DelimiterNode]box{}
+		for _, m := range node.Middle {
+			r.middle[m] = content
+		}
+		content = r.layout(node.Content)
+		r.middle = saved
+	}
+	left := r.fence(delimiterKind(node.LeftDelimiter), content.height(), content.baseline)
+	right := r.fence(delimiterKind(node.RightDelimiter), content.height(), content.baseline)
+	r.result = hcat(left, content, right)
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (r *Renderer) VisitErrorNode(node *ast.ErrorNode) {
+	r.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (r *Renderer) VisitSymbolNode(node *ast.SymbolNode) {
+	if r.charset == ASCII {
+		if name, ok := parser.GreekLetterName(node.Value); ok {
+			r.result = text(name)
+			return
+		}
+	}
+	r.result = text(node.Value)
+}
+
+func (r *Renderer) VisitNumberNode(node *ast.NumberNode) {
+	r.result = text(node.Value)
+}
+
+func (r *Renderer) VisitOperatorNode(node *ast.OperatorNode) {
+	if op, ok := r.glyphs.operators[node.Value]; ok {
+		r.result = text(op)
+		return
+	}
+	r.result = text(node.Value)
+}
+
+func (r *Renderer) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	r.result = text(node.Name)
+}
+
+func (r *Renderer) VisitSpaceNode(node *ast.SpaceNode) {
+	r.result = text(" ")
+}
+
+func (r *Renderer) VisitDelimiterNode(node *ast.DelimiterNode) {
+	if content, ok := r.middle[node]; ok {
+		r.result = r.fence(node.Kind, content.height(), content.baseline)
+		return
+	}
+	r.result = r.fence(node.Kind, 1, 0)
+}
+
+// Visit methods for composite nodes
+func (r *Renderer) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	base := r.layout(node.Base)
+	exp := r.layout(node.Exponent)
+	// The last row of the exponent sits right above the base
+	r.result = beside(base, exp, 0, exp.height())
+}
+
+func (r *Renderer) VisitSubscriptNode(node *ast.SubscriptNode) {
+	base := r.layout(node.Base)
+	sub := r.layout(node.Subscript)
+	// The first row of the subscript sits right below the base
+	r.result = beside(base, sub, base.height()-1, -1)
+}
+
+func (r *Renderer) VisitFractionNode(node *ast.FractionNode) {
+	num := r.layout(node.Numerator)
+	den := r.layout(node.Denominator)
+	bar := text(strings.Repeat(r.glyphs.fractionBar, max(num.width(), den.width())+2))
+	if !node.Ruled() {
+		bar = text(" ")
+	}
+	r.result = vstack(1, num, bar, den)
+}
+
+func (r *Renderer) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	op, ok := r.glyphs.bigOps[node.Operator]
+	if !ok {
+		op = text(node.Operator)
+	}
+
+	boxes := []box{op}
+	at := 0
+	if node.UpperLimit != nil {
+		boxes = append([]box{r.layout(node.UpperLimit)}, boxes...)
+		at = 1
+	}
+	if node.LowerLimit != nil {
+		boxes = append(boxes, r.layout(node.LowerLimit))
+	}
+	r.result = vstack(at, boxes...)
+}
+
+func (r *Renderer) VisitSqrtNode(node *ast.SqrtNode) {
+	radicand := r.layout(node.Radicand)
+	w, h := radicand.width(), radicand.height()
+
+	var sign box
+	if h == 1 {
+		sign = box{lines: []string{
+			strings.Repeat(" ", len([]rune(r.glyphs.radical))),
+			r.glyphs.radical,
+		}, baseline: 1}
+	} else {
+		// A diagonal rising from the tick at the bottom to the top of the radicand
+		tick := []rune(r.glyphs.radicalTick)
+		width := h + len(tick) - 1
+		lines := make([]string, h+1)
+		lines[0] = strings.Repeat(" ", width)
+		for row := 1; row <= h; row++ {
+			line := []rune(strings.Repeat(" ", width))
+			if row == h {
+				copy(line, tick)
+			} else {
+				line[width-row] = []rune(r.glyphs.radicalUp)[0]
+			}
+			lines[row] = string(line)
+		}
+		sign = box{lines: lines, baseline: radicand.baseline + 1}
+	}
+
+	overbar := text(strings.Repeat(r.glyphs.overbar, w))
+	body := vstack(1, overbar, radicand)
+	rad := beside(sign, body, sign.baseline, body.baseline)
+
+	if node.Index != nil {
+		// The index sits above the tick of the radical sign
+		index := r.layout(node.Index)
+		shift := max(0, index.height()-(rad.height()-1))
+		baseline := rad.baseline + shift
+		rad = beside(index, rad, index.height(), rad.height()-1)
+		rad.baseline = baseline
+	}
+	r.result = rad
+}
+
+func (r *Renderer) VisitBinomNode(node *ast.BinomNode) {
+	upper := r.layout(node.Upper)
+	lower := r.layout(node.Lower)
+	inner := vstack(1, upper, text(" "), lower)
+	left := r.fence(ast.LeftParen, inner.height(), inner.baseline)
+	right := r.fence(ast.RightParen, inner.height(), inner.baseline)
+	r.result = hcat(left, inner, right)
+}
+
+// bigRows are the heights in rows of the delimiters of \big, \Big, \bigg
+// and \Bigg.
+var bigRows = [4]int{1, 2, 2, 3}
+
+func (r *Renderer) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	// Sizes out of range are clamped to \big and \Bigg
+	height := bigRows[min(max(node.Size, 1), len(bigRows))-1]
+	r.result = r.fence(delimiterKind(node.Delimiter), height, height/2)
+}
+
+// needsSpace reports whether the element following n must be separated
+// by a space, as in "sin x".
+func needsSpace(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.NonArgumentFunctionNode, *ast.LimitedOperatorNode:
+		return true
+	case *ast.SuperscriptNode:
+		return needsSpace(node.Base)
+	case *ast.SubscriptNode:
+		return needsSpace(node.Base)
+	}
+	return false
+}
+
+// isOpeningParen reports whether n starts with a parenthesis.
+func isOpeningParen(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.DelimiterNode:
+		return node.Kind == ast.LeftParen
+	case *ast.DelimitedExpressionNode:
+		return isOpeningParen(node.LeftDelimiter)
+	}
+	return false
+}
+
+func isOperator(n ast.Node) bool {
+	_, ok := n.(*ast.OperatorNode)
+	return ok
+}
+
+func delimiterKind(n ast.Node) ast.Delimiter {
+	if d, ok := n.(*ast.DelimiterNode); ok {
+		return d.Kind
+	}
+	return ast.NoDelimiter
+}
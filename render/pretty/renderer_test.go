@@ -0,0 +1,63 @@
+package pretty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/pretty"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		input   string
+		charset pretty.Charset
+		want    []string
+	}{
+		{`\frac{a^2}{b}`, pretty.Unicode, []string{
+			"  2",
+			" a",
+			"────",
+			" b",
+		}},
+		{`\alpha_1 \leq \sqrt{x}`, pretty.ASCII, []string{
+			"            _",
+			"alpha  <= \\/x",
+			"     1",
+		}},
+		{`\sum_{i=1}^n i^2`, pretty.Unicode, []string{
+			"  n",
+			"  ⎲    2",
+			"  ⎳   i",
+			"i = 1",
+		}},
+		{`\left( \frac{1}{x} \right)`, pretty.ASCII, []string{
+			"/ 1 \\",
+			"|---|",
+			"\\ x /",
+		}},
+		{`\left\{ \frac{1}{x} \middle| x > 0 \right\}`, pretty.Unicode, []string{
+			"⎧ 1 │     ⎫",
+			"⎨───│x > 0⎬",
+			"⎩ x │     ⎭",
+		}},
+		{`{a \over b} + {n \atop k}`, pretty.ASCII, []string{
+			" a    n",
+			"--- +",
+			" b    k",
+		}},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		if len(errs) > 0 {
+			t.Fatalf("%s: unexpected parse errors: %v", tt.input, errs)
+		}
+		got := pretty.Render(root, tt.charset)
+		want := strings.Join(tt.want, "\n")
+		if got != want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.input, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package speech_test
+
+import (
+	"fmt"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/speech"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func ExampleRender() {
+	root, _ := parser.New(tokenizer.Tokenize(`\frac{a^2}{b}`)).Parse()
+
+	fmt.Println(speech.Render(root, speech.English, speech.Verbose))
+	fmt.Println(speech.Render(root, speech.English, speech.Brief))
+	fmt.Println(speech.Render(root, speech.German, speech.Verbose))
+
+	// Output:
+	// fraction a squared over b end fraction
+	// frac a squared over b end frac
+	// Bruch a Quadrat durch b Ende Bruch
+}
+
+func ExampleRender_limits() {
+	root, _ := parser.New(tokenizer.Tokenize(`\sum_{i=1}^n x_i \leq \sqrt{\alpha}`)).Parse()
+
+	fmt.Println(speech.Render(root, speech.English, speech.Verbose))
+	fmt.Println(speech.Render(root, speech.English, speech.Brief))
+
+	// Output:
+	// sum from i equals 1 to n of x sub i end subscript is less than or equal to square root of alpha end root
+	// sum from i equals 1 to n of x sub i less than or equal to root alpha
+}
@@ -0,0 +1,321 @@
+package speech
+
+// Phrase is the wording of one phrase key. Brief falls back to Verbose
+// when empty.
+type Phrase struct {
+	Verbose string
+	Brief   string
+}
+
+// Language is a localisable phrase table. Keys missing from a language
+// fall back to English. Operators and functions are looked up by their
+// value in the AST prefixed with "op:" and "fn:", Greek letters by their
+// command name prefixed with "greek:".
+type Language struct {
+	Name    string
+	Phrases map[string]Phrase
+}
+
+// phrase returns the wording of key, falling back to English.
+func (l *Language) phrase(key string, v Verbosity) (string, bool) {
+	p, ok := l.Phrases[key]
+	if !ok && l != English {
+		p, ok = English.Phrases[key]
+	}
+	if !ok {
+		return "", false
+	}
+	if v == Brief && p.Brief != "" {
+		return p.Brief, true
+	}
+	return p.Verbose, true
+}
+
+// English is the default phrase table.
+var English = &Language{
+	Name: "en",
+	Phrases: map[string]Phrase{
+		"capital": {"capital", "cap"},
+
+		"fraction.start": {"fraction", "frac"},
+		"fraction.over":  {"over", ""},
+		"fraction.end":   {"end fraction", "end frac"},
+
+		"power.squared": {"squared", ""},
+		"power.cubed":   {"cubed", ""},
+		"power.start":   {"to the power of", "to the"},
+		"power.end":     {"end exponent", "end exp"},
+		"sub.start":     {"sub", ""},
+		"sub.end":       {"end subscript", "end sub"},
+
+		"root.square": {"square root of", "root"},
+		"root.cube":   {"cube root of", "cube root"},
+		"root.index":  {"root with index", "root index"},
+		"root.of":     {"of", ""},
+		"root.end":    {"end root", ""},
+
+		"binom.choose": {"choose", ""},
+		"binom.start":  {"binomial", "binom"},
+		"binom.end":    {"end binomial", "end binom"},
+
+		"sum.start":   {"sum", ""},
+		"prod.start":  {"product", "prod"},
+		"int.start":   {"integral", "int"},
+		"lim.start":   {"limit as", "limit"},
+		"limits.from": {"from", ""},
+		"limits.to":   {"to", ""},
+		"limits.of":   {"of", ""},
+
+		"paren.open":    {"open paren", "paren"},
+		"paren.close":   {"close paren", "close"},
+		"bracket.open":  {"open bracket", "bracket"},
+		"bracket.close": {"close bracket", "close"},
+		"brace.open":    {"open brace", "brace"},
+		"brace.close":   {"close brace", "close"},
+		"abs.start":     {"absolute value of", "abs"},
+		"abs.end":       {"end absolute value", "end abs"},
+		"norm.start":    {"norm of", "norm"},
+		"norm.end":      {"end norm", ""},
+		"floor.start":   {"floor of", "floor"},
+		"floor.end":     {"end floor", ""},
+		"ceil.start":    {"ceiling of", "ceiling"},
+		"ceil.end":      {"end ceiling", ""},
+		"angle.open":    {"open angle bracket", "angle"},
+		"angle.close":   {"close angle bracket", "close"},
+		"bar":           {"vertical bar", "bar"},
+		"backslash":     {"set minus", ""},
+
+		"group.open":         {"open group", "group"},
+		"group.close":        {"close group", "close"},
+		"moustache.open":     {"open moustache", "moustache"},
+		"moustache.close":    {"close moustache", "close"},
+		"corner.upperleft":   {"upper left corner", ""},
+		"corner.upperright":  {"upper right corner", ""},
+		"corner.lowerleft":   {"lower left corner", ""},
+		"corner.lowerright":  {"lower right corner", ""},
+		"doublebar":          {"double vertical bar", "double bar"},
+		"slash":              {"slash", ""},
+		"arrow.up":           {"up arrow", ""},
+		"arrow.down":         {"down arrow", ""},
+		"arrow.updown":       {"up down arrow", ""},
+		"arrow.doubleup":     {"double up arrow", ""},
+		"arrow.doubledown":   {"double down arrow", ""},
+		"arrow.doubleupdown": {"double up down arrow", ""},
+
+		"negative": {"negative", "neg"},
+
+		"op:+": {"plus", ""},
+		"op:-": {"minus", ""},
+		"op:*": {"times", ""},
+		"op:/": {"divided by", "over"},
+		"op:=": {"equals", ""},
+		"op:<": {"is less than", "less than"},
+		"op:>": {"is greater than", "greater than"},
+		"op:·": {"times", ""},
+		"op:×": {"times", ""},
+		"op:÷": {"divided by", ""},
+		"op:±": {"plus or minus", ""},
+		"op:∓": {"minus or plus", ""},
+		"op:∗": {"star", ""},
+		"op:∘": {"composed with", "compose"},
+		"op:∪": {"union", ""},
+		"op:∩": {"intersection", ""},
+		"op:∖": {"set minus", ""},
+		"op:∧": {"and", ""},
+		"op:∨": {"or", ""},
+		"op:≤": {"is less than or equal to", "less than or equal to"},
+		"op:≥": {"is greater than or equal to", "greater than or equal to"},
+		"op:≠": {"is not equal to", "not equal to"},
+		"op:≈": {"is approximately equal to", "approximately"},
+		"op:≡": {"is equivalent to", "equivalent to"},
+		"op:∼": {"is similar to", "similar to"},
+		"op:≃": {"is asymptotically equal to", "asymptotic to"},
+		"op:≅": {"is congruent to", "congruent to"},
+		"op:∝": {"is proportional to", "proportional to"},
+		"op:≪": {"is much less than", "much less than"},
+		"op:≫": {"is much greater than", "much greater than"},
+		"op:∈": {"is an element of", "in"},
+		"op:∉": {"is not an element of", "not in"},
+		"op:⊂": {"is a subset of", "subset of"},
+		"op:⊃": {"is a superset of", "superset of"},
+		"op:→": {"approaches", "to"},
+		"op:←": {"left arrow", ""},
+		"op:⇒": {"implies", ""},
+		"op:⇐": {"is implied by", ""},
+		"op:⇔": {"if and only if", "iff"},
+		"op:↦": {"maps to", ""},
+
+		"fn:sin":    {"sine", "sin"},
+		"fn:cos":    {"cosine", "cos"},
+		"fn:tan":    {"tangent", "tan"},
+		"fn:cot":    {"cotangent", "cot"},
+		"fn:sec":    {"secant", "sec"},
+		"fn:csc":    {"cosecant", "csc"},
+		"fn:log":    {"log", ""},
+		"fn:ln":     {"natural log", "l n"},
+		"fn:exp":    {"exponential", "exp"},
+		"fn:arcsin": {"arc sine", "arc sin"},
+		"fn:arccos": {"arc cosine", "arc cos"},
+		"fn:arctan": {"arc tangent", "arc tan"},
+		"fn:sinh":   {"hyperbolic sine", "sinch"},
+		"fn:cosh":   {"hyperbolic cosine", "cosh"},
+		"fn:tanh":   {"hyperbolic tangent", "tanch"},
+		"fn:max":    {"maximum", "max"},
+		"fn:min":    {"minimum", "min"},
+		"fn:det":    {"determinant", "det"},
+		"fn:arg":    {"argument", "arg"},
+		"fn:mod":    {"modulo", "mod"},
+	},
+}
+
+// German is the German phrase table. Function names without an entry use
+// the English wording.
+var German = &Language{
+	Name: "de",
+	Phrases: map[string]Phrase{
+		"capital": {"großes", "groß"},
+
+		"greek:alpha":   {"Alpha", ""},
+		"greek:beta":    {"Beta", ""},
+		"greek:gamma":   {"Gamma", ""},
+		"greek:delta":   {"Delta", ""},
+		"greek:epsilon": {"Epsilon", ""},
+		"greek:zeta":    {"Zeta", ""},
+		"greek:eta":     {"Eta", ""},
+		"greek:theta":   {"Theta", ""},
+		"greek:iota":    {"Iota", ""},
+		"greek:kappa":   {"Kappa", ""},
+		"greek:lambda":  {"Lambda", ""},
+		"greek:mu":      {"My", ""},
+		"greek:nu":      {"Ny", ""},
+		"greek:xi":      {"Xi", ""},
+		"greek:omicron": {"Omikron", ""},
+		"greek:pi":      {"Pi", ""},
+		"greek:rho":     {"Rho", ""},
+		"greek:sigma":   {"Sigma", ""},
+		"greek:tau":     {"Tau", ""},
+		"greek:upsilon": {"Ypsilon", ""},
+		"greek:phi":     {"Phi", ""},
+		"greek:chi":     {"Chi", ""},
+		"greek:psi":     {"Psi", ""},
+		"greek:omega":   {"Omega", ""},
+
+		"fraction.start": {"Bruch", ""},
+		"fraction.over":  {"durch", ""},
+		"fraction.end":   {"Ende Bruch", ""},
+
+		"power.squared": {"Quadrat", ""},
+		"power.cubed":   {"hoch drei", ""},
+		"power.start":   {"hoch", ""},
+		"power.end":     {"Ende Exponent", "Ende Exp"},
+		"sub.start":     {"Index", ""},
+		"sub.end":       {"Ende Index", ""},
+
+		"root.square": {"Quadratwurzel aus", "Wurzel"},
+		"root.cube":   {"Kubikwurzel aus", "Kubikwurzel"},
+		"root.index":  {"Wurzel mit Index", "Wurzel Index"},
+		"root.of":     {"aus", ""},
+		"root.end":    {"Ende Wurzel", ""},
+
+		"binom.choose": {"über", ""},
+		"binom.start":  {"Binomialkoeffizient", "Binom"},
+		"binom.end":    {"Ende Binomialkoeffizient", "Ende Binom"},
+
+		"sum.start":   {"Summe", ""},
+		"prod.start":  {"Produkt", ""},
+		"int.start":   {"Integral", ""},
+		"lim.start":   {"Grenzwert für", "Limes"},
+		"limits.from": {"von", ""},
+		"limits.to":   {"bis", ""},
+		"limits.of":   {"über", ""},
+
+		"paren.open":    {"Klammer auf", ""},
+		"paren.close":   {"Klammer zu", ""},
+		"bracket.open":  {"eckige Klammer auf", ""},
+		"bracket.close": {"eckige Klammer zu", ""},
+		"brace.open":    {"geschweifte Klammer auf", ""},
+		"brace.close":   {"geschweifte Klammer zu", ""},
+		"abs.start":     {"Betrag von", "Betrag"},
+		"abs.end":       {"Ende Betrag", ""},
+		"norm.start":    {"Norm von", "Norm"},
+		"norm.end":      {"Ende Norm", ""},
+		"floor.start":   {"abgerundet", ""},
+		"floor.end":     {"Ende abgerundet", ""},
+		"ceil.start":    {"aufgerundet", ""},
+		"ceil.end":      {"Ende aufgerundet", ""},
+		"angle.open":    {"spitze Klammer auf", ""},
+		"angle.close":   {"spitze Klammer zu", ""},
+		"bar":           {"senkrechter Strich", "Strich"},
+		"backslash":     {"ohne", ""},
+
+		"group.open":         {"Gruppenklammer auf", ""},
+		"group.close":        {"Gruppenklammer zu", ""},
+		"moustache.open":     {"Schnurrbartklammer auf", ""},
+		"moustache.close":    {"Schnurrbartklammer zu", ""},
+		"corner.upperleft":   {"Ecke oben links", ""},
+		"corner.upperright":  {"Ecke oben rechts", ""},
+		"corner.lowerleft":   {"Ecke unten links", ""},
+		"corner.lowerright":  {"Ecke unten rechts", ""},
+		"doublebar":          {"doppelter senkrechter Strich", "Doppelstrich"},
+		"slash":              {"Schrägstrich", ""},
+		"arrow.up":           {"Pfeil nach oben", ""},
+		"arrow.down":         {"Pfeil nach unten", ""},
+		"arrow.updown":       {"Pfeil nach oben und unten", ""},
+		"arrow.doubleup":     {"Doppelpfeil nach oben", ""},
+		"arrow.doubledown":   {"Doppelpfeil nach unten", ""},
+		"arrow.doubleupdown": {"Doppelpfeil nach oben und unten", ""},
+
+		"negative": {"minus", ""},
+
+		"op:+": {"plus", ""},
+		"op:-": {"minus", ""},
+		"op:*": {"mal", ""},
+		"op:/": {"geteilt durch", "durch"},
+		"op:=": {"gleich", ""},
+		"op:<": {"kleiner als", ""},
+		"op:>": {"größer als", ""},
+		"op:·": {"mal", ""},
+		"op:×": {"mal", ""},
+		"op:÷": {"geteilt durch", ""},
+		"op:±": {"plus minus", ""},
+		"op:∓": {"minus plus", ""},
+		"op:∘": {"verkettet mit", ""},
+		"op:∪": {"vereinigt mit", ""},
+		"op:∩": {"geschnitten mit", ""},
+		"op:∖": {"ohne", ""},
+		"op:∧": {"und", ""},
+		"op:∨": {"oder", ""},
+		"op:≤": {"kleiner oder gleich", ""},
+		"op:≥": {"größer oder gleich", ""},
+		"op:≠": {"ungleich", ""},
+		"op:≈": {"ungefähr gleich", ""},
+		"op:≡": {"äquivalent zu", ""},
+		"op:∝": {"proportional zu", ""},
+		"op:∈": {"Element von", ""},
+		"op:∉": {"nicht Element von", ""},
+		"op:⊂": {"Teilmenge von", ""},
+		"op:⊃": {"Obermenge von", ""},
+		"op:→": {"gegen", ""},
+		"op:⇒": {"daraus folgt", ""},
+		"op:⇔": {"genau dann wenn", ""},
+		"op:↦": {"wird abgebildet auf", ""},
+
+		"fn:sin":    {"Sinus", "sin"},
+		"fn:cos":    {"Kosinus", "cos"},
+		"fn:tan":    {"Tangens", "tan"},
+		"fn:cot":    {"Kotangens", "cot"},
+		"fn:log":    {"Logarithmus", "log"},
+		"fn:ln":     {"natürlicher Logarithmus", "l n"},
+		"fn:exp":    {"Exponentialfunktion", "exp"},
+		"fn:arcsin": {"Arkussinus", ""},
+		"fn:arccos": {"Arkuskosinus", ""},
+		"fn:arctan": {"Arkustangens", ""},
+		"fn:sinh":   {"Sinus hyperbolicus", ""},
+		"fn:cosh":   {"Kosinus hyperbolicus", ""},
+		"fn:tanh":   {"Tangens hyperbolicus", ""},
+		"fn:max":    {"Maximum", "max"},
+		"fn:min":    {"Minimum", "min"},
+		"fn:det":    {"Determinante", "det"},
+		"fn:mod":    {"modulo", "mod"},
+	},
+}
@@ -0,0 +1,344 @@
+// Package speech renders the AST as spoken text for screen readers, e.g.
+// \frac{a^2}{b} as "fraction a squared over b end fraction". The rules
+// follow the MathSpeak style of bracketing structures with start and end
+// phrases; brief verbosity drops the markers around simple arguments.
+package speech
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+// Verbosity selects how much structure is announced.
+type Verbosity int
+
+const (
+	// Verbose announces the start and end of every structure.
+	Verbose Verbosity = iota
+	// Brief omits markers where the structure is unambiguous.
+	Brief
+)
+
+// Render returns the speech string of node in the given language.
+func Render(node ast.Node, lang *Language, v Verbosity) string {
+	if node == nil {
+		return ""
+	}
+	r := NewRenderer(lang, v)
+	ast.Walk(r, node)
+	return r.String()
+}
+
+// Renderer is an ast.Visitor that collects the words spoken for each node.
+type Renderer struct {
+	lang      *Language
+	verbosity Verbosity
+	words     []string
+}
+
+// NewRenderer creates a new Renderer. A nil language selects English.
+func NewRenderer(lang *Language, v Verbosity) *Renderer {
+	if lang == nil {
+		lang = English
+	}
+	return &Renderer{lang: lang, verbosity: v}
+}
+
+// String returns the words spoken so far, separated by spaces.
+func (r *Renderer) String() string {
+	return strings.Join(r.words, " ")
+}
+
+// say appends the phrase for key.
+func (r *Renderer) say(key string) {
+	if text, ok := r.lang.phrase(key, r.verbosity); ok && text != "" {
+		r.words = append(r.words, text)
+	}
+}
+
+// word appends literal text.
+func (r *Renderer) word(text string) {
+	if text != "" {
+		r.words = append(r.words, text)
+	}
+}
+
+// marker says key unless brief verbosity applies and n is simple.
+func (r *Renderer) marker(key string, n ast.Node) {
+	if r.verbosity == Verbose || !isSimple(n) {
+		r.say(key)
+	}
+}
+
+// spoken returns the words for n without adding them to the output.
+func (r *Renderer) spoken(n ast.Node) string {
+	saved := r.words
+	r.words = nil
+	n.Accept(r)
+	out := strings.Join(r.words, " ")
+	r.words = saved
+	return out
+}
+
+// Visit methods for container nodes
+func (r *Renderer) VisitExpressionNode(node *ast.ExpressionNode) {
+	for i, element := range node.Elements {
+		// A leading minus is read as "negative"
+		if op, ok := element.(*ast.OperatorNode); ok && op.Value == "-" &&
+			(i == 0 || isOperator(node.Elements[i-1])) {
+			r.say("negative")
+			continue
+		}
+		element.Accept(r)
+	}
+}
+
+func (r *Renderer) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	left, right := delimiterKind(node.LeftDelimiter), delimiterKind(node.RightDelimiter)
+
+	// Bars pair whether or not they are marked as opening and closing
+	var start, end string
+	switch left.Glyph() + right.Glyph() {
+	case "||":
+		start, end = "abs.start", "abs.end"
+	case "‖‖":
+		start, end = "norm.start", "norm.end"
+	case "⌊⌋":
+		start, end = "floor.start", "floor.end"
+	case "⌈⌉":
+		start, end = "ceil.start", "ceil.end"
+	}
+	if start != "" {
+		r.say(start)
+		if node.Content != nil {
+			node.Content.Accept(r)
+		}
+		r.marker(end, node.Content)
+		return
+	}
+
+	node.LeftDelimiter.Accept(r)
+	if node.Content != nil {
+		node.Content.Accept(r)
+	}
+	node.RightDelimiter.Accept(r)
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (r *Renderer) VisitErrorNode(node *ast.ErrorNode) {
+	r.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (r *Renderer) VisitSymbolNode(node *ast.SymbolNode) {
+	if name, ok := parser.GreekLetterName(node.Value); ok {
+		if unicode.IsUpper([]rune(name)[0]) {
+			r.say("capital")
+			name = strings.ToLower(name)
+		}
+		if text, ok := r.lang.phrase("greek:"+name, r.verbosity); ok {
+			name = text
+		}
+		r.word(name)
+		return
+	}
+	for _, c := range node.Value {
+		if unicode.IsUpper(c) {
+			r.say("capital")
+		}
+		r.word(string(c))
+	}
+}
+
+func (r *Renderer) VisitNumberNode(node *ast.NumberNode) {
+	r.word(node.Value)
+}
+
+func (r *Renderer) VisitOperatorNode(node *ast.OperatorNode) {
+	if text, ok := r.lang.phrase("op:"+node.Value, r.verbosity); ok {
+		r.word(text)
+		return
+	}
+	r.word(node.Value)
+}
+
+func (r *Renderer) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	if text, ok := r.lang.phrase("fn:"+node.Name, r.verbosity); ok {
+		r.word(text)
+		return
+	}
+	r.word(node.Name)
+}
+
+func (r *Renderer) VisitSpaceNode(node *ast.SpaceNode) {}
+
+func (r *Renderer) VisitDelimiterNode(node *ast.DelimiterNode) {
+	if key, ok := delimiterPhrases[node.Kind]; ok {
+		r.say(key)
+	}
+}
+
+// delimiterPhrases maps the delimiters to their phrase keys. The empty
+// delimiter is not spoken.
+var delimiterPhrases = map[ast.Delimiter]string{
+	ast.LeftParen:         "paren.open",
+	ast.RightParen:        "paren.close",
+	ast.LeftBracket:       "bracket.open",
+	ast.RightBracket:      "bracket.close",
+	ast.LeftBrace:         "brace.open",
+	ast.RightBrace:        "brace.close",
+	ast.LeftAngle:         "angle.open",
+	ast.RightAngle:        "angle.close",
+	ast.LeftFloor:         "floor.start",
+	ast.RightFloor:        "floor.end",
+	ast.LeftCeil:          "ceil.start",
+	ast.RightCeil:         "ceil.end",
+	ast.LeftGroup:         "group.open",
+	ast.RightGroup:        "group.close",
+	ast.LeftMoustache:     "moustache.open",
+	ast.RightMoustache:    "moustache.close",
+	ast.UpperLeftCorner:   "corner.upperleft",
+	ast.UpperRightCorner:  "corner.upperright",
+	ast.LowerLeftCorner:   "corner.lowerleft",
+	ast.LowerRightCorner:  "corner.lowerright",
+	ast.Vert:              "bar",
+	ast.LeftVert:          "bar",
+	ast.RightVert:         "bar",
+	ast.DoubleVert:        "doublebar",
+	ast.LeftDoubleVert:    "doublebar",
+	ast.RightDoubleVert:   "doublebar",
+	ast.Slash:             "slash",
+	ast.Backslash:         "backslash",
+	ast.UpArrow:           "arrow.up",
+	ast.DownArrow:         "arrow.down",
+	ast.UpDownArrow:       "arrow.updown",
+	ast.DoubleUpArrow:     "arrow.doubleup",
+	ast.DoubleDownArrow:   "arrow.doubledown",
+	ast.DoubleUpDownArrow: "arrow.doubleupdown",
+}
+
+// Visit methods for composite nodes
+func (r *Renderer) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	node.Base.Accept(r)
+	switch r.spoken(node.Exponent) {
+	case "2":
+		r.say("power.squared")
+		return
+	case "3":
+		r.say("power.cubed")
+		return
+	}
+	r.say("power.start")
+	node.Exponent.Accept(r)
+	r.marker("power.end", node.Exponent)
+}
+
+func (r *Renderer) VisitSubscriptNode(node *ast.SubscriptNode) {
+	node.Base.Accept(r)
+	r.say("sub.start")
+	node.Subscript.Accept(r)
+	r.marker("sub.end", node.Subscript)
+}
+
+func (r *Renderer) VisitFractionNode(node *ast.FractionNode) {
+	simple := isSimple(node.Numerator) && isSimple(node.Denominator)
+	if r.verbosity == Verbose || !simple {
+		r.say("fraction.start")
+	}
+	node.Numerator.Accept(r)
+	r.say("fraction.over")
+	node.Denominator.Accept(r)
+	if r.verbosity == Verbose || !simple {
+		r.say("fraction.end")
+	}
+}
+
+func (r *Renderer) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	if _, ok := r.lang.phrase(node.Operator+".start", r.verbosity); ok {
+		r.say(node.Operator + ".start")
+	} else {
+		r.word(node.Operator)
+	}
+	if node.Operator == "lim" {
+		// "limit as x approaches 0 of"
+		if node.LowerLimit != nil {
+			node.LowerLimit.Accept(r)
+		}
+		r.say("limits.of")
+		return
+	}
+	if node.LowerLimit != nil {
+		r.say("limits.from")
+		node.LowerLimit.Accept(r)
+	}
+	if node.UpperLimit != nil {
+		r.say("limits.to")
+		node.UpperLimit.Accept(r)
+	}
+	r.say("limits.of")
+}
+
+func (r *Renderer) VisitSqrtNode(node *ast.SqrtNode) {
+	index := ""
+	if node.Index != nil {
+		index = r.spoken(node.Index)
+	}
+	switch index {
+	case "", "2":
+		r.say("root.square")
+	case "3":
+		r.say("root.cube")
+	default:
+		r.say("root.index")
+		node.Index.Accept(r)
+		r.say("root.of")
+	}
+	if node.Radicand != nil {
+		node.Radicand.Accept(r)
+	}
+	r.marker("root.end", node.Radicand)
+}
+
+func (r *Renderer) VisitBinomNode(node *ast.BinomNode) {
+	simple := isSimple(node.Upper) && isSimple(node.Lower)
+	if r.verbosity == Verbose || !simple {
+		r.say("binom.start")
+	}
+	node.Upper.Accept(r)
+	r.say("binom.choose")
+	node.Lower.Accept(r)
+	if r.verbosity == Verbose || !simple {
+		r.say("binom.end")
+	}
+}
+
+func (r *Renderer) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	node.Delimiter.Accept(r)
+}
+
+// isSimple reports whether n is a single symbol or number, which needs no
+// end marker in brief mode.
+func isSimple(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.SymbolNode, *ast.NumberNode:
+		return true
+	case *ast.ExpressionNode:
+		return len(node.Elements) == 1 && isSimple(node.Elements[0])
+	}
+	return false
+}
+
+func isOperator(n ast.Node) bool {
+	_, ok := n.(*ast.OperatorNode)
+	return ok
+}
+
+func delimiterKind(n ast.Node) ast.Delimiter {
+	if d, ok := n.(*ast.DelimiterNode); ok {
+		return d.Kind
+	}
+	return ast.NoDelimiter
+}
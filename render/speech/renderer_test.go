@@ -0,0 +1,19 @@
+package speech_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/render/speech"
+)
+
+func TestOperatorWithoutPhrase(t *testing.T) {
+	node := &ast.LimitedOperatorNode{
+		Operator:   "max",
+		LowerLimit: &ast.SymbolNode{Value: "x"},
+	}
+	want := "max from x of"
+	if got := speech.Render(node, speech.English, speech.Verbose); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
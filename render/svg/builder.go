@@ -0,0 +1,420 @@
+package svg
+
+import (
+	"unicode"
+
+	"github.com/neox5/texmax/ast"
+)
+
+// bigOperatorGlyphs maps the operators of ast.LimitedOperatorNode to glyphs.
+var bigOperatorGlyphs = map[string]string{
+	"int":  "∫",
+	"sum":  "∑",
+	"prod": "∏",
+}
+
+// relations are the operators that get relation spacing.
+var relations = map[string]bool{
+	"=": true, "<": true, ">": true, "≤": true, "≥": true, "≠": true,
+	"≈": true, "≡": true, "∼": true, "≃": true, "≅": true, "∝": true,
+	"≪": true, "≫": true, "∈": true, "∉": true, "⊂": true, "⊃": true,
+	"→": true, "←": true, "⇒": true, "⇐": true, "⇔": true, "↦": true,
+}
+
+// builder is an ast.Visitor that lays out each node as a box. Every Visit
+// method sets result to the box of the visited node.
+type builder struct {
+	style  style
+	result *box
+
+	// middle maps the \middle separators being laid out to the content
+	// of their delimited expression, to whose size they grow
+	middle map[*ast.DelimiterNode]*box
+}
+
+// build lays out n in style s.
+func (b *builder) build(n ast.Node, s style) *box {
+	if n == nil {
+		return kern(0)
+	}
+	saved := b.style
+	b.style = s
+	n.Accept(b)
+	b.style = saved
+	return b.result
+}
+
+func (b *builder) size() float64 {
+	return b.style.size()
+}
+
+// Visit methods for container nodes
+func (b *builder) VisitExpressionNode(node *ast.ExpressionNode) {
+	var boxes []*box
+	prev := atom(-1)
+	for i, element := range node.Elements {
+		class := classOf(element)
+		// A binary operator without a left operand is treated as ordinary
+		if class == binAtom && (i == 0 || i == len(node.Elements)-1 ||
+			prev == binAtom || prev == opAtom || prev == relAtom || prev == openAtom || prev == punctAtom) {
+			class = ordAtom
+		}
+		if prev >= 0 {
+			if glue := space(prev, class, b.style); glue > 0 {
+				boxes = append(boxes, kern(glue))
+			}
+		}
+		boxes = append(boxes, b.build(element, b.style))
+		prev = class
+	}
+	b.result = hbox(boxes...)
+}
+
+func (b *builder) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	content := b.build(node.Content, b.style)
+	if len(node.Middle) > 0 {
+		// Lay out the content again with the separators grown to it
+		saved := b.middle
+		b.middle = map[*ast.DelimiterNode]*box{}
+		for _, m := range node.Middle {
+			b.middle[m] = content
+		}
+		content = b.build(node.Content, b.style)
+		b.middle = saved
+	}
+	left := b.delimiter(delimiterKind(node.LeftDelimiter), content)
+	right := b.delimiter(delimiterKind(node.RightDelimiter), content)
+	b.result = hbox(left, content, right)
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (b *builder) VisitErrorNode(node *ast.ErrorNode) {
+	b.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (b *builder) VisitSymbolNode(node *ast.SymbolNode) {
+	b.result = glyphBox(node.Value, b.size(), isItalic(node.Value))
+}
+
+func (b *builder) VisitNumberNode(node *ast.NumberNode) {
+	b.result = glyphBox(node.Value, b.size(), false)
+}
+
+func (b *builder) VisitOperatorNode(node *ast.OperatorNode) {
+	value := node.Value
+	if value == "-" {
+		value = "−" // minus sign instead of hyphen
+	}
+	b.result = glyphBox(value, b.size(), false)
+}
+
+func (b *builder) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	b.result = glyphBox(node.Name, b.size(), false)
+}
+
+func (b *builder) VisitSpaceNode(node *ast.SpaceNode) {
+	b.result = kern(0.25 * b.size())
+}
+
+func (b *builder) VisitDelimiterNode(node *ast.DelimiterNode) {
+	b.result = b.delimiter(node.Kind, b.middle[node])
+}
+
+// Visit methods for composite nodes
+func (b *builder) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	base := b.build(node.Base, b.style)
+	sup := b.build(node.Exponent, b.style.script())
+	b.result = hbox(base, raise(sup, b.supShift(base, sup)))
+}
+
+func (b *builder) VisitSubscriptNode(node *ast.SubscriptNode) {
+	base := b.build(node.Base, b.style)
+	sub := b.build(node.Subscript, b.style.script())
+	b.result = hbox(base, raise(sub, -b.subShift(base, sub)))
+}
+
+func (b *builder) VisitFractionNode(node *ast.FractionNode) {
+	saved := b.style
+	b.style = b.mathStyle(node.Style)
+	parts := b.style.fraction()
+	if node.Style == ast.ContinuedStyle {
+		// Continued fractions keep their parts in display style
+		parts = displayStyle
+	}
+	b.result = b.fraction(node.Numerator, node.Denominator, parts, node.Ruled(), node.Align)
+	b.style = saved
+}
+
+func (b *builder) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	sz := b.size()
+
+	var op *box
+	if glyph, ok := bigOperatorGlyphs[node.Operator]; ok {
+		scale := sz
+		if b.style == displayStyle {
+			scale *= 1.4
+		}
+		op = glyphBox(glyph, scale, false)
+		// Big operators are centered on the math axis
+		op = raise(op, axisHeight*sz-(op.height-op.depth)/2)
+	} else {
+		op = glyphBox(node.Operator, sz, false)
+	}
+
+	var upper, lower *box
+	if node.UpperLimit != nil {
+		upper = b.build(node.UpperLimit, b.style.script())
+	}
+	if node.LowerLimit != nil {
+		lower = b.build(node.LowerLimit, b.style.script())
+	}
+	if upper == nil && lower == nil {
+		b.result = op
+		return
+	}
+
+	// Limits above and below in display style (and always for \lim),
+	// otherwise as scripts to the right
+	if (b.style == displayStyle && node.Operator != "int") || node.Operator == "lim" {
+		gap := bigOpSpacing * sz
+		boxes := []*box{op}
+		offsets := []float64{0}
+		if upper != nil {
+			boxes = append(boxes, upper)
+			offsets = append(offsets, -(op.height + gap + upper.depth))
+		}
+		if lower != nil {
+			boxes = append(boxes, lower)
+			offsets = append(offsets, op.depth+gap+lower.height)
+		}
+		b.result = centered(boxes, offsets)
+		return
+	}
+
+	scripts := &box{}
+	if upper != nil {
+		// Shift the upper limit right to follow the slant of the integral sign
+		italic := 0.0
+		if node.Operator == "int" {
+			italic = 0.3 * op.width
+		}
+		scripts.place(upper, italic, -b.supShift(op, upper))
+		scripts.width = max(scripts.width, italic+upper.width)
+	}
+	if lower != nil {
+		scripts.place(lower, 0, b.subShift(op, lower))
+		scripts.width = max(scripts.width, lower.width)
+	}
+	b.result = hbox(op, scripts)
+}
+
+func (b *builder) VisitSqrtNode(node *ast.SqrtNode) {
+	sz := b.size()
+	radicand := b.build(node.Radicand, b.style)
+	t := ruleThickness * sz
+	clearance := t + t/4
+	if b.style == displayStyle {
+		clearance = t + xHeight*sz/4
+	}
+
+	signWidth := 0.833 * sz
+	top := -(radicand.height + clearance + t/2) // center line of the overbar
+	bottom := radicand.depth + 0.05*sz
+	tick := bottom - min(0.4*(bottom-top), 0.5*sz)
+
+	sign := &box{
+		height: -top + t/2,
+		depth:  bottom,
+		stroke: [][2]float64{
+			{0.06 * sz, tick + 0.04*sz},
+			{0.17 * sz, tick},
+			{0.38 * sz, bottom},
+			{signWidth - 0.04*sz, top},
+			{signWidth + radicand.width + 0.05*sz, top},
+		},
+	}
+	rad := &box{}
+	rad.place(sign, 0, 0)
+	rad.place(radicand, signWidth, 0)
+	rad.width = signWidth + radicand.width + 0.05*sz
+
+	if node.Index != nil {
+		index := b.build(node.Index, scriptScriptStyle)
+		up := 0.6*(rad.height+rad.depth) - rad.depth
+		mu := muSkip * sz
+		rad = hbox(kern(5*mu), raise(index, up), kern(-10*mu), rad)
+	}
+	b.result = rad
+}
+
+func (b *builder) VisitBinomNode(node *ast.BinomNode) {
+	saved := b.style
+	b.style = b.mathStyle(node.Style)
+	stack := b.fraction(node.Upper, node.Lower, b.style.fraction(), false, "")
+	b.result = hbox(b.delimiter(ast.LeftParen, stack), stack, b.delimiter(ast.RightParen, stack))
+	b.style = saved
+}
+
+// bigSizes are the heights in em of the delimiters of \big, \Big, \bigg
+// and \Bigg.
+var bigSizes = [4]float64{1.2, 1.8, 2.4, 3.0}
+
+func (b *builder) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	// The delimiter grows as for content of the given height centered on
+	// the axis. Sizes out of range are clamped to \big and \Bigg.
+	i := min(max(node.Size, 1), len(bigSizes)) - 1
+	sz := b.size()
+	height, axis := bigSizes[i]*sz, axisHeight*sz
+	b.result = b.delimiter(delimiterKind(node.Delimiter), &box{height: axis + height/2, depth: height/2 - axis})
+}
+
+// mathStyle returns the style a fraction or binomial of style s is set in.
+func (b *builder) mathStyle(s ast.Style) style {
+	switch s {
+	case ast.DisplayStyle, ast.ContinuedStyle:
+		return displayStyle
+	case ast.TextStyle:
+		return textStyle
+	case ast.ScriptStyle:
+		return scriptStyle
+	case ast.ScriptScriptStyle:
+		return scriptScriptStyle
+	}
+	return b.style
+}
+
+// fraction stacks numerator over denominator, set in style parts, with a
+// fraction bar if rule is set. The numerator is flush left or right if
+// align is "l" or "r", and centered otherwise.
+func (b *builder) fraction(numerator, denominator ast.Node, parts style, rule bool, align string) *box {
+	sz := b.size()
+	num := b.build(numerator, parts)
+	den := b.build(denominator, parts)
+
+	t := ruleThickness * sz
+	gap := t
+	shiftUp, shiftDown := numShiftText*sz, denomShiftText*sz
+	if b.style == displayStyle {
+		gap = 3 * t
+		shiftUp, shiftDown = numShiftUp*sz, denomShiftDown*sz
+	}
+	axis := axisHeight * sz
+	shiftUp = max(shiftUp, axis+t/2+gap+num.depth)
+	shiftDown = max(shiftDown, gap+den.height-axis+t/2)
+
+	width := max(num.width, den.width) + 0.24*sz
+	boxes := []*box{num, den}
+	offsets := []float64{-shiftUp, shiftDown}
+	if rule {
+		boxes = append(boxes, ruleBox(width, t, axis))
+		offsets = append(offsets, 0)
+	}
+	stack := centered(boxes, offsets)
+	stack.width = width
+	for i := range stack.children {
+		stack.children[i].x = (width - stack.children[i].box.width) / 2
+	}
+	switch align {
+	case "l":
+		stack.children[0].x = 0.12 * sz
+	case "r":
+		stack.children[0].x = width - num.width - 0.12*sz
+	}
+
+	nullDelimiter := 0.12 * sz
+	return hbox(kern(nullDelimiter), stack, kern(nullDelimiter))
+}
+
+// supShift returns how far a superscript is raised above base.
+func (b *builder) supShift(base, sup *box) float64 {
+	sz := b.size()
+	return max(supShift*sz, base.height-0.386*sz, sup.depth+xHeight*sz/4)
+}
+
+// subShift returns how far a subscript is lowered below base.
+func (b *builder) subShift(base, sub *box) float64 {
+	sz := b.size()
+	return max(subShift*sz, base.depth+0.05*sz, sub.height-0.8*xHeight*sz)
+}
+
+// delimiter sets a delimiter glyph, stretched vertically to cover content
+// symmetrically around the math axis if content is given.
+func (b *builder) delimiter(kind ast.Delimiter, content *box) *box {
+	sz := b.size()
+	if kind == ast.NoDelimiter {
+		return kern(0.12 * sz)
+	}
+	d := glyphBox(kind.Glyph(), sz, false)
+	if content == nil {
+		return d
+	}
+
+	axis := axisHeight * sz
+	natural := d.height + d.depth
+	required := 2 * max(content.height-axis, content.depth+axis)
+	if required <= natural {
+		return d
+	}
+
+	scale := required / natural
+	d.shift = -(axis + required/2) + scale*d.height
+	d.scaleY = scale
+	d.height = axis + required/2
+	d.depth = required/2 - axis
+	return d
+}
+
+// classOf returns the spacing class of a node.
+func classOf(n ast.Node) atom {
+	switch node := n.(type) {
+	case *ast.OperatorNode:
+		if relations[node.Value] {
+			return relAtom
+		}
+		return binAtom
+	case *ast.NonArgumentFunctionNode, *ast.LimitedOperatorNode:
+		return opAtom
+	case *ast.DelimiterNode:
+		switch node.Kind.Class() {
+		case ast.OpenDelimiter:
+			return openAtom
+		case ast.CloseDelimiter:
+			return closeAtom
+		}
+	case *ast.SizedDelimiterNode:
+		switch node.Role {
+		case ast.OpenRole:
+			return openAtom
+		case ast.CloseRole:
+			return closeAtom
+		case ast.RelationRole:
+			return relAtom
+		}
+	case *ast.DelimitedExpressionNode, *ast.FractionNode, *ast.BinomNode:
+		return innerAtom
+	case *ast.SuperscriptNode:
+		return classOf(node.Base)
+	case *ast.SubscriptNode:
+		return classOf(node.Base)
+	}
+	return ordAtom
+}
+
+// isItalic reports whether a symbol is set in math italic: Latin letters
+// and lowercase Greek letters.
+func isItalic(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) || (unicode.Is(unicode.Greek, r) && unicode.IsUpper(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func delimiterKind(n ast.Node) ast.Delimiter {
+	if d, ok := n.(*ast.DelimiterNode); ok {
+		return d.Kind
+	}
+	return ast.NoDelimiter
+}
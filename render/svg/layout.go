@@ -0,0 +1,180 @@
+package svg
+
+// box is the unit of layout, as in TeX: a rectangle with a reference point
+// at the left end of its baseline. Height extends above and depth below
+// the baseline. A box draws a glyph, a rule or a stroked path, and may
+// contain positioned child boxes.
+type box struct {
+	width, height, depth float64
+
+	// glyph
+	text   string
+	size   float64
+	italic bool
+	shift  float64 // baseline offset of the glyph (positive is down)
+	scaleY float64 // vertical stretch of extensible delimiters
+
+	// rule fills the whole box
+	rule bool
+
+	// stroke is a polyline relative to the reference point
+	stroke [][2]float64
+
+	children []placed
+}
+
+// placed is a child box with its reference point relative to the parent's.
+// The y axis points downwards, as in SVG.
+type placed struct {
+	x, y float64
+	box  *box
+}
+
+// glyphBox sets s at the given size.
+func glyphBox(s string, size float64, italic bool) *box {
+	m := measure(s, italic)
+	return &box{
+		width:  m.width * size,
+		height: m.height * size,
+		depth:  m.depth * size,
+		text:   s,
+		size:   size,
+		italic: italic,
+		scaleY: 1,
+	}
+}
+
+// kern is empty horizontal space.
+func kern(width float64) *box {
+	return &box{width: width}
+}
+
+// ruleBox is a filled bar of the given width and thickness that sits
+// centered on height.
+func ruleBox(width, thickness, height float64) *box {
+	return &box{
+		width:  width,
+		height: height + thickness/2,
+		depth:  -(height - thickness/2),
+		rule:   true,
+	}
+}
+
+// place adds child to b at (x, y) and grows b's height and depth to cover it.
+func (b *box) place(child *box, x, y float64) {
+	b.children = append(b.children, placed{x: x, y: y, box: child})
+	b.height = max(b.height, child.height-y)
+	b.depth = max(b.depth, child.depth+y)
+}
+
+// hbox sets boxes next to each other along a common baseline.
+func hbox(boxes ...*box) *box {
+	out := &box{}
+	for _, b := range boxes {
+		out.place(b, out.width, 0)
+		out.width += b.width
+	}
+	return out
+}
+
+// raise returns b shifted up by dy (down for negative dy).
+func raise(b *box, dy float64) *box {
+	out := &box{width: b.width}
+	out.place(b, 0, -dy)
+	return out
+}
+
+// centered stacks boxes vertically and centers them horizontally. Each
+// entry gives the baseline offset of the box relative to the result's
+// baseline (positive is down).
+func centered(boxes []*box, offsets []float64) *box {
+	width := 0.0
+	for _, b := range boxes {
+		width = max(width, b.width)
+	}
+	out := &box{width: width}
+	for i, b := range boxes {
+		out.place(b, (width-b.width)/2, offsets[i])
+	}
+	return out
+}
+
+// style is a TeX math style, which determines font size and spacing.
+type style int
+
+const (
+	displayStyle style = iota
+	textStyle
+	scriptStyle
+	scriptScriptStyle
+)
+
+// size returns the font scale of the style.
+func (s style) size() float64 {
+	switch s {
+	case scriptStyle:
+		return 0.7
+	case scriptScriptStyle:
+		return 0.5
+	default:
+		return 1
+	}
+}
+
+// script returns the style of super- and subscripts.
+func (s style) script() style {
+	if s <= textStyle {
+		return scriptStyle
+	}
+	return scriptScriptStyle
+}
+
+// fraction returns the style of numerators and denominators.
+func (s style) fraction() style {
+	if s == displayStyle {
+		return textStyle
+	}
+	return s.script()
+}
+
+// atom classifies nodes for inter-atom spacing.
+type atom int
+
+const (
+	ordAtom atom = iota
+	opAtom
+	binAtom
+	relAtom
+	openAtom
+	closeAtom
+	punctAtom
+	innerAtom
+)
+
+// spacing is TeX's inter-atom spacing table in mu: 1 is a thin, 2 a medium
+// and 3 a thick space. Negative entries apply only in display and text
+// style.
+var spacing = [8][8]int{
+	//         ord op bin rel open close punct inner
+	ordAtom:   {0, 1, -2, -3, 0, 0, 0, -1},
+	opAtom:    {1, 1, 0, -3, 0, 0, 0, -1},
+	binAtom:   {-2, -2, 0, 0, -2, 0, 0, -2},
+	relAtom:   {-3, -3, 0, 0, -3, 0, 0, -3},
+	openAtom:  {0, 0, 0, 0, 0, 0, 0, 0},
+	closeAtom: {0, 1, -2, -3, 0, 0, 0, -1},
+	punctAtom: {-1, -1, 0, -1, -1, -1, -1, -1},
+	innerAtom: {-1, 1, -2, -3, -1, 0, -1, -1},
+}
+
+// space returns the glue between two adjacent atoms in em.
+func space(left, right atom, s style) float64 {
+	mu := spacing[left][right]
+	if mu < 0 {
+		if s >= scriptStyle {
+			return 0
+		}
+		mu = -mu
+	}
+	skip := [4]float64{0, 3, 4, 5}[mu]
+	return skip * muSkip * s.size()
+}
@@ -0,0 +1,133 @@
+package svg
+
+// FontFamily is the font list of the text elements. The metrics embedded
+// in this package are those of Latin Modern Math, but no font is embedded
+// in the output: glyphs are drawn by the viewer, so a viewer without Latin
+// Modern Math falls back to the next font of the list. The positions of
+// the glyphs stay the same, while their widths and shapes may not match.
+const FontFamily = "Latin Modern Math, STIX Two Math, Cambria Math, serif"
+
+// metric holds the advance width, height above the baseline and depth
+// below the baseline of a glyph, in em at the base font size.
+type metric struct {
+	width, height, depth float64
+}
+
+// Font parameters in em, following the naming of TeX's \fontdimen values.
+const (
+	axisHeight     = 0.25  // center line of fractions and operators
+	ruleThickness  = 0.04  // fraction bars and radical overbars
+	xHeight        = 0.431 // height of lowercase letters without ascenders
+	numShiftUp     = 0.677 // display style numerator shift
+	denomShiftDown = 0.686 // display style denominator shift
+	numShiftText   = 0.394
+	denomShiftText = 0.345
+	supShift       = 0.413
+	subShift       = 0.15
+	bigOpSpacing   = 0.111 // gap between big operators and their limits
+	muSkip         = 1.0 / 18
+)
+
+// italicMetrics are the metrics of the math italic letters used for
+// variables (after Computer Modern Math Italic).
+var italicMetrics = map[rune]metric{
+	'a': {0.529, 0.431, 0}, 'b': {0.429, 0.694, 0}, 'c': {0.433, 0.431, 0},
+	'd': {0.520, 0.694, 0}, 'e': {0.466, 0.431, 0}, 'f': {0.490, 0.694, 0.194},
+	'g': {0.477, 0.431, 0.194}, 'h': {0.576, 0.694, 0}, 'i': {0.345, 0.660, 0},
+	'j': {0.412, 0.660, 0.194}, 'k': {0.521, 0.694, 0}, 'l': {0.298, 0.694, 0},
+	'm': {0.878, 0.431, 0}, 'n': {0.600, 0.431, 0}, 'o': {0.485, 0.431, 0},
+	'p': {0.503, 0.431, 0.194}, 'q': {0.446, 0.431, 0.194}, 'r': {0.451, 0.431, 0},
+	's': {0.469, 0.431, 0}, 't': {0.361, 0.615, 0}, 'u': {0.572, 0.431, 0},
+	'v': {0.485, 0.431, 0}, 'w': {0.716, 0.431, 0}, 'x': {0.572, 0.431, 0},
+	'y': {0.490, 0.431, 0.194}, 'z': {0.465, 0.431, 0},
+
+	'A': {0.750, 0.683, 0}, 'B': {0.759, 0.683, 0}, 'C': {0.715, 0.683, 0},
+	'D': {0.828, 0.683, 0}, 'E': {0.738, 0.683, 0}, 'F': {0.643, 0.683, 0},
+	'G': {0.786, 0.683, 0}, 'H': {0.831, 0.683, 0}, 'I': {0.440, 0.683, 0},
+	'J': {0.555, 0.683, 0}, 'K': {0.849, 0.683, 0}, 'L': {0.681, 0.683, 0},
+	'M': {0.970, 0.683, 0}, 'N': {0.803, 0.683, 0}, 'O': {0.763, 0.683, 0},
+	'P': {0.642, 0.683, 0}, 'Q': {0.791, 0.683, 0.194}, 'R': {0.759, 0.683, 0},
+	'S': {0.613, 0.683, 0}, 'T': {0.584, 0.683, 0}, 'U': {0.683, 0.683, 0},
+	'V': {0.583, 0.683, 0}, 'W': {0.944, 0.683, 0}, 'X': {0.828, 0.683, 0},
+	'Y': {0.581, 0.683, 0}, 'Z': {0.683, 0.683, 0},
+
+	'α': {0.640, 0.431, 0}, 'β': {0.566, 0.694, 0.194}, 'γ': {0.518, 0.431, 0.194},
+	'δ': {0.444, 0.694, 0}, 'ε': {0.466, 0.431, 0}, 'ζ': {0.438, 0.694, 0.194},
+	'η': {0.497, 0.431, 0.194}, 'θ': {0.469, 0.694, 0}, 'ι': {0.354, 0.431, 0},
+	'κ': {0.576, 0.431, 0}, 'λ': {0.583, 0.694, 0}, 'μ': {0.603, 0.431, 0.194},
+	'ν': {0.494, 0.431, 0}, 'ξ': {0.438, 0.694, 0.194}, 'ο': {0.485, 0.431, 0},
+	'π': {0.570, 0.431, 0}, 'ρ': {0.517, 0.431, 0.194}, 'σ': {0.571, 0.431, 0},
+	'τ': {0.437, 0.431, 0}, 'υ': {0.540, 0.431, 0}, 'φ': {0.596, 0.694, 0.194},
+	'χ': {0.626, 0.431, 0.194}, 'ψ': {0.651, 0.694, 0.194}, 'ω': {0.622, 0.431, 0},
+}
+
+// romanMetrics are the metrics of upright glyphs: digits, uppercase Greek,
+// function names and operators (after Computer Modern Roman and Symbol).
+var romanMetrics = map[rune]metric{
+	'0': {0.5, 0.644, 0}, '1': {0.5, 0.644, 0}, '2': {0.5, 0.644, 0},
+	'3': {0.5, 0.644, 0}, '4': {0.5, 0.644, 0}, '5': {0.5, 0.644, 0},
+	'6': {0.5, 0.644, 0}, '7': {0.5, 0.644, 0}, '8': {0.5, 0.644, 0},
+	'9': {0.5, 0.644, 0}, '.': {0.278, 0.106, 0},
+
+	'a': {0.500, 0.431, 0}, 'b': {0.556, 0.694, 0}, 'c': {0.444, 0.431, 0},
+	'd': {0.556, 0.694, 0}, 'e': {0.444, 0.431, 0}, 'f': {0.306, 0.694, 0},
+	'g': {0.500, 0.431, 0.194}, 'h': {0.556, 0.694, 0}, 'i': {0.278, 0.668, 0},
+	'j': {0.306, 0.668, 0.194}, 'k': {0.528, 0.694, 0}, 'l': {0.278, 0.694, 0},
+	'm': {0.833, 0.431, 0}, 'n': {0.556, 0.431, 0}, 'o': {0.500, 0.431, 0},
+	'p': {0.556, 0.431, 0.194}, 'q': {0.528, 0.431, 0.194}, 'r': {0.392, 0.431, 0},
+	's': {0.394, 0.431, 0}, 't': {0.389, 0.615, 0}, 'u': {0.556, 0.431, 0},
+	'v': {0.528, 0.431, 0}, 'w': {0.722, 0.431, 0}, 'x': {0.528, 0.431, 0},
+	'y': {0.528, 0.431, 0.194}, 'z': {0.444, 0.431, 0},
+
+	'Α': {0.750, 0.683, 0}, 'Β': {0.708, 0.683, 0}, 'Γ': {0.625, 0.683, 0},
+	'Δ': {0.833, 0.683, 0}, 'Ε': {0.681, 0.683, 0}, 'Ζ': {0.611, 0.683, 0},
+	'Η': {0.750, 0.683, 0}, 'Θ': {0.778, 0.683, 0}, 'Ι': {0.361, 0.683, 0},
+	'Κ': {0.778, 0.683, 0}, 'Λ': {0.694, 0.683, 0}, 'Μ': {0.917, 0.683, 0},
+	'Ν': {0.750, 0.683, 0}, 'Ξ': {0.667, 0.683, 0}, 'Ο': {0.778, 0.683, 0},
+	'Π': {0.750, 0.683, 0}, 'Ρ': {0.681, 0.683, 0}, 'Σ': {0.722, 0.683, 0},
+	'Τ': {0.722, 0.683, 0}, 'Υ': {0.778, 0.683, 0}, 'Φ': {0.722, 0.683, 0},
+	'Χ': {0.750, 0.683, 0}, 'Ψ': {0.778, 0.683, 0}, 'Ω': {0.722, 0.683, 0},
+
+	'+': {0.778, 0.583, 0.083}, '-': {0.778, 0.583, 0.083}, '−': {0.778, 0.583, 0.083},
+	'=': {0.778, 0.367, 0}, '<': {0.778, 0.540, 0.040}, '>': {0.778, 0.540, 0.040},
+	'*': {0.500, 0.465, 0}, '/': {0.500, 0.750, 0.250}, '·': {0.278, 0.310, 0},
+	'×': {0.778, 0.491, 0}, '÷': {0.778, 0.500, 0}, '±': {0.778, 0.583, 0.083},
+	'∓': {0.778, 0.583, 0.083}, '≤': {0.778, 0.636, 0.136}, '≥': {0.778, 0.636, 0.136},
+	'≠': {0.778, 0.716, 0.215}, '≈': {0.778, 0.483, 0}, '≡': {0.778, 0.464, 0},
+	'→': {1.000, 0.511, 0.011}, '←': {1.000, 0.511, 0.011}, '⇒': {1.000, 0.525, 0.024},
+	'∈': {0.667, 0.540, 0.040}, '∪': {0.667, 0.598, 0}, '∩': {0.667, 0.598, 0},
+
+	'(': {0.389, 0.750, 0.250}, ')': {0.389, 0.750, 0.250},
+	'[': {0.278, 0.750, 0.250}, ']': {0.278, 0.750, 0.250},
+	'{': {0.500, 0.750, 0.250}, '}': {0.500, 0.750, 0.250},
+	'|': {0.278, 0.750, 0.250}, '‖': {0.500, 0.750, 0.250},
+	'⟨': {0.389, 0.750, 0.250}, '⟩': {0.389, 0.750, 0.250},
+	'⌊': {0.444, 0.750, 0.250}, '⌋': {0.444, 0.750, 0.250},
+	'⌈': {0.444, 0.750, 0.250}, '⌉': {0.444, 0.750, 0.250},
+	'∖': {0.500, 0.750, 0.250},
+
+	'∑': {1.056, 0.750, 0.250}, '∏': {0.944, 0.750, 0.250}, '∫': {0.417, 0.805, 0.306},
+}
+
+// defaultMetric is used for glyphs missing from the tables.
+var defaultMetric = metric{0.6, 0.683, 0}
+
+// measure returns the metrics of s set in italic or upright glyphs.
+func measure(s string, italic bool) metric {
+	var m metric
+	for _, r := range s {
+		g, ok := romanMetrics[r]
+		if italic {
+			if it, found := italicMetrics[r]; found {
+				g, ok = it, true
+			}
+		}
+		if !ok {
+			g = defaultMetric
+		}
+		m.width += g.width
+		m.height = max(m.height, g.height)
+		m.depth = max(m.depth, g.depth)
+	}
+	return m
+}
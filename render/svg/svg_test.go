@@ -0,0 +1,111 @@
+package svg_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/svg"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func render(t *testing.T, input string, opts svg.Options) string {
+	t.Helper()
+	root, errs := parser.New(tokenizer.Tokenize(input)).Parse()
+	if len(errs) > 0 {
+		t.Fatalf("%s: unexpected parse errors: %v", input, errs)
+	}
+	var buf bytes.Buffer
+	if err := svg.Render(&buf, root, opts); err != nil {
+		t.Fatalf("%s: unexpected error: %v", input, err)
+	}
+	return buf.String()
+}
+
+func TestRender(t *testing.T) {
+	inputs := []string{
+		`\frac{a^2}{b}`,
+		`\left( \frac{1}{x} \right) + \sqrt[3]{x_1}`,
+		`\sum_{i=1}^n i \leq \int_0^1 x dx`,
+		`\binom{n}{k}`,
+	}
+	for _, input := range inputs {
+		out := render(t, input, svg.Options{})
+
+		// The output must be well-formed XML
+		dec := xml.NewDecoder(strings.NewReader(out))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: invalid XML: %v\n%s", input, err, out)
+			}
+		}
+
+		// and identical across runs
+		if again := render(t, input, svg.Options{}); again != out {
+			t.Errorf("%s: output is not deterministic", input)
+		}
+	}
+}
+
+func TestRenderFraction(t *testing.T) {
+	out := render(t, `\frac{a}{b}`, svg.Options{FontSize: 10})
+
+	for _, want := range []string{
+		`font-style="italic">a</text>`,
+		`font-style="italic">b</text>`,
+		`<rect `,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderPadding(t *testing.T) {
+	zero := 0.0
+	if out := render(t, `x`, svg.Options{FontSize: 10}); !strings.Contains(out, `width="9.72" height="8.31"`) {
+		t.Errorf("default padding: unexpected size in\n%s", out)
+	}
+	if out := render(t, `x`, svg.Options{FontSize: 10, Padding: &zero}); !strings.Contains(out, `width="5.72" height="4.31"`) {
+		t.Errorf("zero padding: unexpected size in\n%s", out)
+	}
+}
+
+func TestRenderFractionStyles(t *testing.T) {
+	// Inline, \dfrac keeps its parts at text size
+	if out := render(t, `\dfrac{a}{b}`, svg.Options{FontSize: 10, Inline: true}); !strings.Contains(out, `font-size="10"`) {
+		t.Errorf("\\dfrac: parts not at text size in\n%s", out)
+	}
+	if out := render(t, `\tfrac{a}{b}`, svg.Options{FontSize: 10}); !strings.Contains(out, `font-size="7"`) {
+		t.Errorf("\\tfrac: parts not at script size in\n%s", out)
+	}
+	if out := render(t, `{a \atop b}`, svg.Options{FontSize: 10}); strings.Contains(out, `<rect `) {
+		t.Errorf("\\atop: unexpected fraction bar in\n%s", out)
+	}
+}
+
+func TestRenderSizedDelimiters(t *testing.T) {
+	if out := render(t, `\big( x`, svg.Options{}); !strings.Contains(out, "scale(1 1.200)") {
+		t.Errorf("\\big: delimiter not scaled to 1.2 times its size in\n%s", out)
+	}
+	if out := render(t, `\Bigg( x`, svg.Options{}); !strings.Contains(out, "scale(1 3.000)") {
+		t.Errorf("\\Bigg: delimiter not scaled to three times its size in\n%s", out)
+	}
+
+	// Hand-built nodes with a size out of range are clamped
+	for _, size := range []int{0, 5} {
+		var buf bytes.Buffer
+		node := &ast.SizedDelimiterNode{Size: size, Delimiter: &ast.DelimiterNode{Kind: ast.LeftParen}}
+		if err := svg.Render(&buf, node, svg.Options{}); err != nil {
+			t.Errorf("size %d: unexpected error: %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package unicode_test
+
+import (
+	"fmt"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/unicode"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func ExampleRender() {
+	for _, input := range []string{
+		`\frac{a^2}{b}`,
+		`\alpha_1 \leq \sqrt{x}`,
+		`\sum_{i=1}^n x_i^2`,
+		`e^{i\pi} = -1`,
+		`\left\lfloor x^{\pi} \right\rfloor`,
+		`\lim_{x \to 0} \frac{\sin x}{x}`,
+		`a \cdot b + 2 \times -3`,
+	} {
+		root, _ := parser.New(tokenizer.Tokenize(input)).Parse()
+		fmt.Println(unicode.Render(root))
+	}
+
+	// Output:
+	// a²/b
+	// α₁ ≤ √x
+	// ∑ᵢ₌₁ⁿ xᵢ²
+	// e^(iπ) = -1
+	// ⌊x^π⌋
+	// lim_(x→0) (sin x)/x
+	// a · b + 2 × -3
+}
@@ -0,0 +1,72 @@
+package unicode
+
+// superscripts maps characters to their Unicode superscript form.
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴',
+	'5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾',
+
+	'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ',
+	'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ',
+	'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ', 'v': 'ᵛ',
+	'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ',
+
+	'A': 'ᴬ', 'B': 'ᴮ', 'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ',
+	'J': 'ᴶ', 'K': 'ᴷ', 'L': 'ᴸ', 'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ',
+	'R': 'ᴿ', 'T': 'ᵀ', 'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ',
+
+	'α': 'ᵅ', 'β': 'ᵝ', 'γ': 'ᵞ', 'δ': 'ᵟ', 'ε': 'ᵋ', 'θ': 'ᶿ', 'ι': 'ᶥ',
+	'φ': 'ᵠ', 'χ': 'ᵡ',
+}
+
+// subscripts maps characters to their Unicode subscript form.
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄',
+	'5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+	'+': '₊', '-': '₋', '=': '₌', '(': '₍', ')': '₎',
+
+	'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ',
+	'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ', 't': 'ₜ',
+	'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+
+	'β': 'ᵦ', 'γ': 'ᵧ', 'ρ': 'ᵨ', 'φ': 'ᵩ', 'χ': 'ᵪ',
+}
+
+// bigOperators maps the operators of ast.LimitedOperatorNode to glyphs.
+var bigOperators = map[string]string{
+	"int":  "∫",
+	"sum":  "∑",
+	"prod": "∏",
+	"lim":  "lim",
+}
+
+// vulgarFractions maps numerator/denominator pairs to single code points.
+var vulgarFractions = map[string]string{
+	"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾",
+	"1/5": "⅕", "2/5": "⅖", "3/5": "⅗", "4/5": "⅘", "1/6": "⅙",
+	"5/6": "⅚", "1/7": "⅐", "1/8": "⅛", "3/8": "⅜", "5/8": "⅝",
+	"7/8": "⅞", "1/9": "⅑", "1/10": "⅒",
+}
+
+// spacedOperators are rendered with a space on either side outside scripts.
+var spacedOperators = map[string]bool{
+	"+": true, "-": true, "±": true, "∓": true, "×": true, "·": true, "÷": true,
+	"=": true, "<": true, ">": true, "≤": true, "≥": true, "≠": true,
+	"≈": true, "≡": true, "∼": true, "≃": true, "≅": true, "∝": true,
+	"≪": true, "≫": true, "∈": true, "∉": true, "⊂": true, "⊃": true,
+	"→": true, "←": true, "⇒": true, "⇐": true, "⇔": true, "↦": true,
+}
+
+// mapRunes converts every rune of s using table. It reports false if any
+// rune has no mapping.
+func mapRunes(s string, table map[rune]rune) (string, bool) {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		m, ok := table[r]
+		if !ok {
+			return "", false
+		}
+		out = append(out, m)
+	}
+	return string(out), true
+}
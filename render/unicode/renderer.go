@@ -0,0 +1,226 @@
+// Package unicode renders the AST as single-line Unicode plain text,
+// e.g. \frac{a^2}{b} as "a²/b" and \alpha_1 \leq \sqrt{x} as "α₁ ≤ √x".
+package unicode
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/neox5/texmax/ast"
+)
+
+// Render returns the Unicode plain-text form of node.
+func Render(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+	r := NewRenderer()
+	ast.Walk(r, node)
+	return r.String()
+}
+
+// Renderer is an ast.Visitor that builds a Unicode plain-text string.
+type Renderer struct {
+	sb     *strings.Builder
+	script int // nesting level of super- and subscripts
+}
+
+// NewRenderer creates a new Renderer
+func NewRenderer() *Renderer {
+	return &Renderer{sb: &strings.Builder{}}
+}
+
+// String returns the text rendered so far.
+func (r *Renderer) String() string {
+	return r.sb.String()
+}
+
+// render renders n into a separate string without touching the output.
+func (r *Renderer) render(n ast.Node) string {
+	saved := r.sb
+	r.sb = &strings.Builder{}
+	n.Accept(r)
+	out := r.sb.String()
+	r.sb = saved
+	return out
+}
+
+// renderScript renders a super- or subscript using the code points in table,
+// falling back to marker followed by the text, parenthesized if needed.
+func (r *Renderer) renderScript(n ast.Node, table map[rune]rune, marker string) string {
+	r.script++
+	text := r.render(n)
+	r.script--
+
+	if mapped, ok := mapRunes(text, table); ok && text != "" {
+		return mapped
+	}
+	if utf8.RuneCountInString(text) == 1 {
+		return marker + text
+	}
+	return marker + "(" + text + ")"
+}
+
+// renderOperand renders n, adding parentheses if it consists of several
+// elements, as needed for fraction parts and radicands.
+func (r *Renderer) renderOperand(n ast.Node) string {
+	text := r.render(n)
+	if expr, ok := n.(*ast.ExpressionNode); ok && len(expr.Elements) > 1 {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// Visit methods for container nodes
+func (r *Renderer) VisitExpressionNode(node *ast.ExpressionNode) {
+	for i, element := range node.Elements {
+		text := r.render(element)
+
+		if i > 0 && needsSpace(node.Elements[i-1]) && !isOpeningParen(element) {
+			r.sb.WriteString(" ")
+		}
+
+		// Binary operators are spaced, unary ones are not
+		if op, ok := element.(*ast.OperatorNode); ok && r.script == 0 && spacedOperators[op.Value] {
+			if i == 0 || isOperator(node.Elements[i-1]) {
+				r.sb.WriteString(text)
+			} else {
+				r.sb.WriteString(" " + text + " ")
+			}
+			continue
+		}
+		r.sb.WriteString(text)
+	}
+}
+
+func (r *Renderer) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	node.LeftDelimiter.Accept(r)
+	if node.Content != nil {
+		node.Content.Accept(r)
+	}
+	node.RightDelimiter.Accept(r)
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (r *Renderer) VisitErrorNode(node *ast.ErrorNode) {
+	r.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (r *Renderer) VisitSymbolNode(node *ast.SymbolNode) {
+	r.sb.WriteString(node.Value)
+}
+
+func (r *Renderer) VisitNumberNode(node *ast.NumberNode) {
+	r.sb.WriteString(node.Value)
+}
+
+func (r *Renderer) VisitOperatorNode(node *ast.OperatorNode) {
+	r.sb.WriteString(node.Value)
+}
+
+func (r *Renderer) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	r.sb.WriteString(node.Name)
+}
+
+func (r *Renderer) VisitSpaceNode(node *ast.SpaceNode) {
+	r.sb.WriteString(" ")
+}
+
+func (r *Renderer) VisitDelimiterNode(node *ast.DelimiterNode) {
+	r.sb.WriteString(node.Kind.Glyph())
+}
+
+// Visit methods for composite nodes
+func (r *Renderer) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	node.Base.Accept(r)
+	r.sb.WriteString(r.renderScript(node.Exponent, superscripts, "^"))
+}
+
+func (r *Renderer) VisitSubscriptNode(node *ast.SubscriptNode) {
+	node.Base.Accept(r)
+	r.sb.WriteString(r.renderScript(node.Subscript, subscripts, "_"))
+}
+
+func (r *Renderer) VisitFractionNode(node *ast.FractionNode) {
+	num := r.render(node.Numerator)
+	den := r.render(node.Denominator)
+	if vulgar, ok := vulgarFractions[num+"/"+den]; ok {
+		r.sb.WriteString(vulgar)
+		return
+	}
+	r.sb.WriteString(r.renderOperand(node.Numerator) + "/" + r.renderOperand(node.Denominator))
+}
+
+func (r *Renderer) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	if glyph, ok := bigOperators[node.Operator]; ok {
+		r.sb.WriteString(glyph)
+	} else {
+		r.sb.WriteString(node.Operator)
+	}
+	if node.LowerLimit != nil {
+		r.sb.WriteString(r.renderScript(node.LowerLimit, subscripts, "_"))
+	}
+	if node.UpperLimit != nil {
+		r.sb.WriteString(r.renderScript(node.UpperLimit, superscripts, "^"))
+	}
+}
+
+func (r *Renderer) VisitSqrtNode(node *ast.SqrtNode) {
+	if node.Index != nil {
+		switch index := r.render(node.Index); index {
+		case "2":
+			r.sb.WriteString("√")
+		case "3":
+			r.sb.WriteString("∛")
+		case "4":
+			r.sb.WriteString("∜")
+		default:
+			r.sb.WriteString(r.renderScript(node.Index, superscripts, "^") + "√")
+		}
+	} else {
+		r.sb.WriteString("√")
+	}
+	if node.Radicand != nil {
+		r.sb.WriteString(r.renderOperand(node.Radicand))
+	}
+}
+
+func (r *Renderer) VisitBinomNode(node *ast.BinomNode) {
+	r.sb.WriteString("C(" + r.render(node.Upper) + ", " + r.render(node.Lower) + ")")
+}
+
+func (r *Renderer) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	node.Delimiter.Accept(r)
+}
+
+// needsSpace reports whether the element following n must be separated
+// by a space, as in "sin x" or "∑ᵢ xᵢ".
+func needsSpace(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.NonArgumentFunctionNode, *ast.LimitedOperatorNode:
+		return true
+	case *ast.SuperscriptNode:
+		return needsSpace(node.Base)
+	case *ast.SubscriptNode:
+		return needsSpace(node.Base)
+	}
+	return false
+}
+
+// isOpeningParen reports whether n starts with a parenthesis that makes
+// a function call like "sin(x)" unambiguous.
+func isOpeningParen(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.DelimiterNode:
+		return node.Kind == ast.LeftParen
+	case *ast.DelimitedExpressionNode:
+		return isOpeningParen(node.LeftDelimiter)
+	}
+	return false
+}
+
+func isOperator(n ast.Node) bool {
+	_, ok := n.(*ast.OperatorNode)
+	return ok
+}
@@ -0,0 +1,179 @@
+// Package semantic turns the presentation-oriented AST produced by the parser
+// into an operator tree that states what a formula means rather than how it
+// is written. Exporters for computer algebra systems and code generators are
+// built on top of this tree.
+package semantic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr represents a node in the semantic expression tree.
+type Expr interface {
+	// String returns the expression in a compact prefix notation,
+	// e.g. "(divide (power a 2) b)".
+	String() string
+}
+
+// Op identifies the operation applied by an Apply or Bind expression.
+type Op int
+
+const (
+	// Arithmetic
+	Plus      Op = iota // a + b + ...
+	Minus               // a - b
+	Negate              // -a
+	Times               // a * b * ...
+	Divide              // a / b
+	Mod                 // a mod b
+	Power               // a^b
+	Root                // root of Args[0] with optional degree Args[1]
+	PlusMinus           // a ± b
+
+	// Relations
+	Equal     // a = b
+	NotEqual  // a ≠ b
+	Less      // a < b
+	LessEq    // a ≤ b
+	Greater   // a > b
+	GreaterEq // a ≥ b
+	Approx    // a ≈ b
+
+	// Fences and functions
+	Abs      // |a|
+	Floor    // ⌊a⌋
+	Ceil     // ⌈a⌉
+	Binomial // binom(n, k)
+	Index    // a_{i+1}, subscripts that do not form a plain name
+	Function // named function such as sin, log, max
+
+	// Binding operators (used by Bind)
+	Sum
+	Product
+	Integral
+	Limit
+)
+
+var opNames = map[Op]string{
+	Plus:      "plus",
+	Minus:     "minus",
+	Negate:    "negate",
+	Times:     "times",
+	Divide:    "divide",
+	Mod:       "mod",
+	Power:     "power",
+	Root:      "root",
+	PlusMinus: "plusminus",
+	Equal:     "eq",
+	NotEqual:  "neq",
+	Less:      "lt",
+	LessEq:    "leq",
+	Greater:   "gt",
+	GreaterEq: "geq",
+	Approx:    "approx",
+	Abs:       "abs",
+	Floor:     "floor",
+	Ceil:      "ceiling",
+	Binomial:  "binomial",
+	Index:     "index",
+	Function:  "function",
+	Sum:       "sum",
+	Product:   "product",
+	Integral:  "int",
+	Limit:     "limit",
+}
+
+// String returns the lowercase name of the operation.
+func (op Op) String() string {
+	if name, ok := opNames[op]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// IsRelation reports whether op compares two expressions.
+func (op Op) IsRelation() bool {
+	return op >= Equal && op <= Approx
+}
+
+// Number is a numeric literal.
+type Number struct {
+	Value string
+}
+
+func (e *Number) String() string { return e.Value }
+
+// Ident is a variable. Simple subscripts such as x_1 or a_{ij} are treated
+// as part of the name and kept in Subscript.
+type Ident struct {
+	Name      string
+	Subscript string
+}
+
+func (e *Ident) String() string {
+	if e.Subscript != "" {
+		return e.Name + "_" + e.Subscript
+	}
+	return e.Name
+}
+
+// Apply applies an operation to its arguments. For Function, Func holds the
+// function name (e.g. "sin").
+type Apply struct {
+	Op   Op
+	Func string
+	Args []Expr
+}
+
+func (e *Apply) String() string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	if e.Op == Function {
+		sb.WriteString(e.Func)
+	} else {
+		sb.WriteString(e.Op.String())
+	}
+	for _, arg := range e.Args {
+		sb.WriteString(" ")
+		sb.WriteString(arg.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Bind is an operation that binds a variable: sums, products, integrals and
+// limits. Lower and Upper are optional; for Limit, Lower is the value the
+// variable tends to. Var is nil for integrals without a differential.
+type Bind struct {
+	Op    Op
+	Var   *Ident
+	Lower Expr
+	Upper Expr
+	Body  Expr
+}
+
+func (e *Bind) String() string {
+	parts := []string{e.Op.String()}
+	if e.Var != nil {
+		parts = append(parts, "(bvar "+e.Var.String()+")")
+	}
+	if e.Lower != nil {
+		parts = append(parts, "(lower "+e.Lower.String()+")")
+	}
+	if e.Upper != nil {
+		parts = append(parts, "(upper "+e.Upper.String()+")")
+	}
+	parts = append(parts, e.Body.String())
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// Error describes why a part of the AST has no semantic interpretation.
+type Error struct {
+	Message string
+	Pos     int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
@@ -0,0 +1,719 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neox5/texmax/ast"
+)
+
+// Structure converts a parsed AST into a semantic expression tree.
+// The flat element lists of ExpressionNodes are grouped by operator
+// precedence: relations bind weakest, then addition, then multiplication
+// (explicit or implicit), then unary minus, function application and powers.
+func Structure(node ast.Node) (Expr, error) {
+	if node == nil {
+		return nil, &Error{Message: "empty expression", Pos: 0}
+	}
+	s := &structurer{}
+	return s.convert(node)
+}
+
+// Operator tables keyed by the value of ast.OperatorNode.
+var (
+	relationOps = map[string]Op{
+		"=": Equal,
+		"≠": NotEqual,
+		"<": Less,
+		"≤": LessEq,
+		">": Greater,
+		"≥": GreaterEq,
+		"≈": Approx,
+	}
+
+	additiveOps = map[string]Op{
+		"+": Plus,
+		"-": Minus,
+		"±": PlusMinus,
+	}
+
+	multiplicativeOps = map[string]Op{
+		"*": Times,
+		"·": Times,
+		"×": Times,
+		"/": Divide,
+		"÷": Divide,
+	}
+
+	bindingOps = map[string]Op{
+		"sum":  Sum,
+		"prod": Product,
+		"int":  Integral,
+		"lim":  Limit,
+	}
+)
+
+// structurer is an ast.Visitor that converts a single node into an Expr.
+type structurer struct {
+	result Expr
+	err    error
+}
+
+// convert visits n and returns the resulting expression.
+func (s *structurer) convert(n ast.Node) (Expr, error) {
+	s.result, s.err = nil, nil
+	n.Accept(s)
+	return s.result, s.err
+}
+
+func (s *structurer) set(e Expr, err error) {
+	s.result, s.err = e, err
+}
+
+func (s *structurer) fail(pos int, format string, args ...any) {
+	s.set(nil, &Error{Message: fmt.Sprintf(format, args...), Pos: pos})
+}
+
+// Visit methods for container nodes
+func (s *structurer) VisitExpressionNode(node *ast.ExpressionNode) {
+	var elems []ast.Node
+	for _, el := range node.Elements {
+		switch el := el.(type) {
+		case *ast.SpaceNode:
+		case *ast.SizedDelimiterNode:
+			// The size of a delimiter does not change its meaning
+			elems = append(elems, el.Delimiter)
+		default:
+			elems = append(elems, el)
+		}
+	}
+	if len(elems) == 0 {
+		s.fail(node.Start, "empty expression")
+		return
+	}
+	q := &sequence{s: s, elems: elems}
+	s.set(q.parse())
+}
+
+func (s *structurer) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	left := delimiterKind(node.LeftDelimiter)
+	right := delimiterKind(node.RightDelimiter)
+	if node.Content == nil {
+		s.fail(node.Start, "empty delimited expression")
+		return
+	}
+	if len(node.Middle) > 0 {
+		s.fail(node.Middle[0].Start, "unsupported \\middle separator")
+		return
+	}
+	content, err := s.convert(node.Content)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+
+	// Bars pair whether or not they are marked as opening and closing
+	switch left.Glyph() + right.Glyph() {
+	case "()", "[]", "{}":
+		s.set(content, nil)
+	case "||":
+		s.set(&Apply{Op: Abs, Args: []Expr{content}}, nil)
+	case "⌊⌋":
+		s.set(&Apply{Op: Floor, Args: []Expr{content}}, nil)
+	case "⌈⌉":
+		s.set(&Apply{Op: Ceil, Args: []Expr{content}}, nil)
+	default:
+		s.fail(node.Start, "unsupported delimiters %s...%s", left, right)
+	}
+}
+
+func (s *structurer) VisitErrorNode(node *ast.ErrorNode) {
+	s.fail(node.Start, "%s", node.Message)
+}
+
+// Visit methods for leaf nodes
+func (s *structurer) VisitSymbolNode(node *ast.SymbolNode) {
+	s.set(&Ident{Name: node.Value}, nil)
+}
+
+func (s *structurer) VisitNumberNode(node *ast.NumberNode) {
+	s.set(&Number{Value: node.Value}, nil)
+}
+
+func (s *structurer) VisitOperatorNode(node *ast.OperatorNode) {
+	s.fail(node.Start, "unexpected operator %q", node.Value)
+}
+
+func (s *structurer) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	s.fail(node.Start, "missing argument for \\%s", node.Name)
+}
+
+func (s *structurer) VisitSpaceNode(node *ast.SpaceNode) {
+	s.fail(node.Start, "unexpected space")
+}
+
+func (s *structurer) VisitDelimiterNode(node *ast.DelimiterNode) {
+	s.fail(node.Start, "unmatched delimiter %q", node.Kind)
+}
+
+func (s *structurer) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	node.Delimiter.Accept(s)
+}
+
+// Visit methods for composite nodes
+func (s *structurer) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	base, err := s.convert(node.Base)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	exp, err := s.convert(node.Exponent)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	s.set(&Apply{Op: Power, Args: []Expr{base, exp}}, nil)
+}
+
+func (s *structurer) VisitSubscriptNode(node *ast.SubscriptNode) {
+	if sym, ok := node.Base.(*ast.SymbolNode); ok {
+		if sub, ok := plainSubscript(node.Subscript); ok {
+			s.set(&Ident{Name: sym.Value, Subscript: sub}, nil)
+			return
+		}
+	}
+	base, err := s.convert(node.Base)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	idx, err := s.convert(node.Subscript)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	s.set(&Apply{Op: Index, Args: []Expr{base, idx}}, nil)
+}
+
+func (s *structurer) VisitFractionNode(node *ast.FractionNode) {
+	if !node.Ruled() {
+		s.fail(node.Start, "unsupported fraction without a bar")
+		return
+	}
+	num, err := s.convert(node.Numerator)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	den, err := s.convert(node.Denominator)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	s.set(&Apply{Op: Divide, Args: []Expr{num, den}}, nil)
+}
+
+func (s *structurer) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	s.fail(node.Start, "missing operand for \\%s", node.Operator)
+}
+
+func (s *structurer) VisitSqrtNode(node *ast.SqrtNode) {
+	if node.Radicand == nil {
+		s.fail(node.Start, "missing radicand")
+		return
+	}
+	radicand, err := s.convert(node.Radicand)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	args := []Expr{radicand}
+	if node.Index != nil {
+		idx, err := s.convert(node.Index)
+		if err != nil {
+			s.set(nil, err)
+			return
+		}
+		args = append(args, idx)
+	}
+	s.set(&Apply{Op: Root, Args: args}, nil)
+}
+
+func (s *structurer) VisitBinomNode(node *ast.BinomNode) {
+	upper, err := s.convert(node.Upper)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	lower, err := s.convert(node.Lower)
+	if err != nil {
+		s.set(nil, err)
+		return
+	}
+	s.set(&Apply{Op: Binomial, Args: []Expr{upper, lower}}, nil)
+}
+
+// sequence is a precedence parser over the elements of an ExpressionNode.
+type sequence struct {
+	s       *structurer
+	elems   []ast.Node
+	pos     int
+	closing ast.Delimiter // delimiter that closes the innermost open group
+}
+
+func (q *sequence) peek() ast.Node {
+	if q.pos < len(q.elems) {
+		return q.elems[q.pos]
+	}
+	return nil
+}
+
+// endPos returns the position used for errors at the end of the sequence.
+func (q *sequence) endPos() int {
+	if q.pos > 0 && q.pos <= len(q.elems) {
+		return q.elems[q.pos-1].End()
+	}
+	if len(q.elems) > 0 {
+		return q.elems[0].Pos()
+	}
+	return 0
+}
+
+// peekOp returns the operation of the next element if it is an operator
+// listed in table.
+func (q *sequence) peekOp(table map[string]Op) (Op, bool) {
+	op, ok := q.peek().(*ast.OperatorNode)
+	if !ok {
+		return 0, false
+	}
+	kind, ok := table[op.Value]
+	return kind, ok
+}
+
+func (q *sequence) parse() (Expr, error) {
+	e, err := q.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	if n := q.peek(); n != nil {
+		return nil, q.unexpected(n)
+	}
+	return e, nil
+}
+
+func (q *sequence) parseRelation() (Expr, error) {
+	left, err := q.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := q.peekOp(relationOps)
+		if !ok {
+			return left, nil
+		}
+		q.pos++
+		right, err := q.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &Apply{Op: op, Args: []Expr{left, right}}
+	}
+}
+
+func (q *sequence) parseAdditive() (Expr, error) {
+	left, err := q.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := q.peekOp(additiveOps)
+		if !ok {
+			return left, nil
+		}
+		q.pos++
+		right, err := q.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = combine(op, left, right)
+	}
+}
+
+func (q *sequence) parseTerm() (Expr, error) {
+	left, err := q.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if op, ok := q.peekOp(multiplicativeOps); ok {
+			q.pos++
+			right, err := q.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			left = combine(op, left, right)
+			continue
+		}
+		if fn, ok := q.peek().(*ast.NonArgumentFunctionNode); ok && fn.Name == "mod" {
+			q.pos++
+			right, err := q.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			left = &Apply{Op: Mod, Args: []Expr{left, right}}
+			continue
+		}
+		if !q.startsOperand() {
+			return left, nil
+		}
+		// Juxtaposition is implicit multiplication
+		right, err := q.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = combine(Times, left, right)
+	}
+}
+
+func (q *sequence) parseUnary() (Expr, error) {
+	if op, ok := q.peekOp(additiveOps); ok {
+		q.pos++
+		operand, err := q.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case Minus:
+			return &Apply{Op: Negate, Args: []Expr{operand}}, nil
+		case Plus:
+			return operand, nil
+		default:
+			return nil, &Error{Message: "unexpected unary operator", Pos: q.elems[q.pos-1].Pos()}
+		}
+	}
+	return q.parseFactor()
+}
+
+// startsOperand reports whether the next element can begin a factor.
+func (q *sequence) startsOperand() bool {
+	switch n := q.peek().(type) {
+	case nil, *ast.OperatorNode:
+		return false
+	case *ast.NonArgumentFunctionNode:
+		return n.Name != "mod"
+	default:
+		base, _ := q.s.splitScripts(n)
+		if d, ok := base.(*ast.DelimiterNode); ok {
+			return d.Kind.Class() != ast.CloseDelimiter && d.Kind != q.closing
+		}
+		return true
+	}
+}
+
+func (q *sequence) parseFactor() (Expr, error) {
+	n := q.peek()
+	if n == nil {
+		return nil, &Error{Message: "missing operand", Pos: q.endPos()}
+	}
+
+	switch node := n.(type) {
+	case *ast.OperatorNode:
+		return nil, q.unexpected(n)
+	case *ast.DelimiterNode:
+		switch node.Kind {
+		case ast.LeftParen, ast.LeftBracket:
+			return q.parseGroup(node, node.Kind.Mirror(), nil)
+		case ast.Vert:
+			return q.parseGroup(node, ast.Vert, func(e Expr) Expr {
+				return &Apply{Op: Abs, Args: []Expr{e}}
+			})
+		}
+		return nil, q.unexpected(n)
+	case *ast.LimitedOperatorNode:
+		q.pos++
+		return q.parseBind(node)
+	case *ast.SubscriptNode:
+		// \log_b x
+		if fn, ok := node.Base.(*ast.NonArgumentFunctionNode); ok && fn.Name == "log" {
+			q.pos++
+			base, err := q.s.convert(node.Subscript)
+			if err != nil {
+				return nil, err
+			}
+			arg, err := q.parseFactor()
+			if err != nil {
+				return nil, err
+			}
+			return &Apply{Op: Function, Func: "log", Args: []Expr{arg, base}}, nil
+		}
+	}
+
+	base, wrap := q.s.splitScripts(n)
+	switch b := base.(type) {
+	case *ast.NonArgumentFunctionNode:
+		// \sin x and \sin^2 x apply the function to the following factor
+		q.pos++
+		arg, err := q.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return wrap(&Apply{Op: Function, Func: b.Name, Args: []Expr{arg}})
+	case *ast.DelimiterNode:
+		return nil, &Error{Message: fmt.Sprintf("unmatched delimiter %q", b.Kind), Pos: b.Start}
+	}
+
+	q.pos++
+	return q.s.convert(n)
+}
+
+// parseGroup parses the elements between an opening delimiter and the
+// matching closing delimiter. Scripts attached to the closing delimiter,
+// as in (a+b)^2, apply to the whole group.
+func (q *sequence) parseGroup(open *ast.DelimiterNode, closing ast.Delimiter, fence func(Expr) Expr) (Expr, error) {
+	q.pos++ // consume opening delimiter
+
+	saved := q.closing
+	q.closing = closing
+	inner, err := q.parseRelation()
+	q.closing = saved
+	if err != nil {
+		return nil, err
+	}
+
+	n := q.peek()
+	base, wrap := q.s.splitScripts(n)
+	if d, ok := base.(*ast.DelimiterNode); !ok || d.Kind != closing {
+		return nil, &Error{Message: fmt.Sprintf("missing closing %q", closing), Pos: open.Start}
+	}
+	q.pos++ // consume closing delimiter
+
+	if fence != nil {
+		inner = fence(inner)
+	}
+	return wrap(inner)
+}
+
+// parseBind parses the operand of a big operator. Sums, products and limits
+// take the following term as their body; integrals extend up to the
+// matching differential (e.g. "dx").
+func (q *sequence) parseBind(node *ast.LimitedOperatorNode) (Expr, error) {
+	op, ok := bindingOps[node.Operator]
+	if !ok {
+		return nil, &Error{Message: fmt.Sprintf("unsupported operator \\%s", node.Operator), Pos: node.Start}
+	}
+	b := &Bind{Op: op}
+
+	var err error
+	switch op {
+	case Sum, Product:
+		b.Var, b.Lower, err = q.s.binding(node.LowerLimit, "=")
+	case Limit:
+		b.Var, b.Lower, err = q.s.binding(node.LowerLimit, "→")
+		if err == nil && (b.Var == nil || b.Lower == nil) {
+			err = &Error{Message: "limit needs a subscript of the form x \\to a", Pos: node.Start}
+		}
+	case Integral:
+		if node.LowerLimit != nil {
+			b.Lower, err = q.s.convert(node.LowerLimit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if node.UpperLimit != nil {
+		if b.Upper, err = q.s.convert(node.UpperLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	if op == Integral {
+		if d := q.findDifferential(); d >= 0 {
+			body := &sequence{s: q.s, elems: q.elems[q.pos:d]}
+			if len(body.elems) == 0 {
+				return nil, &Error{Message: "missing integrand", Pos: node.Start}
+			}
+			if b.Body, err = body.parse(); err != nil {
+				return nil, err
+			}
+			b.Var, _ = q.s.ident(q.elems[d+1])
+			q.pos = d + 2
+			return b, nil
+		}
+	}
+
+	if q.peek() == nil {
+		return nil, &Error{Message: fmt.Sprintf("missing operand for \\%s", node.Operator), Pos: node.Start}
+	}
+	if b.Body, err = q.parseTerm(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// findDifferential returns the index of the "d" that starts the differential
+// belonging to the current integral, skipping the differentials of nested
+// integrals, or -1 if there is none.
+func (q *sequence) findDifferential() int {
+	depth := 0
+	for i := q.pos; i+1 < len(q.elems); i++ {
+		if op, ok := q.elems[i].(*ast.LimitedOperatorNode); ok && op.Operator == "int" {
+			depth++
+			continue
+		}
+		d, ok := q.elems[i].(*ast.SymbolNode)
+		if !ok || d.Value != "d" {
+			continue
+		}
+		if _, ok := q.s.ident(q.elems[i+1]); !ok {
+			continue
+		}
+		if depth == 0 {
+			return i
+		}
+		depth--
+		i++
+	}
+	return -1
+}
+
+func (q *sequence) unexpected(n ast.Node) error {
+	switch node := n.(type) {
+	case *ast.OperatorNode:
+		return &Error{Message: fmt.Sprintf("unexpected operator %q", node.Value), Pos: node.Start}
+	case *ast.DelimiterNode:
+		return &Error{Message: fmt.Sprintf("unmatched delimiter %q", node.Kind), Pos: node.Start}
+	case *ast.NonArgumentFunctionNode:
+		return &Error{Message: fmt.Sprintf("unexpected \\%s", node.Name), Pos: node.Start}
+	}
+	return &Error{Message: "unexpected element", Pos: n.Pos()}
+}
+
+// splitScripts strips superscripts and subscripts from n and returns the
+// innermost base together with a function that re-applies the scripts to
+// the expression that replaces the base.
+func (s *structurer) splitScripts(n ast.Node) (ast.Node, func(Expr) (Expr, error)) {
+	switch node := n.(type) {
+	case *ast.SuperscriptNode:
+		base, wrap := s.splitScripts(node.Base)
+		return base, func(e Expr) (Expr, error) {
+			inner, err := wrap(e)
+			if err != nil {
+				return nil, err
+			}
+			exp, err := s.convert(node.Exponent)
+			if err != nil {
+				return nil, err
+			}
+			return &Apply{Op: Power, Args: []Expr{inner, exp}}, nil
+		}
+	case *ast.SubscriptNode:
+		base, wrap := s.splitScripts(node.Base)
+		return base, func(e Expr) (Expr, error) {
+			inner, err := wrap(e)
+			if err != nil {
+				return nil, err
+			}
+			idx, err := s.convert(node.Subscript)
+			if err != nil {
+				return nil, err
+			}
+			return &Apply{Op: Index, Args: []Expr{inner, idx}}, nil
+		}
+	}
+	return n, func(e Expr) (Expr, error) { return e, nil }
+}
+
+// binding splits a limit such as "i=1" or "x \to 0" at the given relation
+// into the bound variable and the remaining expression. A limit consisting
+// of a single variable only yields the variable.
+func (s *structurer) binding(limit ast.Node, relation string) (*Ident, Expr, error) {
+	if limit == nil {
+		return nil, nil, nil
+	}
+	if v, ok := s.ident(limit); ok {
+		return v, nil, nil
+	}
+
+	var elems []ast.Node
+	if expr, ok := limit.(*ast.ExpressionNode); ok {
+		elems = expr.Elements
+	}
+	if len(elems) == 1 {
+		return s.binding(elems[0], relation)
+	}
+	if len(elems) > 2 {
+		v, ok := s.ident(elems[0])
+		op, isOp := elems[1].(*ast.OperatorNode)
+		if ok && isOp && op.Value == relation {
+			rest := &sequence{s: s, elems: elems[2:]}
+			e, err := rest.parse()
+			return v, e, err
+		}
+	}
+	return nil, nil, &Error{Message: "cannot determine bound variable", Pos: limit.Pos()}
+}
+
+// ident returns the variable denoted by n, if n is a symbol with an
+// optional plain subscript.
+func (s *structurer) ident(n ast.Node) (*Ident, bool) {
+	switch node := n.(type) {
+	case *ast.SymbolNode:
+		return &Ident{Name: node.Value}, true
+	case *ast.SubscriptNode:
+		sym, ok := node.Base.(*ast.SymbolNode)
+		if !ok {
+			return nil, false
+		}
+		if sub, ok := plainSubscript(node.Subscript); ok {
+			return &Ident{Name: sym.Value, Subscript: sub}, true
+		}
+	case *ast.ExpressionNode:
+		if len(node.Elements) == 1 {
+			return s.ident(node.Elements[0])
+		}
+	}
+	return nil, false
+}
+
+// combine applies a binary operation, flattening nested sums and products.
+func combine(op Op, left, right Expr) Expr {
+	if op == Plus || op == Times {
+		if l, ok := left.(*Apply); ok && l.Op == op {
+			l.Args = append(l.Args, right)
+			return l
+		}
+	}
+	return &Apply{Op: op, Args: []Expr{left, right}}
+}
+
+// plainSubscript returns the text of a subscript made only of symbols and
+// numbers, such as the "1" in x_1 or the "ij" in a_{ij}.
+func plainSubscript(n ast.Node) (string, bool) {
+	switch node := n.(type) {
+	case *ast.SymbolNode:
+		return node.Value, true
+	case *ast.NumberNode:
+		return node.Value, true
+	case *ast.ExpressionNode:
+		if len(node.Elements) == 0 {
+			return "", false
+		}
+		var sb strings.Builder
+		for _, el := range node.Elements {
+			part, ok := plainSubscript(el)
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(part)
+		}
+		return sb.String(), true
+	}
+	return "", false
+}
+
+func delimiterKind(n ast.Node) ast.Delimiter {
+	if d, ok := n.(*ast.DelimiterNode); ok {
+		return d.Kind
+	}
+	return ast.NoDelimiter
+}
@@ -0,0 +1,53 @@
+package semantic_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/semantic"
+	"github.com/neox5/texmax/tokenizer"
+)
+
+func TestStructure(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`\frac{a^2}{b}`, "(divide (power a 2) b)"},
+		{`a + b - c`, "(minus (plus a b) c)"},
+		{`2x y`, "(times 2 x y)"},
+		{`-a b`, "(negate (times a b))"},
+		{`(a+b)^2`, "(power (plus a b) 2)"},
+		{`x_1 + a_{ij}`, "(plus x_1 a_ij)"},
+		{`\sin^2 x + \cos^2 x = 1`, "(eq (plus (power (sin x) 2) (power (cos x) 2)) 1)"},
+		{`|x| \leq \sqrt[3]{y}`, "(leq (abs x) (root y 3))"},
+		{`\sum_{i=1}^n i^2 + 1`, "(plus (sum (bvar i) (lower 1) (upper n) (power i 2)) 1)"},
+		{`\int_0^1 x^2 dx`, "(int (bvar x) (lower 0) (upper 1) (power x 2))"},
+		{`\lim_{x \to 0} \frac{\sin x}{x}`, "(limit (bvar x) (lower 0) (divide (sin x) x))"},
+		{`\binom{n}{k}`, "(binomial n k)"},
+	}
+
+	for _, tt := range tests {
+		root, errs := parser.New(tokenizer.Tokenize(tt.input)).Parse()
+		if len(errs) > 0 {
+			t.Fatalf("%s: unexpected parse errors: %v", tt.input, errs)
+		}
+		expr, err := semantic.Structure(root)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStructureErrors(t *testing.T) {
+	for _, input := range []string{`(a + b`, `a +`, `\sum`, `\lim_{x} x`, `{a \atop b}`} {
+		root, _ := parser.New(tokenizer.Tokenize(input)).Parse()
+		if _, err := semantic.Structure(root); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Package source maps the byte offsets used by tokens, nodes and errors to
+// file names, lines and columns. It is modeled on go/token: a FileSet
+// assigns each File a range of Pos values, so a single integer identifies
+// a location across many inputs.
+package source
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// Pos is a position in a FileSet. The zero value NoPos is no position.
+type Pos int
+
+// NoPos is the zero value of Pos.
+const NoPos Pos = 0
+
+// IsValid reports whether p is a position.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Position is a resolved location in a file.
+type Position struct {
+	Filename    string // file name, if any
+	Offset      int    // byte offset, starting at 0
+	Line        int    // line, starting at 1
+	Column      int    // column in runes, starting at 1
+	ColumnUTF16 int    // column in UTF-16 code units, starting at 1, as used by LSP and JavaScript
+}
+
+// IsValid reports whether the position has a line.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as "file:line:column", "line:column" without
+// a file name, or "-" if it is invalid.
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// File is a source text together with the offsets of its lines.
+type File struct {
+	name  string
+	base  int
+	src   string
+	lines []int // byte offsets at which lines start
+}
+
+// NewFile returns a File that is not part of a FileSet. Its base is 1.
+func NewFile(name, src string) *File {
+	f := &File{name: name, base: 1, src: src, lines: []int{0}}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			f.lines = append(f.lines, i+1)
+		}
+	}
+	return f
+}
+
+// Name returns the file name.
+func (f *File) Name() string { return f.name }
+
+// Base returns the Pos of the first byte of the file.
+func (f *File) Base() int { return f.base }
+
+// Size returns the length of the file in bytes.
+func (f *File) Size() int { return len(f.src) }
+
+// Source returns the text of the file.
+func (f *File) Source() string { return f.src }
+
+// LineCount returns the number of lines.
+func (f *File) LineCount() int { return len(f.lines) }
+
+// Line returns the text of the given line, starting at 1, without its
+// line break.
+func (f *File) Line(line int) string {
+	if line < 1 || line > len(f.lines) {
+		return ""
+	}
+	start, end := f.lines[line-1], len(f.src)
+	if line < len(f.lines) {
+		end = f.lines[line] - 1
+	}
+	if end > start && f.src[end-1] == '\r' {
+		end--
+	}
+	return f.src[start:end]
+}
+
+// Pos returns the Pos of a byte offset. Offsets are clamped to the file.
+func (f *File) Pos(offset int) Pos {
+	return Pos(f.base + f.clamp(offset))
+}
+
+// Offset returns the byte offset of p, which must belong to the file.
+func (f *File) Offset(p Pos) int {
+	return f.clamp(int(p) - f.base)
+}
+
+// Position returns the location of a byte offset, such as the Pos of a
+// token or node or the position of an error.
+func (f *File) Position(offset int) Position {
+	offset = f.clamp(offset)
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	prefix := f.src[f.lines[i]:offset]
+	return Position{
+		Filename:    f.name,
+		Offset:      offset,
+		Line:        i + 1,
+		Column:      utf8.RuneCountInString(prefix) + 1,
+		ColumnUTF16: utf16Len(prefix) + 1,
+	}
+}
+
+func (f *File) clamp(offset int) int {
+	return max(0, min(offset, len(f.src)))
+}
+
+// utf16Len returns the number of UTF-16 code units of s.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// FileSet is a set of files with disjoint ranges of Pos values. It is safe
+// for concurrent use.
+type FileSet struct {
+	mu    sync.RWMutex
+	base  int
+	files []*File
+}
+
+// NewFileSet creates a new, empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile adds a file to the set. Its positions follow those of the
+// previously added file, with one extra position for the end of file.
+func (s *FileSet) AddFile(name, src string) *File {
+	f := NewFile(name, src)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f.base = s.base
+	s.base += len(src) + 1
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file containing p, or nil.
+func (s *FileSet) File(p Pos) *File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i < 0 || int(p) > s.files[i].base+s.files[i].Size() {
+		return nil
+	}
+	return s.files[i]
+}
+
+// Position resolves p, or returns the zero Position if p belongs to no
+// file.
+func (s *FileSet) Position(p Pos) Position {
+	if f := s.File(p); f != nil {
+		return f.Position(f.Offset(p))
+	}
+	return Position{}
+}
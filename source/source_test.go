@@ -0,0 +1,64 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/source"
+)
+
+func TestFilePosition(t *testing.T) {
+	f := source.NewFile("a.tex", "x + 1\n\\alpha = α𝑥 + y\r\nz")
+	tests := []struct {
+		offset                    int
+		line, column, columnUTF16 int
+	}{
+		{0, 1, 1, 1},
+		{4, 1, 5, 5},
+		{6, 2, 1, 1},
+		{15, 2, 10, 10}, // before "α"
+		{17, 2, 11, 11},
+		{21, 2, 12, 13}, // after "𝑥", outside the basic multilingual plane
+		{27, 3, 1, 1},
+		{100, 3, 2, 2}, // clamped to the end
+	}
+
+	for _, tt := range tests {
+		pos := f.Position(tt.offset)
+		if pos.Line != tt.line || pos.Column != tt.column || pos.ColumnUTF16 != tt.columnUTF16 {
+			t.Errorf("offset %d: got %d:%d (UTF-16 %d), want %d:%d (UTF-16 %d)",
+				tt.offset, pos.Line, pos.Column, pos.ColumnUTF16, tt.line, tt.column, tt.columnUTF16)
+		}
+	}
+
+	if got := f.Position(6).String(); got != "a.tex:2:1" {
+		t.Errorf("got %s, want a.tex:2:1", got)
+	}
+	if got := f.Line(2); got != "\\alpha = α𝑥 + y" {
+		t.Errorf("line 2: got %q", got)
+	}
+}
+
+func TestFileSet(t *testing.T) {
+	fset := source.NewFileSet()
+	a := fset.AddFile("a.md", "$x$\n$y$")
+	b := fset.AddFile("b.md", "$$z$$")
+
+	tests := []struct {
+		pos  source.Pos
+		want string
+	}{
+		{a.Pos(1), "a.md:1:2"},
+		{a.Pos(5), "a.md:2:2"},
+		{b.Pos(2), "b.md:1:3"},
+		{b.Pos(5), "b.md:1:6"},
+		{source.NoPos, "-"},
+	}
+	for _, tt := range tests {
+		if got := fset.Position(tt.pos).String(); got != tt.want {
+			t.Errorf("pos %d: got %s, want %s", tt.pos, got, tt.want)
+		}
+	}
+	if fset.File(b.Pos(0)) != b {
+		t.Errorf("file of %d is not b.md", b.Pos(0))
+	}
+}
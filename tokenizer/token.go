@@ -13,7 +13,7 @@ const (
 	COMMAND     // e.g., \frac, \alpha
 	SYMBOL      // e.g., x, y, z
 	NUMBER      // e.g., 123
-	OPERATOR    // e.g., +, -, =, *, /
+	OPERATOR    // e.g., +, -, =, *, /, <, >
 	SUPERSCRIPT // ^
 	SUBSCRIPT   // _
 	LBRACE      // {
@@ -29,6 +29,15 @@ type Token struct {
 	Pos   int       // Byte offset in the input
 }
 
+// End returns the byte offset just after the token. Commands are stored
+// without their backslash.
+func (t Token) End() int {
+	if t.Type == COMMAND {
+		return t.Pos + 1 + len(t.Value)
+	}
+	return t.Pos + len(t.Value)
+}
+
 // String returns a readable representation of the token.
 func (t Token) String() string {
 	return t.Type.String() + "('" + t.Value + "')"
@@ -1,13 +1,32 @@
 package tokenizer
 
-import "unicode"
+import (
+	"errors"
+	"unicode"
+)
+
+// ErrTooManyTokens is returned by TokenizeLimit for input of more tokens
+// than allowed.
+var ErrTooManyTokens = errors.New("tokenizer: too many tokens")
 
 func Tokenize(input string) []Token {
+	tokens, _ := TokenizeLimit(input, 0)
+	return tokens
+}
+
+// TokenizeLimit is like Tokenize, but stops with ErrTooManyTokens once the
+// input has more than limit tokens, not counting EOF. The tokens up to the
+// limit are returned, followed by EOF. A limit of 0 means none.
+func TokenizeLimit(input string, limit int) ([]Token, error) {
 	var tokens []Token
 	var pos int
 	runes := []rune(input)
 
 	for i := 0; i < len(runes); {
+		if limit > 0 && len(tokens) >= limit {
+			tokens = append(tokens, Token{Type: EOF, Value: "", Pos: pos})
+			return tokens, ErrTooManyTokens
+		}
 		r := runes[i]
 
 		// Track current position (as bytes, not runes)
@@ -79,7 +98,7 @@ func Tokenize(input string) []Token {
 			pos += charLen
 
 		// OPERATOR
-		case r == '+' || r == '-' || r == '*' || r == '/' || r == '=':
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '=' || r == '<' || r == '>':
 			tokens = append(tokens, Token{Type: OPERATOR, Value: string(r), Pos: start})
 			i++
 			pos += charLen
@@ -130,5 +149,5 @@ func Tokenize(input string) []Token {
 
 	// Add EOF token at end
 	tokens = append(tokens, Token{Type: EOF, Value: "", Pos: pos})
-	return tokens
+	return tokens, nil
 }
@@ -39,3 +39,18 @@ func TestTokenize(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenizeLimit(t *testing.T) {
+	tokens, err := tokenizer.TokenizeLimit("a+b", 3)
+	if err != nil || len(tokens) != 4 {
+		t.Errorf("got %d tokens and %v, want 4 tokens", len(tokens), err)
+	}
+
+	tokens, err = tokenizer.TokenizeLimit("a+b+c", 3)
+	if err != tokenizer.ErrTooManyTokens {
+		t.Fatalf("err = %v, want ErrTooManyTokens", err)
+	}
+	if last := tokens[len(tokens)-1]; len(tokens) != 4 || last.Type != tokenizer.EOF || last.Pos != 3 {
+		t.Errorf("got %v, want 3 tokens and EOF at 3", tokens)
+	}
+}
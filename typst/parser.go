@@ -0,0 +1,461 @@
+// Package typst converts between the AST and Typst math syntax, e.g.
+// `frac(a, b)`, `root(n, x)` or `sum_(i=1)^n`.
+//
+// The parser maps Typst math back into the nodes of the LaTeX parser:
+// brackets become ast.DelimitedExpressionNode and are dropped where they
+// only group a script, a fraction operand or an lr argument; `a/b` and
+// `frac(a, b)` both become ast.FractionNode. Positions are byte offsets
+// into the input. Format writes an AST as Typst math.
+package typst
+
+import (
+	"fmt"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+)
+
+// Parse tokenizes and parses input.
+func Parse(input string) (ast.Node, []parser.ParseError) {
+	return New(Tokenize(input)).Parse()
+}
+
+type Parser struct {
+	tokens []Token
+	pos    int
+	errors []parser.ParseError
+
+	// bars counts the open |...| groups; inside them a bar ends the
+	// expression
+	bars int
+	// inArgs is set inside the arguments of a call, where a comma ends
+	// the expression
+	inArgs bool
+	// groups are the expressions in plain parentheses, which are removed
+	// around attachments and fraction operands
+	groups map[ast.Node]bool
+}
+
+func New(ts []Token) *Parser {
+	return &Parser{
+		tokens: ts,
+		errors: []parser.ParseError{},
+		groups: map[ast.Node]bool{},
+	}
+}
+
+func (p *Parser) Parse() (ast.Node, []parser.ParseError) {
+	start := p.peek().Pos
+	var elements []ast.Node
+	for {
+		elements = append(elements, p.parseSequence()...)
+		if p.peek().Type == EOF {
+			break
+		}
+		// An unmatched closing bracket is kept as a plain delimiter
+		t := p.next()
+		p.addError("unexpected closing bracket "+t.Value, t.Pos)
+		elements = append(elements, delimiter(t))
+	}
+	return &ast.ExpressionNode{Start: start, Elements: elements}, p.errors
+}
+
+func (p *Parser) peek() Token {
+	if p.pos >= len(p.tokens) {
+		return Token{Type: EOF, Value: "", Pos: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *Parser) next() Token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *Parser) addError(msg string, pos int) {
+	p.errors = append(p.errors, parser.ParseError{Message: msg, Pos: pos})
+}
+
+// parseSequence parses expressions up to the end of input, a closing
+// bracket or, inside |...| and call arguments, a bar or comma.
+func (p *Parser) parseSequence() []ast.Node {
+	var elements []ast.Node
+	for {
+		t := p.peek()
+		if t.Type == EOF || t.Type == RIGHT ||
+			(t.Type == BAR && p.bars > 0) || (t.Type == COMMA && p.inArgs) {
+			return elements
+		}
+		if n := p.parseFraction(); n != nil {
+			elements = append(elements, n)
+		}
+	}
+}
+
+// parseFraction parses an expression with attachments, followed by any
+// number of "/" and denominators.
+func (p *Parser) parseFraction() ast.Node {
+	left := p.parseAttached()
+	if left == nil {
+		return nil
+	}
+	for p.peek().Type == DIVIDE {
+		p.next() // consume '/'
+		right := p.parseAttached()
+		if right == nil {
+			p.addError("expected denominator after '/'", p.peek().Pos)
+			return left
+		}
+		left = &ast.FractionNode{
+			Start:       left.Pos(),
+			Numerator:   p.unbracket(left),
+			Denominator: p.unbracket(right),
+		}
+	}
+	return left
+}
+
+// parseAttached parses a primary expression with its subscripts and
+// superscripts.
+func (p *Parser) parseAttached() ast.Node {
+	base := p.parsePrimary()
+	if base == nil {
+		return nil
+	}
+	for {
+		switch p.peek().Type {
+		case SUBSCRIPT:
+			p.next() // consume '_'
+			base = &ast.SubscriptNode{Start: base.Pos(), Base: base, Subscript: p.parseAttachment("_")}
+		case SUPERSCRIPT:
+			p.next() // consume '^'
+			base = &ast.SuperscriptNode{Start: base.Pos(), Base: base, Exponent: p.parseAttachment("^")}
+		default:
+			return base
+		}
+	}
+}
+
+// parseAttachment parses a subscript or superscript, removing grouping
+// brackets. A missing attachment is reported and replaced by an empty
+// expression.
+func (p *Parser) parseAttachment(after string) ast.Node {
+	t := p.peek()
+	if n := p.parsePrimary(); n != nil {
+		return p.unbracket(n)
+	}
+	p.addError("expected attachment after "+after, t.Pos)
+	return &ast.ExpressionNode{Start: t.Pos}
+}
+
+// parsePrimary parses a single symbol, bracketed expression, or call.
+func (p *Parser) parsePrimary() ast.Node {
+	t := p.peek()
+	switch t.Type {
+	case NUMBER:
+		p.next()
+		return &ast.NumberNode{Start: t.Pos, Value: t.Value}
+	case SYMBOL, TEXT:
+		p.next()
+		return &ast.SymbolNode{Start: t.Pos, Value: t.Value}
+	case IDENT:
+		p.next()
+		p.addError("unknown variable: "+t.Value, t.Pos)
+		return &ast.SymbolNode{Start: t.Pos, Value: t.Value}
+	case OPERATOR, DIVIDE, COMMA:
+		p.next()
+		return &ast.OperatorNode{Start: t.Pos, Value: t.Value}
+	case FUNCTION:
+		p.next()
+		return &ast.NonArgumentFunctionNode{Start: t.Pos, Name: t.Value}
+	case DELIMITER:
+		p.next()
+		return delimiter(t)
+	case BIGOP:
+		p.next()
+		return p.parseBigOperator(t)
+	case CALL:
+		p.next()
+		return p.parseCall(t)
+	case LEFT:
+		return p.parseBracketed()
+	case BAR:
+		return p.parseBars()
+	case EOF, RIGHT:
+		return nil
+	}
+
+	// Attachments without a base and illegal tokens
+	p.next()
+	p.addError("unexpected "+t.Value, t.Pos)
+	return nil
+}
+
+// parseBigOperator parses sum, product, integral and lim with their limits.
+func (p *Parser) parseBigOperator(t Token) ast.Node {
+	node := &ast.LimitedOperatorNode{Start: t.Pos, Operator: t.Value}
+	for {
+		switch p.peek().Type {
+		case SUBSCRIPT:
+			p.next()
+			if node.LowerLimit != nil {
+				p.addError("duplicate lower limit", p.peek().Pos)
+			}
+			node.LowerLimit = p.parseAttachment("_")
+		case SUPERSCRIPT:
+			p.next()
+			if node.UpperLimit != nil {
+				p.addError("duplicate upper limit", p.peek().Pos)
+			}
+			node.UpperLimit = p.parseAttachment("^")
+		default:
+			if node.Operator == "lim" && node.UpperLimit != nil {
+				p.addError("lim can only have a lower limit", node.UpperLimit.Pos())
+				node.UpperLimit = nil
+			}
+			return node
+		}
+	}
+}
+
+// callArity is the number of arguments of each call.
+var callArity = map[string]int{
+	"frac": 2, "root": 2, "binom": 2,
+	"sqrt": 1, "lr": 1, "abs": 1, "norm": 1, "floor": 1, "ceil": 1,
+	"display": 1, "inline": 1, "script": 1, "sscript": 1,
+}
+
+// callStyles maps the style calls to the styles of fractions and
+// binomials. The style of other content is dropped.
+var callStyles = map[string]ast.Style{
+	"display": ast.DisplayStyle,
+	"inline":  ast.TextStyle,
+	"script":  ast.ScriptStyle,
+	"sscript": ast.ScriptScriptStyle,
+}
+
+// callDelimiters maps the calls written with delimiters to the delimiter
+// kinds of ast.DelimiterNode.
+var callDelimiters = map[string][2]ast.Delimiter{
+	"abs":   {ast.Vert, ast.Vert},
+	"norm":  {ast.DoubleVert, ast.DoubleVert},
+	"floor": {ast.LeftFloor, ast.RightFloor},
+	"ceil":  {ast.LeftCeil, ast.RightCeil},
+}
+
+// parseCall parses a function call like frac(a, b). The arguments must
+// follow the name directly.
+func (p *Parser) parseCall(t Token) ast.Node {
+	if next := p.peek(); next.Type != LEFT || next.Value != "(" || next.Pos != t.Pos+t.Len {
+		p.addError("expected arguments after "+t.Value, next.Pos)
+		return &ast.SymbolNode{Start: t.Pos, Value: t.Value}
+	}
+
+	args := p.parseArguments()
+	if n := callArity[t.Value]; len(args) != n {
+		p.addError(fmt.Sprintf("%s expects %d arguments, got %d", t.Value, n, len(args)), t.Pos)
+		for len(args) < n {
+			args = append(args, &ast.ExpressionNode{Start: p.peek().Pos})
+		}
+	}
+
+	switch t.Value {
+	case "frac":
+		return &ast.FractionNode{Start: t.Pos, Numerator: args[0], Denominator: args[1]}
+	case "binom":
+		return &ast.BinomNode{Start: t.Pos, Upper: args[0], Lower: args[1]}
+	case "root":
+		return &ast.SqrtNode{Start: t.Pos, Index: args[0], Radicand: args[1]}
+	case "sqrt":
+		return &ast.SqrtNode{Start: t.Pos, Radicand: args[0]}
+	case "lr":
+		d := leftRight(args[0])
+		delete(p.groups, d)
+		return d
+	case "display", "inline", "script", "sscript":
+		if len(args[0].Elements) == 1 {
+			switch n := args[0].Elements[0].(type) {
+			case *ast.FractionNode:
+				n.Style = callStyles[t.Value]
+			case *ast.BinomNode:
+				n.Style = callStyles[t.Value]
+			}
+		}
+		return args[0]
+	}
+	delims := callDelimiters[t.Value]
+	return &ast.DelimitedExpressionNode{
+		Start:          t.Pos,
+		LeftDelimiter:  &ast.DelimiterNode{Start: t.Pos, Kind: delims[0]},
+		Content:        args[0],
+		RightDelimiter: &ast.DelimiterNode{Start: args[0].End(), Kind: delims[1]},
+	}
+}
+
+// parseArguments parses the comma separated arguments of a call.
+func (p *Parser) parseArguments() []*ast.ExpressionNode {
+	p.next() // consume '('
+
+	bars, inArgs := p.bars, p.inArgs
+	p.bars, p.inArgs = 0, true
+	var args []*ast.ExpressionNode
+	for {
+		args = append(args, &ast.ExpressionNode{Start: p.peek().Pos, Elements: p.parseSequence()})
+		if p.peek().Type != COMMA {
+			break
+		}
+		p.next() // consume ','
+	}
+	p.bars, p.inArgs = bars, inArgs
+
+	if t := p.peek(); t.Type == RIGHT && t.Value == ")" {
+		p.next()
+	} else {
+		p.addError("expected ')' to close arguments", t.Pos)
+	}
+	return args
+}
+
+// leftRight returns the delimited expression of an lr call. The argument is
+// either a bracketed expression, or starts and ends with delimiters such as
+// angle.l and angle.r.
+func leftRight(arg *ast.ExpressionNode) ast.Node {
+	if len(arg.Elements) == 1 {
+		if d, ok := arg.Elements[0].(*ast.DelimitedExpressionNode); ok {
+			return d
+		}
+	}
+
+	elements := arg.Elements
+	left := &ast.DelimiterNode{Start: arg.Pos(), Kind: ast.NoDelimiter}
+	if len(elements) > 0 {
+		if d, ok := elements[0].(*ast.DelimiterNode); ok && isOpening(d.Kind) {
+			left, elements = d, elements[1:]
+		}
+	}
+	right := &ast.DelimiterNode{Start: arg.End(), Kind: ast.NoDelimiter}
+	if len(elements) > 0 {
+		if d, ok := elements[len(elements)-1].(*ast.DelimiterNode); ok && isClosing(d.Kind) {
+			right, elements = d, elements[:len(elements)-1]
+		}
+	}
+	return &ast.DelimitedExpressionNode{
+		Start:          left.Start,
+		LeftDelimiter:  left,
+		Content:        &ast.ExpressionNode{Start: arg.Pos(), Elements: elements},
+		RightDelimiter: right,
+	}
+}
+
+func isOpening(kind ast.Delimiter) bool {
+	return kind != ast.NoDelimiter && kind.Class() != ast.CloseDelimiter
+}
+
+func isClosing(kind ast.Delimiter) bool {
+	return kind != ast.NoDelimiter && kind.Class() != ast.OpenDelimiter
+}
+
+// delimiterKinds maps the values of delimiter tokens to the kinds of
+// ast.DelimiterNode.
+var delimiterKinds = map[string]ast.Delimiter{
+	"(":                ast.LeftParen,
+	")":                ast.RightParen,
+	"[":                ast.LeftBracket,
+	"]":                ast.RightBracket,
+	"{":                ast.LeftBrace,
+	"}":                ast.RightBrace,
+	"|":                ast.Vert,
+	"angle.l":          ast.LeftAngle,
+	"angle.r":          ast.RightAngle,
+	"floor.l":          ast.LeftFloor,
+	"floor.r":          ast.RightFloor,
+	"ceil.l":           ast.LeftCeil,
+	"ceil.r":           ast.RightCeil,
+	"paren.l.flat":     ast.LeftGroup,
+	"paren.r.flat":     ast.RightGroup,
+	"mustache.l":       ast.LeftMoustache,
+	"mustache.r":       ast.RightMoustache,
+	"corner.l.t":       ast.UpperLeftCorner,
+	"corner.r.t":       ast.UpperRightCorner,
+	"corner.l.b":       ast.LowerLeftCorner,
+	"corner.r.b":       ast.LowerRightCorner,
+	"bar.v.double":     ast.DoubleVert,
+	"backslash":        ast.Backslash,
+	"arrow.t":          ast.UpArrow,
+	"arrow.b":          ast.DownArrow,
+	"arrow.t.b":        ast.UpDownArrow,
+	"arrow.t.double":   ast.DoubleUpArrow,
+	"arrow.b.double":   ast.DoubleDownArrow,
+	"arrow.t.b.double": ast.DoubleUpDownArrow,
+}
+
+// delimiter returns the delimiter node of a delimiter, bracket or bar
+// token.
+func delimiter(t Token) *ast.DelimiterNode {
+	return &ast.DelimiterNode{Start: t.Pos, Stop: t.Pos + t.Len, Kind: delimiterKinds[t.Value]}
+}
+
+// parseBracketed parses a bracketed expression. Any closing bracket ends it.
+func (p *Parser) parseBracketed() ast.Node {
+	left := p.next()
+
+	// Bars and commas inside brackets belong to the bracketed expression
+	bars, inArgs := p.bars, p.inArgs
+	p.bars, p.inArgs = 0, false
+	content := &ast.ExpressionNode{Start: p.peek().Pos, Elements: p.parseSequence()}
+	p.bars, p.inArgs = bars, inArgs
+
+	var right *ast.DelimiterNode
+	if t := p.peek(); t.Type == RIGHT {
+		p.next()
+		right = delimiter(t)
+	} else {
+		p.addError("expected closing bracket", t.Pos)
+		right = &ast.DelimiterNode{Start: t.Pos, Kind: ast.NoDelimiter}
+	}
+
+	node := &ast.DelimitedExpressionNode{
+		Start:          left.Pos,
+		LeftDelimiter:  delimiter(left),
+		Content:        content,
+		RightDelimiter: right,
+	}
+	if left.Value == "(" && right.Kind == ast.RightParen {
+		p.groups[node] = true
+	}
+	return node
+}
+
+// parseBars parses |...| as an absolute value. A bar without a matching
+// bar is kept as a plain delimiter.
+func (p *Parser) parseBars() ast.Node {
+	left := p.next()
+	pos, errors := p.pos, len(p.errors)
+
+	p.bars++
+	content := &ast.ExpressionNode{Start: p.peek().Pos, Elements: p.parseSequence()}
+	p.bars--
+
+	if t := p.peek(); t.Type == BAR && len(content.Elements) > 0 {
+		p.next()
+		return &ast.DelimitedExpressionNode{
+			Start:          left.Pos,
+			LeftDelimiter:  delimiter(left),
+			Content:        content,
+			RightDelimiter: delimiter(t),
+		}
+	}
+
+	p.pos, p.errors = pos, p.errors[:errors]
+	return delimiter(left)
+}
+
+// unbracket returns the content of an expression in plain parentheses,
+// and n itself otherwise. Parentheses written with lr are kept.
+func (p *Parser) unbracket(n ast.Node) ast.Node {
+	if d, ok := n.(*ast.DelimitedExpressionNode); ok && p.groups[d] {
+		return d.Content
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+package typst_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/ast"
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/unicode"
+	"github.com/neox5/texmax/tokenizer"
+	"github.com/neox5/texmax/typst"
+)
+
+func TestParseMatchesLaTeX(t *testing.T) {
+	tests := []struct {
+		typst string
+		latex string
+	}{
+		{`sqrt(x)/2`, `\frac{\sqrt{x}}{2}`},
+		{`sum_(i=1)^n i^2`, `\sum_{i=1}^{n} i^2`},
+		{`(a+b)/(c-d)`, `\frac{a+b}{c-d}`},
+		{`root(3, x)`, `\sqrt[3]{x}`},
+		{`frac(1, 2)`, `\frac{1}{2}`},
+		{`alpha_1 <= beta`, `\alpha_1 \leq \beta`},
+		{`x^(n+1) times y`, `x^{n+1} \times y`},
+		{`integral_0^1 f(x) d x`, `\int_0^1 f(x) d x`},
+		{`binom(n, k)`, `\binom{n}{k}`},
+		{`lim_(x -> 0) x`, `\lim_{x \to 0} x`},
+		{`abs(x) + norm(y)`, `\left| x \right| + \left\| y \right\|`},
+		{`lr(angle.l x angle.r)`, `\left\langle x \right\rangle`},
+		{`a dot.op b`, `a \cdot b`},
+	}
+
+	for _, tt := range tests {
+		got, errs := typst.Parse(tt.typst)
+		if len(errs) > 0 {
+			t.Errorf("%s: unexpected errors %v", tt.typst, errs)
+			continue
+		}
+		want, _ := parser.New(tokenizer.Tokenize(tt.latex)).Parse()
+		if g, w := unicode.Render(got), unicode.Render(want); g != w {
+			t.Errorf("%s: got %q, want %q as for %s", tt.typst, g, w, tt.latex)
+		}
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	root, _ := typst.Parse(`a + sqrt(x)/2`)
+	elements := root.(*ast.ExpressionNode).Elements
+	if len(elements) != 3 {
+		t.Fatalf("got %d elements, want 3", len(elements))
+	}
+
+	frac, ok := elements[2].(*ast.FractionNode)
+	if !ok {
+		t.Fatalf("got %T, want *ast.FractionNode", elements[2])
+	}
+	if frac.Pos() != 4 || frac.End() != 13 {
+		t.Errorf("fraction spans [%d, %d), want [4, 13)", frac.Pos(), frac.End())
+	}
+	if sqrt := frac.Numerator.(*ast.SqrtNode); sqrt.Radicand.Pos() != 9 {
+		t.Errorf("radicand starts at %d, want 9", sqrt.Radicand.Pos())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{`(a+b`, 4},
+		{`a)`, 1},
+		{`x^`, 2},
+		{`frac(a)`, 0},
+		{`xy + 1`, 0},
+	}
+
+	for _, tt := range tests {
+		_, errs := typst.Parse(tt.input)
+		if len(errs) != 1 {
+			t.Errorf("%s: got %d errors %v, want 1", tt.input, len(errs), errs)
+			continue
+		}
+		if errs[0].Pos != tt.pos {
+			t.Errorf("%s: error at %d, want %d", tt.input, errs[0].Pos, tt.pos)
+		}
+	}
+}
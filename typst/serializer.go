@@ -0,0 +1,447 @@
+package typst
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/neox5/texmax/ast"
+)
+
+// operatorNames maps operator values in the AST to Typst math. Operators
+// without an entry are written as they are.
+var operatorNames = map[string]string{
+	"/": "slash", // "/" is a fraction
+	"∗": "*",
+	"·": "dot",
+	"×": "times",
+	"÷": "div",
+	"±": "plus.minus",
+	"∓": "minus.plus",
+	"∘": "compose",
+	"∪": "union",
+	"∩": "sect",
+	"∖": "without",
+	"∧": "and",
+	"∨": "or",
+	"≤": "<=",
+	"≥": ">=",
+	"≠": "!=",
+	"≈": "approx",
+	"≡": "equiv",
+	"∼": "tilde.op",
+	"≃": "tilde.eq",
+	"≅": "tilde.equiv",
+	"∝": "prop",
+	"≪": "lt.double",
+	"≫": "gt.double",
+	"∈": "in",
+	"∉": "in.not",
+	"⊂": "subset",
+	"⊃": "supset",
+	"→": "->",
+	"←": "<-",
+	"⇒": "=>",
+	"⇐": "arrow.l.double",
+	"⇔": "<=>",
+	"↦": "|->",
+}
+
+// delimiterNames maps the delimiters to Typst math.
+var delimiterNames = map[ast.Delimiter]string{
+	ast.LeftParen:         "(",
+	ast.RightParen:        ")",
+	ast.LeftBracket:       "[",
+	ast.RightBracket:      "]",
+	ast.LeftBrace:         "{",
+	ast.RightBrace:        "}",
+	ast.LeftAngle:         "angle.l",
+	ast.RightAngle:        "angle.r",
+	ast.LeftFloor:         "floor.l",
+	ast.RightFloor:        "floor.r",
+	ast.LeftCeil:          "ceil.l",
+	ast.RightCeil:         "ceil.r",
+	ast.LeftGroup:         "paren.l.flat",
+	ast.RightGroup:        "paren.r.flat",
+	ast.LeftMoustache:     "mustache.l",
+	ast.RightMoustache:    "mustache.r",
+	ast.UpperLeftCorner:   "corner.l.t",
+	ast.UpperRightCorner:  "corner.r.t",
+	ast.LowerLeftCorner:   "corner.l.b",
+	ast.LowerRightCorner:  "corner.r.b",
+	ast.Vert:              "|",
+	ast.LeftVert:          "|",
+	ast.RightVert:         "|",
+	ast.DoubleVert:        "bar.v.double",
+	ast.LeftDoubleVert:    "bar.v.double",
+	ast.RightDoubleVert:   "bar.v.double",
+	ast.Slash:             "slash",
+	ast.Backslash:         "backslash",
+	ast.UpArrow:           "arrow.t",
+	ast.DownArrow:         "arrow.b",
+	ast.UpDownArrow:       "arrow.t.b",
+	ast.DoubleUpArrow:     "arrow.t.double",
+	ast.DoubleDownArrow:   "arrow.b.double",
+	ast.DoubleUpDownArrow: "arrow.t.b.double",
+}
+
+// delimiterCalls maps delimiter pairs written as calls.
+var delimiterCalls = map[[2]ast.Delimiter]string{
+	{ast.Vert, ast.Vert}:                      "abs",
+	{ast.LeftVert, ast.RightVert}:             "abs",
+	{ast.DoubleVert, ast.DoubleVert}:          "norm",
+	{ast.LeftDoubleVert, ast.RightDoubleVert}: "norm",
+	{ast.LeftFloor, ast.RightFloor}:           "floor",
+	{ast.LeftCeil, ast.RightCeil}:             "ceil",
+}
+
+// bigOperatorNames maps the operators of ast.LimitedOperatorNode to Typst.
+var bigOperatorNames = map[string]string{
+	"sum":  "sum",
+	"prod": "product",
+	"int":  "integral",
+	"lim":  "lim",
+}
+
+// Format returns node as Typst math, such that Parse(Format(node)) yields
+// the same formula.
+func Format(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+	s := NewSerializer()
+	ast.Walk(s, node)
+	return s.String()
+}
+
+// Serializer is an ast.Visitor that writes Typst math. Operators are spaced
+// at the top level and written compactly inside attachments; fractions,
+// roots and binomials are written as calls.
+type Serializer struct {
+	sb    strings.Builder
+	depth int
+}
+
+// NewSerializer creates a new Serializer.
+func NewSerializer() *Serializer {
+	return &Serializer{}
+}
+
+// String returns the Typst math written so far.
+func (s *Serializer) String() string {
+	return s.sb.String()
+}
+
+// format returns the Typst math for n at the given depth.
+func (s *Serializer) format(n ast.Node, depth int) string {
+	if n == nil {
+		return ""
+	}
+	sub := &Serializer{depth: depth}
+	n.Accept(sub)
+	return sub.String()
+}
+
+// attachment returns n as a subscript or superscript, in parentheses
+// unless it is a single primary expression.
+func (s *Serializer) attachment(n ast.Node) string {
+	text := s.format(n, s.depth+1)
+	if isPrimary(n) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// argument returns n as an argument of a call. Arguments containing a
+// comma are put in parentheses.
+func (s *Serializer) argument(n ast.Node) string {
+	text := s.format(n, s.depth+1)
+	if hasComma(n) {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// base returns n as the base of an attachment. A closing delimiter, as
+// in (a-b)^2, takes the attachment for the group it closes.
+func (s *Serializer) base(n ast.Node) string {
+	text := s.format(n, s.depth)
+	if _, ok := n.(*ast.DelimiterNode); ok {
+		return text
+	}
+	if isPrimary(n) || isAttached(n) || isFunction(n) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// Visit methods for container nodes
+func (s *Serializer) VisitExpressionNode(node *ast.ExpressionNode) {
+	var out string
+	for i, element := range node.Elements {
+		if _, ok := element.(*ast.SpaceNode); ok {
+			continue
+		}
+		text := s.format(element, s.depth)
+		if out != "" && (s.spaced(node.Elements, i) || needsSpace(out, text)) {
+			out += " "
+		}
+		out += text
+	}
+	s.sb.WriteString(out)
+}
+
+// spaced reports whether the element at i is separated from the previous
+// element by a space: around operators at the top level and after
+// functions and big operators.
+func (s *Serializer) spaced(elements []ast.Node, i int) bool {
+	prev := previous(elements, i)
+	switch node := prev.(type) {
+	case *ast.NonArgumentFunctionNode, *ast.LimitedOperatorNode:
+		return true
+	case *ast.SuperscriptNode:
+		return isFunction(node.Base)
+	case *ast.OperatorNode:
+		// No space after a sign
+		if s.depth > 0 || isOperator(previous(elements, i-1)) || previous(elements, i-1) == nil {
+			return false
+		}
+		return true
+	}
+	if op, ok := elements[i].(*ast.OperatorNode); ok {
+		return s.depth == 0 && op.Value != ","
+	}
+	return false
+}
+
+// previous returns the element before i, skipping spaces, or nil.
+func previous(elements []ast.Node, i int) ast.Node {
+	for j := i - 1; j >= 0; j-- {
+		if _, ok := elements[j].(*ast.SpaceNode); !ok {
+			return elements[j]
+		}
+	}
+	return nil
+}
+
+func (s *Serializer) VisitDelimitedExpressionNode(node *ast.DelimitedExpressionNode) {
+	left, right := delimiterKind(node.LeftDelimiter), delimiterKind(node.RightDelimiter)
+	content := s.format(node.Content, s.depth)
+	if call, ok := delimiterCalls[[2]ast.Delimiter{left, right}]; ok {
+		s.sb.WriteString(call + "(" + content + ")")
+		return
+	}
+
+	// Brackets are written as they are when both sides are brackets, so
+	// that they pair up; a single bracket is escaped
+	l, r := delimiterNames[left], delimiterNames[right]
+	if !isBracket(left) || !isBracket(right) {
+		if isBracket(left) {
+			l = `\` + l
+		}
+		if isBracket(right) {
+			r = `\` + r
+		}
+	}
+	parts := []string{l, content, r}
+	if l != "" && !isBracket(left) && content != "" {
+		parts[0] += " "
+	}
+	if r != "" && !isBracket(right) && content != "" {
+		parts[2] = " " + r
+	}
+	s.sb.WriteString("lr(" + strings.Join(parts, "") + ")")
+}
+
+// VisitErrorNode shows what was parsed of erroneous input.
+func (s *Serializer) VisitErrorNode(node *ast.ErrorNode) {
+	s.VisitExpressionNode(&ast.ExpressionNode{Start: node.Start, Elements: node.Elements})
+}
+
+// Visit methods for leaf nodes
+func (s *Serializer) VisitSymbolNode(node *ast.SymbolNode) {
+	switch name, ok := symbolNames[node.Value]; {
+	case ok:
+		s.sb.WriteString(name)
+	case utf8.RuneCountInString(node.Value) > 1:
+		s.sb.WriteString(`"` + node.Value + `"`)
+	default:
+		s.sb.WriteString(node.Value)
+	}
+}
+
+func (s *Serializer) VisitNumberNode(node *ast.NumberNode) {
+	s.sb.WriteString(node.Value)
+}
+
+func (s *Serializer) VisitOperatorNode(node *ast.OperatorNode) {
+	if name, ok := operatorNames[node.Value]; ok {
+		s.sb.WriteString(name)
+		return
+	}
+	s.sb.WriteString(node.Value)
+}
+
+func (s *Serializer) VisitNonArgumentFunctionNode(node *ast.NonArgumentFunctionNode) {
+	s.sb.WriteString(node.Name)
+}
+
+func (s *Serializer) VisitSpaceNode(node *ast.SpaceNode) {}
+
+func (s *Serializer) VisitDelimiterNode(node *ast.DelimiterNode) {
+	s.sb.WriteString(delimiterNames[node.Kind])
+}
+
+// Visit methods for composite nodes
+func (s *Serializer) VisitSuperscriptNode(node *ast.SuperscriptNode) {
+	s.sb.WriteString(s.base(node.Base) + "^" + s.attachment(node.Exponent))
+}
+
+func (s *Serializer) VisitSubscriptNode(node *ast.SubscriptNode) {
+	s.sb.WriteString(s.base(node.Base) + "_" + s.attachment(node.Subscript))
+}
+
+func (s *Serializer) VisitFractionNode(node *ast.FractionNode) {
+	s.sb.WriteString(styled(node.Style, "frac("+s.argument(node.Numerator)+", "+s.argument(node.Denominator)+")"))
+}
+
+func (s *Serializer) VisitLimitedOperatorNode(node *ast.LimitedOperatorNode) {
+	name, ok := bigOperatorNames[node.Operator]
+	if !ok {
+		name = `"` + node.Operator + `"`
+	}
+	s.sb.WriteString(name)
+	if node.LowerLimit != nil {
+		s.sb.WriteString("_" + s.attachment(node.LowerLimit))
+	}
+	if node.UpperLimit != nil {
+		s.sb.WriteString("^" + s.attachment(node.UpperLimit))
+	}
+}
+
+func (s *Serializer) VisitSqrtNode(node *ast.SqrtNode) {
+	if node.Index != nil {
+		s.sb.WriteString("root(" + s.argument(node.Index) + ", " + s.argument(node.Radicand) + ")")
+		return
+	}
+	s.sb.WriteString("sqrt(" + s.argument(node.Radicand) + ")")
+}
+
+func (s *Serializer) VisitBinomNode(node *ast.BinomNode) {
+	s.sb.WriteString(styled(node.Style, "binom("+s.argument(node.Upper)+", "+s.argument(node.Lower)+")"))
+}
+
+func (s *Serializer) VisitSizedDelimiterNode(node *ast.SizedDelimiterNode) {
+	// The size of a delimiter is not written
+	node.Delimiter.Accept(s)
+}
+
+// styled wraps text in the Typst function that sets the given style.
+func styled(style ast.Style, text string) string {
+	switch style {
+	case ast.DisplayStyle, ast.ContinuedStyle:
+		return "display(" + text + ")"
+	case ast.TextStyle:
+		return "inline(" + text + ")"
+	case ast.ScriptStyle:
+		return "script(" + text + ")"
+	case ast.ScriptScriptStyle:
+		return "sscript(" + text + ")"
+	}
+	return text
+}
+
+// needsSpace reports whether a and b must be separated by a space, since
+// writing them together would change how they are tokenized, as in "x" "y"
+// becoming the unknown identifier "xy".
+func needsSpace(a, b string) bool {
+	ta, tb := withoutEOF(Tokenize(a)), withoutEOF(Tokenize(b))
+	joined := withoutEOF(Tokenize(a + b))
+	if len(joined) != len(ta)+len(tb) {
+		return true
+	}
+	for i, t := range append(ta, tb...) {
+		if joined[i].Type != t.Type || joined[i].Value != t.Value {
+			return true
+		}
+	}
+	r1, _ := utf8.DecodeLastRuneInString(a)
+	r2, _ := utf8.DecodeRuneInString(b)
+	return unicode.IsLetter(r1) && unicode.IsLetter(r2)
+}
+
+// withoutEOF removes the EOF tokens from ts.
+func withoutEOF(ts []Token) []Token {
+	out := ts[:0:0]
+	for _, t := range ts {
+		if t.Type != EOF {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// isPrimary reports whether n is written as a single primary expression
+// that needs no parentheses as an attachment.
+func isPrimary(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.ExpressionNode:
+		return len(node.Elements) == 1 && isPrimary(node.Elements[0])
+	case *ast.SymbolNode, *ast.NumberNode, *ast.SqrtNode, *ast.BinomNode,
+		*ast.FractionNode, *ast.DelimitedExpressionNode:
+		return true
+	}
+	return false
+}
+
+// isAttached reports whether n is a primary expression with attachments.
+func isAttached(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.ExpressionNode:
+		return len(node.Elements) == 1 && isAttached(node.Elements[0])
+	case *ast.SuperscriptNode:
+		return isPrimary(node.Base) || isAttached(node.Base)
+	case *ast.SubscriptNode:
+		return isPrimary(node.Base) || isAttached(node.Base)
+	}
+	return false
+}
+
+// hasComma reports whether n has a comma at the top level.
+func hasComma(n ast.Node) bool {
+	expr, ok := n.(*ast.ExpressionNode)
+	if !ok {
+		return false
+	}
+	for _, element := range expr.Elements {
+		if op, ok := element.(*ast.OperatorNode); ok && op.Value == "," {
+			return true
+		}
+	}
+	return false
+}
+
+func isBracket(kind ast.Delimiter) bool {
+	switch kind {
+	case ast.LeftParen, ast.RightParen, ast.LeftBracket, ast.RightBracket, ast.LeftBrace, ast.RightBrace:
+		return true
+	}
+	return false
+}
+
+func isFunction(n ast.Node) bool {
+	_, ok := n.(*ast.NonArgumentFunctionNode)
+	return ok
+}
+
+func isOperator(n ast.Node) bool {
+	_, ok := n.(*ast.OperatorNode)
+	return ok
+}
+
+func delimiterKind(n ast.Node) ast.Delimiter {
+	if d, ok := n.(*ast.DelimiterNode); ok {
+		return d.Kind
+	}
+	return ast.NoDelimiter
+}
@@ -0,0 +1,54 @@
+package typst_test
+
+import (
+	"testing"
+
+	"github.com/neox5/texmax/parser"
+	"github.com/neox5/texmax/render/unicode"
+	"github.com/neox5/texmax/tokenizer"
+	"github.com/neox5/texmax/typst"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		latex string
+		want  string
+	}{
+		{`\frac{a+b}{c}`, `frac(a+b, c)`},
+		{`\frac{x^2}{\sqrt{y}}`, `frac(x^2, sqrt(y))`},
+		{`\sum_{i=1}^{n} i^2`, `sum_(i=1)^n i^2`},
+		{`\prod_{k=1}^{n} k`, `product_(k=1)^n k`},
+		{`\lim_{x \to 0} f(x)`, `lim_(x->0) f(x)`},
+		{`\binom{n}{k}`, `binom(n, k)`},
+		{`\dfrac{1}{2} + \tbinom{n}{k}`, `display(frac(1, 2)) + inline(binom(n, k))`},
+		{`\sqrt[3]{x+1}`, `root(3, x+1)`},
+		{`x^{-1}`, `x^(-1)`},
+		{`e^{i\pi}`, `e^(i pi)`},
+		{`a = -b`, `a = -b`},
+		{`a \leq b \cdot c`, `a <= b dot c`},
+		{`\alpha \times \beta`, `alpha times beta`},
+		{`\left\lfloor x \right\rfloor + \left| y \right|`, `floor(x) + abs(y)`},
+		{`\left\langle x \right\rangle`, `lr(angle.l x angle.r)`},
+		{`(a-b)^2`, `(a - b)^2`},
+		{`\left(a-b\right)^2`, `lr((a - b))^2`},
+	}
+
+	for _, tt := range tests {
+		root, _ := parser.New(tokenizer.Tokenize(tt.latex)).Parse()
+		got := typst.Format(root)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.latex, got, tt.want)
+			continue
+		}
+
+		// Parsing the output back must give the same formula
+		back, errs := typst.Parse(got)
+		if len(errs) > 0 {
+			t.Errorf("%s: parsing %q failed: %v", tt.latex, got, errs)
+			continue
+		}
+		if g, w := unicode.Render(back), unicode.Render(root); g != w {
+			t.Errorf("%s: round trip gives %q, want %q", tt.latex, g, w)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package typst
+
+import (
+	"strings"
+
+	"github.com/neox5/texmax/parser"
+)
+
+// symbol is an entry of the Typst symbol tables.
+type symbol struct {
+	Type  TokenType
+	Value string
+}
+
+// names maps Typst identifiers, including modifiers like "arrow.r", to
+// tokens. Delimiters keep their names, see delimiterKinds.
+var names = map[string]symbol{
+	// Operators
+	"dot":        {OPERATOR, "·"},
+	"dot.op":     {OPERATOR, "·"},
+	"times":      {OPERATOR, "×"},
+	"div":        {OPERATOR, "÷"},
+	"plus.minus": {OPERATOR, "±"},
+	"minus.plus": {OPERATOR, "∓"},
+	"compose":    {OPERATOR, "∘"},
+	"union":      {OPERATOR, "∪"},
+	"sect":       {OPERATOR, "∩"},
+	"inter":      {OPERATOR, "∩"},
+	"without":    {OPERATOR, "∖"},
+	"and":        {OPERATOR, "∧"},
+	"or":         {OPERATOR, "∨"},
+	"slash":      {OPERATOR, "/"},
+
+	// Relations
+	"eq.not":      {OPERATOR, "≠"},
+	"lt.eq":       {OPERATOR, "≤"},
+	"gt.eq":       {OPERATOR, "≥"},
+	"approx":      {OPERATOR, "≈"},
+	"equiv":       {OPERATOR, "≡"},
+	"tilde.op":    {OPERATOR, "∼"},
+	"tilde.eq":    {OPERATOR, "≃"},
+	"tilde.equiv": {OPERATOR, "≅"},
+	"prop":        {OPERATOR, "∝"},
+	"lt.double":   {OPERATOR, "≪"},
+	"gt.double":   {OPERATOR, "≫"},
+	"in":          {OPERATOR, "∈"},
+	"in.not":      {OPERATOR, "∉"},
+	"subset":      {OPERATOR, "⊂"},
+	"supset":      {OPERATOR, "⊃"},
+
+	// Arrows
+	"arrow.r":          {OPERATOR, "→"},
+	"arrow.l":          {OPERATOR, "←"},
+	"arrow.r.double":   {OPERATOR, "⇒"},
+	"arrow.l.double":   {OPERATOR, "⇐"},
+	"arrow.l.r.double": {OPERATOR, "⇔"},
+	"arrow.r.bar":      {OPERATOR, "↦"},
+
+	// Miscellaneous symbols
+	"infinity": {SYMBOL, "∞"},
+	"partial":  {SYMBOL, "∂"},
+	"nabla":    {SYMBOL, "∇"},
+	"forall":   {SYMBOL, "∀"},
+	"exists":   {SYMBOL, "∃"},
+	"emptyset": {SYMBOL, "∅"},
+	"dots.h":   {SYMBOL, "…"},
+	"NN":       {SYMBOL, "ℕ"},
+	"ZZ":       {SYMBOL, "ℤ"},
+	"QQ":       {SYMBOL, "ℚ"},
+	"RR":       {SYMBOL, "ℝ"},
+	"CC":       {SYMBOL, "ℂ"},
+
+	// Big operators
+	"sum":      {BIGOP, "sum"},
+	"product":  {BIGOP, "prod"},
+	"integral": {BIGOP, "int"},
+	"lim":      {BIGOP, "lim"},
+
+	// Functions with arguments
+	"frac":  {CALL, "frac"},
+	"sqrt":  {CALL, "sqrt"},
+	"root":  {CALL, "root"},
+	"binom": {CALL, "binom"},
+	"lr":    {CALL, "lr"},
+	"abs":   {CALL, "abs"},
+	"norm":  {CALL, "norm"},
+	"floor": {CALL, "floor"},
+	"ceil":  {CALL, "ceil"},
+
+	// Styles
+	"display": {CALL, "display"},
+	"inline":  {CALL, "inline"},
+	"script":  {CALL, "script"},
+	"sscript": {CALL, "sscript"},
+
+	// Delimiters
+	"angle.l":          {DELIMITER, "angle.l"},
+	"angle.r":          {DELIMITER, "angle.r"},
+	"floor.l":          {DELIMITER, "floor.l"},
+	"floor.r":          {DELIMITER, "floor.r"},
+	"ceil.l":           {DELIMITER, "ceil.l"},
+	"ceil.r":           {DELIMITER, "ceil.r"},
+	"paren.l.flat":     {DELIMITER, "paren.l.flat"},
+	"paren.r.flat":     {DELIMITER, "paren.r.flat"},
+	"mustache.l":       {DELIMITER, "mustache.l"},
+	"mustache.r":       {DELIMITER, "mustache.r"},
+	"corner.l.t":       {DELIMITER, "corner.l.t"},
+	"corner.r.t":       {DELIMITER, "corner.r.t"},
+	"corner.l.b":       {DELIMITER, "corner.l.b"},
+	"corner.r.b":       {DELIMITER, "corner.r.b"},
+	"bar.v.double":     {DELIMITER, "bar.v.double"},
+	"backslash":        {DELIMITER, "backslash"},
+	"arrow.t":          {DELIMITER, "arrow.t"},
+	"arrow.b":          {DELIMITER, "arrow.b"},
+	"arrow.t.b":        {DELIMITER, "arrow.t.b"},
+	"arrow.t.double":   {DELIMITER, "arrow.t.double"},
+	"arrow.b.double":   {DELIMITER, "arrow.b.double"},
+	"arrow.t.b.double": {DELIMITER, "arrow.t.b.double"},
+}
+
+// shorthands maps Typst punctuation and shorthands to tokens. The
+// tokenizer takes the longest match, so "<=" wins over "<".
+var shorthands = map[string]symbol{
+	"+":   {OPERATOR, "+"},
+	"-":   {OPERATOR, "-"},
+	"*":   {OPERATOR, "*"},
+	"=":   {OPERATOR, "="},
+	"<":   {OPERATOR, "<"},
+	">":   {OPERATOR, ">"},
+	"<=":  {OPERATOR, "≤"},
+	">=":  {OPERATOR, "≥"},
+	"!=":  {OPERATOR, "≠"},
+	"->":  {OPERATOR, "→"},
+	"<-":  {OPERATOR, "←"},
+	"=>":  {OPERATOR, "⇒"},
+	"<=>": {OPERATOR, "⇔"},
+	"|->": {OPERATOR, "↦"},
+	"...": {SYMBOL, "…"},
+	"(":   {LEFT, "("},
+	")":   {RIGHT, ")"},
+	"[":   {LEFT, "["},
+	"]":   {RIGHT, "]"},
+	"{":   {LEFT, "{"},
+	"}":   {RIGHT, "}"},
+	"|":   {BAR, "|"},
+	",":   {COMMA, ","},
+	"^":   {SUPERSCRIPT, "^"},
+	"_":   {SUBSCRIPT, "_"},
+	"/":   {DIVIDE, "/"},
+}
+
+// functions are the function names written upright, matching the functions
+// known to the LaTeX parser.
+var functions = []string{
+	"sin", "cos", "tan", "cot", "sec", "csc", "log", "ln", "exp",
+	"arcsin", "arccos", "arctan", "sinh", "cosh", "tanh",
+	"max", "min", "det", "arg", "mod",
+}
+
+// greekNames are the lowercase Greek letter names; Typst also knows the
+// capitalized names.
+var greekNames = []string{
+	"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta",
+	"iota", "kappa", "lambda", "mu", "nu", "xi", "omicron", "pi", "rho",
+	"sigma", "tau", "upsilon", "phi", "chi", "psi", "omega",
+}
+
+// maxShorthandLength is the length of the longest shorthand.
+var maxShorthandLength int
+
+// symbolNames maps the values of symbols back to their names.
+var symbolNames = map[string]string{}
+
+func init() {
+	for _, name := range functions {
+		names[name] = symbol{FUNCTION, name}
+	}
+	for _, name := range greekNames {
+		for _, n := range []string{name, strings.ToUpper(name[:1]) + name[1:]} {
+			if value, ok := parser.GreekLetter(n); ok {
+				names[n] = symbol{SYMBOL, value}
+			}
+		}
+	}
+	for name, sym := range names {
+		if sym.Type == SYMBOL {
+			symbolNames[sym.Value] = name
+		}
+	}
+	for s := range shorthands {
+		maxShorthandLength = max(maxShorthandLength, len(s))
+	}
+}
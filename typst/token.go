@@ -0,0 +1,86 @@
+package typst
+
+// TokenType defines the type of a token from Typst math input.
+type TokenType int
+
+const (
+	// Special
+	ILLEGAL TokenType = iota
+	EOF
+
+	// Core token types
+	NUMBER      // e.g., 12, 3.14
+	SYMBOL      // e.g., x, alpha, infinity
+	IDENT       // unknown multi-letter identifier
+	TEXT        // e.g., "if"
+	OPERATOR    // e.g., +, times, <=, arrow.r
+	FUNCTION    // e.g., sin, log
+	BIGOP       // sum, product, integral, lim
+	CALL        // frac, sqrt, root, binom, lr, abs, norm, floor, ceil
+	DELIMITER   // e.g., angle.l, floor.r, \(
+	LEFT        // ( [ {
+	RIGHT       // ) ] }
+	BAR         // |
+	COMMA       // ,
+	SUPERSCRIPT // ^
+	SUBSCRIPT   // _
+	DIVIDE      // /
+)
+
+// Token represents a single lexical token. Value holds the canonical form
+// used in the AST, e.g. "α" for alpha and "≤" for <=.
+type Token struct {
+	Type  TokenType // Type of the token
+	Value string    // Canonical value
+	Pos   int       // Byte offset in the input
+	Len   int       // Length in the input in bytes
+}
+
+// String returns a readable representation of the token.
+func (t Token) String() string {
+	return t.Type.String() + "('" + t.Value + "')"
+}
+
+// String implements the fmt.Stringer interface for TokenType.
+func (tt TokenType) String() string {
+	switch tt {
+	case ILLEGAL:
+		return "ILLEGAL"
+	case EOF:
+		return "EOF"
+	case NUMBER:
+		return "NUMBER"
+	case SYMBOL:
+		return "SYMBOL"
+	case IDENT:
+		return "IDENT"
+	case TEXT:
+		return "TEXT"
+	case OPERATOR:
+		return "OPERATOR"
+	case FUNCTION:
+		return "FUNCTION"
+	case BIGOP:
+		return "BIGOP"
+	case CALL:
+		return "CALL"
+	case DELIMITER:
+		return "DELIMITER"
+	case LEFT:
+		return "LEFT"
+	case RIGHT:
+		return "RIGHT"
+	case BAR:
+		return "BAR"
+	case COMMA:
+		return "COMMA"
+	case SUPERSCRIPT:
+		return "SUPERSCRIPT"
+	case SUBSCRIPT:
+		return "SUBSCRIPT"
+	case DIVIDE:
+		return "DIVIDE"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,137 @@
+package typst
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenize splits Typst math input into tokens. Whitespace only separates
+// tokens and is dropped. Single letters are variables; longer identifiers,
+// with modifiers like "arrow.r", are looked up in the symbol table.
+func Tokenize(input string) []Token {
+	var tokens []Token
+	emit := func(typ TokenType, value string, pos, length int) {
+		tokens = append(tokens, Token{Type: typ, Value: value, Pos: pos, Len: length})
+	}
+
+	for pos := 0; pos < len(input); {
+		rest := input[pos:]
+		r, size := utf8.DecodeRuneInString(rest)
+
+		switch {
+		// SPACE
+		case unicode.IsSpace(r):
+			pos += size
+
+		// TEXT - "quoted"
+		case r == '"':
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				emit(ILLEGAL, rest, pos, len(rest))
+				pos = len(input)
+				continue
+			}
+			emit(TEXT, rest[1:end+1], pos, end+2)
+			pos += end + 2
+
+		// Escaped character, e.g. \( for a single parenthesis
+		case r == '\\':
+			if len(rest) == 1 {
+				emit(ILLEGAL, rest, pos, 1)
+				pos++
+				continue
+			}
+			c, n := utf8.DecodeRuneInString(rest[1:])
+			typ := OPERATOR
+			if strings.ContainsRune("()[]{}|", c) {
+				typ = DELIMITER
+			}
+			emit(typ, string(c), pos, 1+n)
+			pos += 1 + n
+
+		// NUMBER - digits with an optional decimal part
+		case r >= '0' && r <= '9':
+			end := 0
+			for end < len(rest) && isDigit(rest[end]) {
+				end++
+			}
+			if end+1 < len(rest) && rest[end] == '.' && isDigit(rest[end+1]) {
+				end++
+				for end < len(rest) && isDigit(rest[end]) {
+					end++
+				}
+			}
+			emit(NUMBER, rest[:end], pos, end)
+			pos += end
+
+		// Identifiers
+		case isASCIILetter(r):
+			name := identifier(rest)
+			if len(name) == 1 {
+				emit(SYMBOL, name, pos, 1)
+			} else if sym, ok := names[name]; ok {
+				emit(sym.Type, sym.Value, pos, len(name))
+			} else {
+				emit(IDENT, name, pos, len(name))
+			}
+			pos += len(name)
+
+		// Unicode letters are variables
+		case unicode.IsLetter(r):
+			emit(SYMBOL, string(r), pos, size)
+			pos += size
+
+		default:
+			if s, sym, ok := matchShorthand(rest); ok {
+				emit(sym.Type, sym.Value, pos, len(s))
+				pos += len(s)
+				continue
+			}
+			emit(OPERATOR, string(r), pos, size)
+			pos += size
+		}
+	}
+
+	// Add EOF token at end
+	tokens = append(tokens, Token{Type: EOF, Value: "", Pos: len(input)})
+	return tokens
+}
+
+// identifier returns the identifier at the start of s. Modifiers separated
+// by dots are included as long as the result is a known name.
+func identifier(s string) string {
+	end := 0
+	for end < len(s) && isASCIILetter(rune(s[end])) {
+		end++
+	}
+	name := s[:end]
+	for end+1 < len(s) && s[end] == '.' && isASCIILetter(rune(s[end+1])) {
+		end++
+		for end < len(s) && isASCIILetter(rune(s[end])) {
+			end++
+		}
+		if _, ok := names[s[:end]]; ok {
+			name = s[:end]
+		}
+	}
+	return name
+}
+
+// matchShorthand returns the longest shorthand that s starts with.
+func matchShorthand(s string) (string, symbol, bool) {
+	for n := min(len(s), maxShorthandLength); n > 0; n-- {
+		if sym, ok := shorthands[s[:n]]; ok {
+			return s[:n], sym, true
+		}
+	}
+	return "", symbol{}, false
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}